@@ -0,0 +1,86 @@
+// audit.go: Structured audit records of configuration loads
+//
+// Compliance regimes such as SOC 2 require a record of every configuration
+// load. With WithAuditSink, each Load, LoadDetailed and LoadFiles call, and
+// each configuration a watch delivers, produces exactly one AuditRecord per
+// file: the redacted repository, the file, the commit it was read from, its
+// size, the duration and the outcome. Unlike diagnostic logging, records are
+// emitted for every event, including cache hits and failures.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"time"
+)
+
+// Audited operations
+const (
+	AuditOperationLoad  = "load"  // Load, LoadDetailed or LoadFiles
+	AuditOperationWatch = "watch" // A configuration delivered by Watch
+)
+
+// Audit outcomes
+const (
+	AuditSuccess = "success" // The configuration was loaded
+	AuditFailure = "failure" // The load failed; Error describes why
+)
+
+// AuditRecord is the audit trail entry of a single configuration load
+type AuditRecord struct {
+	Time       time.Time     // When the load completed
+	Operation  string        // One of the AuditOperation* values
+	RepoURL    string        // Repository URL with credentials redacted
+	FilePath   string        // Configuration file within the repository
+	Reference  string        // Reference that was requested or served
+	CommitHash string        // Commit the configuration was read from; empty on failure
+	Bytes      int           // Size of the raw file content in bytes
+	Duration   time.Duration // Time the load took
+	CacheHit   bool          // The configuration was served from the cache
+	Stale      bool          // A last-known-good configuration was served (see WithStaleFallback)
+	Credential string        // Fingerprint of the request's auth override (see WithAuthOverride); empty without one
+	Outcome    string        // AuditSuccess or AuditFailure
+	Error      string        // Redacted error message on failure
+}
+
+// audit emits the audit record of a load if an audit sink is configured.
+// gitURL is nil when configURL could not be parsed.
+func (g *GitProvider) audit(operation, configURL string, gitURL *GitURL, result *LoadResult, err error, duration time.Duration) {
+	if g.options.auditSink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:      time.Now(),
+		Operation: operation,
+		RepoURL:   redactLogText(configURL),
+		Duration:  duration,
+		Outcome:   AuditSuccess,
+	}
+	if gitURL != nil {
+		record.RepoURL = redactLogText(gitURL.RepoURL)
+		record.FilePath = gitURL.FilePath
+		record.Reference = gitURL.Reference
+		if gitURL.authOverride {
+			record.Credential = gitURL.cacheScope
+		}
+	}
+	if result != nil {
+		if result.Reference != "" {
+			record.Reference = result.Reference
+		}
+		record.CommitHash = result.CommitHash
+		record.Bytes = result.Size
+		record.CacheHit = result.cacheHit
+		record.Stale = result.Stale
+	}
+	if err != nil {
+		record.Outcome = AuditFailure
+		record.Error = redactLogText(err.Error())
+	}
+
+	g.options.auditSink(record)
+}
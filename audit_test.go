@@ -0,0 +1,151 @@
+// audit_test.go
+//
+// Configuration load audit tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// auditRecorder collects audit records for a test
+type auditRecorder struct {
+	mutex   sync.Mutex
+	records []AuditRecord
+}
+
+func (r *auditRecorder) record(record AuditRecord) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.records = append(r.records, record)
+}
+
+// take returns the records collected so far and resets the recorder
+func (r *auditRecorder) take() []AuditRecord {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	records := r.records
+	r.records = nil
+	return records
+}
+
+// TestWithAuditSink tests that every load produces exactly one audit record
+func TestWithAuditSink(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"app.json":    `{"service": "api"}`,
+		"worker.yaml": "queue: jobs\n",
+	})
+	head := repo.commit("tune worker", map[string]string{"worker.yaml": "queue: jobs\nworkers: 4\n"})
+
+	recorder := &auditRecorder{}
+	provider, err := NewProvider(WithAllowLocalRepos(true), WithAuditSink(recorder.record))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configURL := "file://" + repo.dir + "#app.json?ref=main"
+
+	t.Run("Load and cache hit", func(t *testing.T) {
+		for i, cacheHit := range []bool{false, true} {
+			if _, err := provider.Load(ctx, configURL); err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			records := recorder.take()
+			if len(records) != 1 {
+				t.Fatalf("Load %d: expected 1 audit record, got %d", i, len(records))
+			}
+			record := records[0]
+			if record.Operation != AuditOperationLoad || record.Outcome != AuditSuccess || record.Error != "" {
+				t.Errorf("Load %d: unexpected outcome %+v", i, record)
+			}
+			if record.RepoURL != "file://"+repo.dir || record.FilePath != "app.json" || record.Reference != "main" {
+				t.Errorf("Load %d: unexpected target %+v", i, record)
+			}
+			if record.CommitHash != head || record.Bytes != len(`{"service": "api"}`) || record.CacheHit != cacheHit {
+				t.Errorf("Load %d: unexpected result %+v", i, record)
+			}
+			if record.Time.IsZero() || record.Duration <= 0 {
+				t.Errorf("Load %d: expected time and duration, got %+v", i, record)
+			}
+		}
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		for _, failing := range []string{
+			"file://" + repo.dir + "#missing.json",
+			"https://ghp_secret123@github.com/org/repo.git#../../etc/app.json?auth=token:ghp_secret123",
+		} {
+			if _, err := provider.LoadDetailed(ctx, failing); err == nil {
+				t.Fatalf("Expected load of %s to fail", failing)
+			}
+
+			records := recorder.take()
+			if len(records) != 1 || records[0].Outcome != AuditFailure || records[0].Error == "" {
+				t.Fatalf("Expected 1 failure record for %s, got %+v", failing, records)
+			}
+			if strings.Contains(records[0].RepoURL+records[0].Error, "ghp_secret123") {
+				t.Errorf("SECURITY: Credentials in audit record: %+v", records[0])
+			}
+		}
+	})
+
+	t.Run("LoadFiles", func(t *testing.T) {
+		if _, err := provider.LoadFiles(ctx, "file://"+repo.dir, "main", []string{"app.json", "worker.yaml"}); err != nil {
+			t.Fatalf("LoadFiles failed: %v", err)
+		}
+
+		records := recorder.take()
+		if len(records) != 2 {
+			t.Fatalf("Expected one audit record per file, got %d", len(records))
+		}
+		for i, filePath := range []string{"app.json", "worker.yaml"} {
+			if records[i].FilePath != filePath || records[i].CommitHash != head || records[i].Outcome != AuditSuccess {
+				t.Errorf("Unexpected record for %s: %+v", filePath, records[i])
+			}
+		}
+	})
+
+	t.Run("Watch delivery", func(t *testing.T) {
+		watchCtx, stop := context.WithCancel(ctx)
+		defer stop()
+
+		configs, err := provider.Watch(watchCtx, "file://"+repo.dir+"#worker.yaml?ref=main")
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		select {
+		case <-configs:
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the initial configuration")
+		}
+
+		// The record is emitted once the delivery has completed
+		deadline := time.Now().Add(5 * time.Second)
+		var records []AuditRecord
+		for len(records) == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			records = recorder.take()
+		}
+		if len(records) != 1 || records[0].Operation != AuditOperationWatch || records[0].FilePath != "worker.yaml" {
+			t.Errorf("Expected 1 watch record, got %+v", records)
+		}
+	})
+
+	t.Run("Nil sink", func(t *testing.T) {
+		if _, err := NewProvider(WithAuditSink(nil)); err == nil {
+			t.Error("Expected error for nil audit sink")
+		}
+	})
+}
@@ -0,0 +1,511 @@
+// auth_test.go
+//
+// HTTP authentication tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// newHeaderCaptureServer starts a mock Git server that records a named header of every request
+func newHeaderCaptureServer(t *testing.T, header string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var values []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		values = append(values, r.Header.Get(header))
+		mu.Unlock()
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), values...)
+	}
+}
+
+// newNoRetryProvider returns a provider that fails fast, for tests against mock servers
+func newNoRetryProvider() *GitProvider {
+	provider := GetProvider().(*GitProvider)
+	provider.retryConfig = &retryConfig{
+		maxRetries:    0,
+		baseDelay:     time.Millisecond,
+		maxDelay:      time.Millisecond,
+		backoffFactor: 1,
+	}
+	return provider
+}
+
+// TestGitProvider_BitbucketAuthentication verifies Bitbucket Cloud token semantics
+func TestGitProvider_BitbucketAuthentication(t *testing.T) {
+	provider := GetProvider().(*GitProvider)
+
+	testCases := []struct {
+		name         string
+		url          string
+		expectedUser string
+	}{
+		{
+			name:         "Bitbucket Cloud access token",
+			url:          "https://bitbucket.org/team/repo.git#config.json?auth=token:bb_secret",
+			expectedUser: bitbucketTokenUsername,
+		},
+		{
+			name:         "Bitbucket Cloud API subdomain",
+			url:          "https://api.bitbucket.org/team/repo.git#config.json?auth=token:bb_secret",
+			expectedUser: bitbucketTokenUsername,
+		},
+		{
+			name:         "GitHub token keeps token username",
+			url:          "https://github.com/user/repo.git#config.json?auth=token:bb_secret",
+			expectedUser: "token",
+		},
+		{
+			name:         "Look-alike host is not Bitbucket",
+			url:          "https://evilbitbucket.org/team/repo.git#config.json?auth=token:bb_secret",
+			expectedUser: "token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gitURL, err := provider.parseGitURL(tc.url)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+
+			auth, err := provider.getAuthentication(gitURL)
+			if err != nil {
+				t.Fatalf("Unexpected authentication error: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+			auth.(interface{ SetAuth(*http.Request) }).SetAuth(req)
+
+			username, password, ok := req.BasicAuth()
+			if !ok {
+				t.Fatalf("Expected basic auth header, got %q", req.Header.Get("Authorization"))
+			}
+			if username != tc.expectedUser {
+				t.Errorf("Expected username %q, got %q", tc.expectedUser, username)
+			}
+			if password != "bb_secret" {
+				t.Errorf("Expected password to be the token, got %q", password)
+			}
+		})
+	}
+
+	t.Run("App password via basic auth", func(t *testing.T) {
+		gitURL, err := provider.parseGitURL("https://bitbucket.org/team/repo.git#config.json?auth=basic:bbuser:app_password")
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+
+		auth, err := provider.getAuthentication(gitURL)
+		if err != nil {
+			t.Fatalf("Unexpected authentication error: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		auth.(interface{ SetAuth(*http.Request) }).SetAuth(req)
+
+		username, password, _ := req.BasicAuth()
+		if username != "bbuser" || password != "app_password" {
+			t.Errorf("Expected bbuser/app_password, got %q/%q", username, password)
+		}
+	})
+}
+
+// TestGitProvider_BearerAuthentication verifies the bearer auth type on the wire
+func TestGitProvider_BearerAuthentication(t *testing.T) {
+	provider := newNoRetryProvider()
+
+	gitURL, err := provider.parseGitURL("https://bitbucket.org/team/repo.git#config.json?auth=bearer:oauth_access_token")
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	if gitURL.AuthType != "bearer" || gitURL.AuthData["token"] != "oauth_access_token" {
+		t.Fatalf("Unexpected auth parsing: type=%q data=%v", gitURL.AuthType, gitURL.AuthData)
+	}
+
+	// Point the parsed URL at a local mock server (bypassing SSRF validation on purpose)
+	server, recorded := newHeaderCaptureServer(t, "Authorization")
+	gitURL.RepoURL = server.URL + "/team/repo.git"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := provider.getRemoteCommitHash(ctx, gitURL); err == nil {
+		t.Fatal("Expected mock server to reject the request")
+	}
+
+	headers := recorded()
+	if len(headers) == 0 {
+		t.Fatal("Mock server received no requests")
+	}
+	if headers[0] != "Bearer oauth_access_token" {
+		t.Errorf("Expected bearer Authorization header, got %q", headers[0])
+	}
+}
+
+// TestGitProvider_GiteaAuthentication verifies the Gitea/Forgejo token scheme on the wire
+func TestGitProvider_GiteaAuthentication(t *testing.T) {
+	provider := newNoRetryProvider()
+
+	gitURL, err := provider.parseGitURL("https://gitea.example.com/org/repo.git#config.json?auth=gitea:gt_secret")
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	if gitURL.AuthType != "gitea" || gitURL.AuthData["token"] != "gt_secret" {
+		t.Fatalf("Unexpected auth parsing: type=%q data=%v", gitURL.AuthType, gitURL.AuthData)
+	}
+
+	auth, err := provider.getAuthentication(gitURL)
+	if err != nil {
+		t.Fatalf("Unexpected authentication error: %v", err)
+	}
+	if strings.Contains(auth.String(), "gt_secret") {
+		t.Errorf("SECURITY: Token exposed in auth description: %s", auth.String())
+	}
+
+	// Point the parsed URL at a local mock server (bypassing SSRF validation on purpose)
+	server, recorded := newHeaderCaptureServer(t, "Authorization")
+	gitURL.RepoURL = server.URL + "/org/repo.git"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := provider.getRemoteCommitHash(ctx, gitURL); err == nil {
+		t.Fatal("Expected mock server to reject the request")
+	}
+
+	headers := recorded()
+	if len(headers) == 0 {
+		t.Fatal("Mock server received no requests")
+	}
+	if headers[0] != "token gt_secret" {
+		t.Errorf("Expected Gitea token Authorization header, got %q", headers[0])
+	}
+
+	if _, err := (AuthMethodSpec{Type: "gitea"}).authData(); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected a gitea override without a token to be rejected, got %v", err)
+	}
+}
+
+// TestWithAnonymousFallback verifies that rejected credentials fall back to anonymous access
+func TestWithAnonymousFallback(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "public"}`})
+	backend := repo.httpBackend()
+
+	// The public server serves anonymous clones but rejects the expired token; the
+	// private server requires credentials it never accepts
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(public.Close)
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(private.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	load := func(t *testing.T, serverURL string, opts ...Option) (*LoadResult, error) {
+		t.Helper()
+		provider, err := NewProvider(opts...)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		provider.retryConfig = newNoRetryProvider().retryConfig
+		return provider.loadConfigFromRepo(ctx, &GitURL{
+			RepoURL:   serverURL + "/.git",
+			FilePath:  "config.json",
+			Reference: "main",
+			AuthType:  "token",
+			AuthData:  map[string]string{"token": "ghp_expired"},
+		})
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		if _, err := load(t, public.URL); err == nil {
+			t.Error("Expected the rejected token to fail without the fallback")
+		}
+	})
+
+	t.Run("Public repository", func(t *testing.T) {
+		result, err := load(t, public.URL, WithAnonymousFallback(true))
+		if err != nil {
+			t.Fatalf("Expected the anonymous fallback to load the configuration, got %v", err)
+		}
+		if result.Config["service"] != "public" {
+			t.Errorf("Unexpected config: %v", result.Config)
+		}
+	})
+
+	t.Run("Private repository", func(t *testing.T) {
+		_, plain := load(t, private.URL)
+		_, fallback := load(t, private.URL, WithAnonymousFallback(true))
+		if fallback == nil || fallback.Error() != plain.Error() {
+			t.Errorf("Expected the original authentication error, got %v (without fallback: %v)", fallback, plain)
+		}
+	})
+}
+
+// TestGitProvider_BitbucketTokenOnTheWire verifies the header shape sent for Bitbucket tokens
+func TestGitProvider_BitbucketTokenOnTheWire(t *testing.T) {
+	provider := newNoRetryProvider()
+
+	gitURL, err := provider.parseGitURL("https://bitbucket.org/team/repo.git#config.json?auth=token:bb_secret")
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+
+	// Resolve authentication against the real host, then send it to the mock server
+	if _, err := provider.getAuthentication(gitURL); err != nil {
+		t.Fatalf("Unexpected authentication error: %v", err)
+	}
+	server, recorded := newHeaderCaptureServer(t, "Authorization")
+	mockURL := *gitURL
+	mockURL.RepoURL = server.URL + "/team/repo.git"
+	provider.authCache["token:"+mockURL.RepoURL] = provider.authCache["token:"+gitURL.RepoURL]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _ = provider.getRemoteCommitHash(ctx, &mockURL)
+
+	headers := recorded()
+	if len(headers) == 0 {
+		t.Fatal("Mock server received no requests")
+	}
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(bitbucketTokenUsername+":bb_secret"))
+	if headers[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, headers[0])
+	}
+}
+
+// TestGitProvider_CustomHeaderAuthentication verifies header injection into the HTTP transport
+func TestGitProvider_CustomHeaderAuthentication(t *testing.T) {
+	const secret = "eyJhbGciOiJIUzI1NiJ9.gateway-jwt"
+
+	t.Run("Provider-level header without URL auth", func(t *testing.T) {
+		provider, err := NewProvider(WithHTTPHeader("Authorization", "Bearer "+secret))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		provider.retryConfig.maxRetries = 0
+
+		server, recorded := newHeaderCaptureServer(t, "Authorization")
+		gitURL := &GitURL{RepoURL: server.URL + "/org/repo.git", Reference: "main", AuthData: map[string]string{}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err = provider.getRemoteCommitHash(ctx, gitURL)
+		if err == nil {
+			t.Fatal("Expected mock server to reject the request")
+		}
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("Header value leaked into error: %v", err)
+		}
+
+		headers := recorded()
+		if len(headers) == 0 || headers[0] != "Bearer "+secret {
+			t.Errorf("Expected custom Authorization header, got %v", headers)
+		}
+
+		if strings.Contains(fmt.Sprintf("%v", provider.GetMetrics()), secret) {
+			t.Error("Header value leaked into metrics")
+		}
+	})
+
+	t.Run("Provider-level header combined with basic auth", func(t *testing.T) {
+		provider, err := NewProvider(WithHTTPHeader("X-Gateway-Token", secret))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		gitURL, err := provider.parseGitURL("https://git.company.com/team/configs.git#app.json?auth=basic:deploy:pw")
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+
+		auth, err := provider.getAuthentication(gitURL)
+		if err != nil {
+			t.Fatalf("Unexpected authentication error: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "https://git.company.com/", nil)
+		auth.(interface{ SetAuth(*http.Request) }).SetAuth(req)
+
+		if req.Header.Get("X-Gateway-Token") != secret {
+			t.Errorf("Expected gateway header, got %q", req.Header.Get("X-Gateway-Token"))
+		}
+		if username, password, ok := req.BasicAuth(); !ok || username != "deploy" || password != "pw" {
+			t.Errorf("Expected basic auth to be preserved, got %q/%q (ok=%v)", username, password, ok)
+		}
+		if strings.Contains(auth.String(), secret) || strings.Contains(auth.String(), "pw") {
+			t.Errorf("Authentication description leaks secrets: %s", auth.String())
+		}
+	})
+
+	t.Run("URL header auth type", func(t *testing.T) {
+		provider := GetProvider().(*GitProvider)
+
+		gitURL, err := provider.parseGitURL("https://git.company.com/team/configs.git#app.json?auth=header:X-Gateway-Token:" + secret)
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+
+		auth, err := provider.getAuthentication(gitURL)
+		if err != nil {
+			t.Fatalf("Unexpected authentication error: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "https://git.company.com/", nil)
+		auth.(interface{ SetAuth(*http.Request) }).SetAuth(req)
+
+		if req.Header.Get("X-Gateway-Token") != secret {
+			t.Errorf("Expected gateway header, got %q", req.Header.Get("X-Gateway-Token"))
+		}
+	})
+
+	t.Run("SSH repositories are not wrapped", func(t *testing.T) {
+		provider, err := NewProvider(WithHTTPHeader("X-Gateway-Token", secret))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		auth, err := provider.getAuthentication(&GitURL{RepoURL: "ssh://git@github.com/user/repo.git"})
+		if err != nil || auth != nil {
+			t.Errorf("Expected no authentication for SSH URL, got %v (err=%v)", auth, err)
+		}
+	})
+
+	t.Run("Header injection is rejected", func(t *testing.T) {
+		invalid := []struct{ name, value string }{
+			{"", "value"},
+			{"X-Bad Header", "value"},
+			{"X-Header", "value\r\nX-Injected: 1"},
+			{"X-Header", "value\x00"},
+		}
+
+		for _, tc := range invalid {
+			if _, err := NewProvider(WithHTTPHeader(tc.name, tc.value)); err == nil {
+				t.Errorf("Expected header %q=%q to be rejected", tc.name, tc.value)
+			}
+		}
+	})
+}
+
+// TestWithAuthOverride verifies that request contexts carry their own credentials
+func TestWithAuthOverride(t *testing.T) {
+	const configURL = "https://github.com/org/configs.git#config.json?auth=token:url_token"
+
+	provider := newNoRetryProvider()
+	server, recorded := newHeaderCaptureServer(t, "Authorization")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// sentAuthorization resolves the URL for ctx and returns the header sent to the mock server
+	sentAuthorization := func(t *testing.T, ctx context.Context) (string, *GitURL) {
+		t.Helper()
+
+		gitURL, err := provider.parseRequestURL(ctx, configURL, true)
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+		gitURL.RepoURL = server.URL + "/org/configs.git"
+
+		before := len(recorded())
+		_, _ = provider.getRemoteCommitHash(ctx, gitURL)
+		headers := recorded()
+		if len(headers) == before {
+			t.Fatal("Mock server received no requests")
+		}
+		return headers[before], gitURL
+	}
+	basic := func(password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte("token:"+password))
+	}
+
+	tenantA := WithAuthOverride(ctx, AuthMethodSpec{Type: "token", Token: "tenant_a_token"})
+	tenantB := WithAuthOverride(ctx, AuthMethodSpec{Type: "token", Token: "tenant_b_token"})
+
+	headerA, urlA := sentAuthorization(t, tenantA)
+	headerB, urlB := sentAuthorization(t, tenantB)
+	headerURL, plainURL := sentAuthorization(t, ctx)
+
+	if headerA != basic("tenant_a_token") || headerB != basic("tenant_b_token") {
+		t.Errorf("Expected per-context credentials, got %q and %q", headerA, headerB)
+	}
+	if headerURL != basic("url_token") {
+		t.Errorf("Expected URL credentials without an override, got %q", headerURL)
+	}
+
+	// Overrides never enter the shared authentication cache
+	provider.authCacheMutex.RLock()
+	for _, auth := range provider.authCache {
+		if strings.Contains(fmt.Sprintf("%#v", auth), "tenant_") {
+			t.Errorf("Override credentials leaked into the shared auth cache: %v", auth)
+		}
+	}
+	provider.authCacheMutex.RUnlock()
+
+	// Configurations are cached separately per credential
+	keys := map[string]bool{
+		provider.configCache.getCacheKey(urlA, "abc"):     true,
+		provider.configCache.getCacheKey(urlB, "abc"):     true,
+		provider.configCache.getCacheKey(plainURL, "abc"): true,
+	}
+	if len(keys) != 3 {
+		t.Errorf("Expected distinct cache keys per credential, got %v", keys)
+	}
+
+	t.Run("Specs print without credentials", func(t *testing.T) {
+		if printed := fmt.Sprint(tenantA); strings.Contains(printed, "tenant_a_token") {
+			t.Errorf("Context printed the override token: %s", printed)
+		}
+	})
+
+	t.Run("Invalid specs are rejected", func(t *testing.T) {
+		invalid := []AuthMethodSpec{
+			{Type: "token"},
+			{Type: "basic", Username: "user"},
+			{Type: "header", HeaderName: "Bad Header", HeaderValue: "x"},
+			{Type: "ssh"},
+			{Type: "kerberos", Token: "x"},
+		}
+		for _, spec := range invalid {
+			if _, err := provider.Load(WithAuthOverride(ctx, spec), configURL); err == nil {
+				t.Errorf("Expected %+v to be rejected", spec)
+			}
+		}
+	})
+}
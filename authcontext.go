@@ -0,0 +1,154 @@
+// authcontext.go: Request-scoped authentication overrides
+//
+// A multi-tenant service can serve configurations for tenants with different
+// credentials from a single provider by attaching the credentials to the request
+// context with WithAuthOverride, instead of embedding them in configuration URLs.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/agilira/go-errors"
+)
+
+// AuthMethodSpec describes credentials for a single request.
+// The fields used depend on Type and mirror the auth URL parameter:
+//
+//   - "token", "bearer" and "gitea": Token
+//   - "basic": Username and Password
+//   - "header": HeaderName and HeaderValue
+//   - "ssh" (or "key"): KeyPath and optionally Passphrase
+type AuthMethodSpec struct {
+	Type        string // Authentication type
+	Token       string // Access or bearer token
+	Username    string // HTTP Basic username
+	Password    string // HTTP Basic password
+	HeaderName  string // Custom HTTP header name
+	HeaderValue string // Custom HTTP header value
+	KeyPath     string // SSH private key path
+	Passphrase  string // SSH private key passphrase
+}
+
+// String describes the spec without its credentials, so it is safe to log
+func (s AuthMethodSpec) String() string {
+	return fmt.Sprintf("AuthMethodSpec{Type: %s, credentials: %s}", s.Type, maskedAuthValue)
+}
+
+// authOverrideKey is the context key of the request-scoped AuthMethodSpec
+type authOverrideKey struct{}
+
+// WithAuthOverride returns a context whose Load, LoadDetailed, LoadFiles, Watch,
+// ListConfigs, ListRefs, Diff and HealthCheck calls authenticate with spec instead
+// of any auth in the URL.
+//
+// SECURITY: Overrides never enter the shared authentication cache and each
+// credential gets its own configuration cache partition, so a configuration
+// loaded with one tenant's credentials is never served to a request carrying
+// different credentials. The spec lives in the context for as long as the
+// context does; do not store such contexts beyond the request. Specs print
+// without their credentials. Overrides are ignored for local and git:// repositories.
+//
+// Example:
+//
+//	ctx = git.WithAuthOverride(ctx, git.AuthMethodSpec{Type: "token", Token: tenant.Token})
+//	config, err := provider.Load(ctx, "https://github.com/org/configs.git#app.json")
+func WithAuthOverride(ctx context.Context, spec AuthMethodSpec) context.Context {
+	return context.WithValue(ctx, authOverrideKey{}, spec)
+}
+
+// applyAuthOverride replaces the URL authentication with the context override, if any
+func applyAuthOverride(ctx context.Context, gitURL *GitURL) error {
+	spec, ok := ctx.Value(authOverrideKey{}).(AuthMethodSpec)
+	if !ok {
+		return nil
+	}
+
+	// Local and git:// repositories are read without credentials
+	if strings.HasPrefix(gitURL.RepoURL, "file://") || isGitProtocolURL(gitURL.RepoURL) {
+		return nil
+	}
+
+	authData, err := spec.authData()
+	if err != nil {
+		return err
+	}
+
+	gitURL.AuthType = strings.ToLower(spec.Type)
+	gitURL.AuthData = authData
+	gitURL.authOverride = true
+	gitURL.cacheScope = spec.fingerprint()
+	return nil
+}
+
+// authData validates the spec and converts it to GitURL authentication data
+func (s AuthMethodSpec) authData() (map[string]string, error) {
+	missing := func(fields string) error {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("auth override of type %s requires %s", s.Type, fields))
+	}
+
+	switch strings.ToLower(s.Type) {
+	case "token", "bearer", "gitea":
+		if s.Token == "" {
+			return nil, missing("Token")
+		}
+		return map[string]string{"token": s.Token}, nil
+	case "basic":
+		if s.Username == "" || s.Password == "" {
+			return nil, missing("Username and Password")
+		}
+		return map[string]string{"username": s.Username, "password": s.Password}, nil
+	case "header":
+		if err := validateHTTPHeader(s.HeaderName, s.HeaderValue); err != nil {
+			return nil, err
+		}
+		return map[string]string{"header_name": s.HeaderName, "header_value": s.HeaderValue}, nil
+	case "ssh", "key":
+		if s.KeyPath == "" {
+			return nil, missing("KeyPath")
+		}
+
+		// SECURITY: Validate SSH key file permissions as for ssh_key URL parameters
+		if err := validateSecretFile(s.KeyPath, "SSH key file"); err != nil {
+			return nil, err
+		}
+		return map[string]string{"keypath": s.KeyPath, "passphrase": s.Passphrase}, nil
+	default:
+		return nil, errors.New("ARGUS_AUTH_ERROR",
+			fmt.Sprintf("unsupported authentication type: %s", s.Type))
+	}
+}
+
+// fingerprint identifies the credentials of the spec without revealing them
+func (s AuthMethodSpec) fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		strings.ToLower(s.Type), s.Token, s.Username, s.Password,
+		s.HeaderName, s.HeaderValue, s.KeyPath, s.Passphrase,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// parseRequestURL parses a configuration URL and applies the request's authentication
+// override and cache scope
+func (g *GitProvider) parseRequestURL(ctx context.Context, configURL string, requireFile bool) (*GitURL, error) {
+	gitURL, err := g.parseGitURLWith(configURL, requireFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyAuthOverride(ctx, gitURL); err != nil {
+		return nil, err
+	}
+	applyCacheScope(ctx, gitURL)
+
+	return gitURL, nil
+}
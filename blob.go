@@ -0,0 +1,257 @@
+// blob.go: Configuration read from a blob by object hash
+//
+// Content-addressed pipelines may publish rendered configuration as a Git blob
+// referenced by its hash rather than as a file at a path. With "blob=<sha>" the
+// blob is read straight from the repository object store, bypassing path and
+// reference resolution; since there is no file extension, the format must be
+// given explicitly:
+//
+//	https://github.com/org/configs.git?blob=3b18e512dba79e4c8300dd08aeb37f8e728b8dad&format=json
+//
+// The blob must be reachable from a reference of the repository (a branch, a tag,
+// or e.g. a tag or note pointing at the blob), since Git servers only serve
+// reachable objects. Blob content is immutable, so it is cached without expiry and
+// watches load it once.
+//
+// Files loaded by path are cached by their blob too: when a new commit leaves the
+// file unchanged, the configuration parsed at an earlier commit is reused rather
+// than read and parsed again.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// blobRefSpec fetches every reference, since the blob may be reachable from any of them
+const blobRefSpec = "+refs/*:refs/*"
+
+// blobConflictingParams are the URL parameters that select content by path or reference
+var blobConflictingParams = []string{"file", "ref", "branch", "tag", "commit", "tag_constraint", "fallback_ref", "release"}
+
+// isImmutable reports whether the URL names content that can never change:
+// a blob, or a file at a full commit hash
+func (u *GitURL) isImmutable() bool {
+	return u.Blob != "" || isCommitHash(revisionBase(u.Reference))
+}
+
+// validateBlobURL checks a URL with a blob parameter: the hash must be a full object
+// hash, a format must be given, and no path or reference may be given
+func validateBlobURL(gitURL *GitURL, queries ...url.Values) error {
+	gitURL.Blob = strings.ToLower(gitURL.Blob)
+	if !isCommitHash(gitURL.Blob) {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("invalid blob hash: %q (expected 40 hexadecimal digits)", gitURL.Blob))
+	}
+
+	if gitURL.FilePath != "" {
+		return errors.New("ARGUS_INVALID_CONFIG", "blob cannot be combined with a configuration file path")
+	}
+	for _, query := range queries {
+		for _, param := range blobConflictingParams {
+			if query.Has(param) {
+				return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("blob cannot be combined with %s", param))
+			}
+		}
+	}
+
+	if gitURL.Format == "" {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("blob requires an explicit format (supported: %s)", strings.Join(registeredFormats(), ", ")))
+	}
+	gitURL.Reference = ""
+
+	return nil
+}
+
+// loadBlobConfig reads and parses the blob the URL names, serving it from the
+// configuration cache when it was read before
+func (g *GitProvider) loadBlobConfig(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	if cached, found := g.configCache.getResult(gitURL, ""); found {
+		g.metrics.incrementCacheHits()
+		g.logEvent(ctx, slog.LevelDebug, "configuration cache hit", logURLAttrs(gitURL)...)
+		cached.cacheHit = true
+		return cached, nil
+	}
+	g.metrics.incrementCacheMisses()
+
+	tempDir, err := g.createTempDirectory()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to create temporary directory")
+	}
+	defer g.removeTempDirectory(tempDir)
+
+	repo, err := g.fetchObjectStore(ctx, gitURL, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepository(repo)
+
+	content, err := readBlob(repo, gitURL.Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := g.parseConfigFileAs(gitURL.displayPath(), gitURL.Format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoadResult{
+		Config: config,
+		Format: gitURL.Format,
+		Size:   len(content),
+	}
+	g.configCache.putResult(gitURL, "", result)
+	g.metrics.incrementConfigsCached()
+
+	return result, nil
+}
+
+// fetchObjectStore fetches every reference of the repository with full history into a
+// bare repository in tempDir, with retry logic and mirror failover
+func (g *GitProvider) fetchObjectStore(ctx context.Context, gitURL *GitURL, tempDir string) (*git.Repository, error) {
+	start := time.Now()
+	defer func() {
+		g.metrics.addCloneTime(time.Since(start))
+	}()
+
+	repo, err := git.PlainInit(tempDir, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to initialize repository")
+	}
+	g.logEvent(ctx, slog.LevelDebug, "fetching repository objects", logURLAttrs(gitURL)...)
+
+	err = g.tryMirrors(gitURL, func(gitURL *GitURL) error {
+		return g.retryOperation(ctx, func() error {
+			remote := git.NewRemote(repo.Storer, &config.RemoteConfig{
+				Name: git.DefaultRemoteName,
+				URLs: []string{transportURL(gitURL.RepoURL)},
+			})
+
+			fetchOptions := &git.FetchOptions{
+				RefSpecs: []config.RefSpec{blobRefSpec},
+				Tags:     git.NoTags, // Covered by the refspec
+			}
+			auth, err := g.getAuthentication(gitURL)
+			if err != nil {
+				return err
+			}
+			if auth != nil {
+				fetchOptions.Auth = auth
+			}
+			fetchOptions.CABundle, fetchOptions.InsecureSkipTLS, fetchOptions.ProxyOptions = g.transportOptions(gitURL.RepoURL)
+			if progress := g.newProgressWriter(gitURL.RepoURL); progress != nil {
+				fetchOptions.Progress = progress
+			}
+
+			fetchCtx, cancel := context.WithTimeout(ctx, g.options.effectiveCloneTimeout())
+			defer cancel()
+
+			if err := remote.FetchContext(fetchCtx, fetchOptions); err != nil && !stderrors.Is(err, git.NoErrAlreadyUpToDate) {
+				return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to fetch repository")
+			}
+			return nil
+		}, "git fetch")
+	})
+	if err != nil {
+		closeRepository(repo)
+		g.logEvent(ctx, slog.LevelWarn, "fetch failed", append(logURLAttrs(gitURL), logErrorAttr(err))...)
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// readBlob reads the content of a blob, enforcing the configuration file size limit
+func readBlob(repo *git.Repository, hash string) ([]byte, error) {
+	blob, err := repo.BlobObject(plumbing.NewHash(hash))
+	if stderrors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, errors.New("ARGUS_CONFIG_NOT_FOUND",
+			fmt.Sprintf("blob not found in repository: %s (it must be reachable from a reference)", hash))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", fmt.Sprintf("failed to read blob: %s", hash))
+	}
+
+	if blob.Size > maxConfigFileSize {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration blob too large: %d bytes (max %d)", blob.Size, maxConfigFileSize))
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", fmt.Sprintf("failed to read blob: %s", hash))
+	}
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxConfigFileSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", fmt.Sprintf("failed to read blob: %s", hash))
+	}
+	return content, nil
+}
+
+// treeBlobURL returns the URL under which the configuration parsed from the blob of
+// the URL's file at the reference is cached by blob, with the hash of the commit it
+// resolved to in the clone. It returns nil for files reached through symbolic links,
+// which are validated by their target path, and with includes, since configurations
+// then depend on other files too.
+func (g *GitProvider) treeBlobURL(repo *git.Repository, gitURL *GitURL) (*GitURL, string) {
+	if g.options.includes {
+		return nil, ""
+	}
+
+	commit, ok := resolveReferenceCommit(repo, gitURL.Reference)
+	if !ok {
+		return nil, ""
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, ""
+	}
+	entry, err := tree.FindEntry(gitURL.FilePath)
+	if err != nil || !entry.Mode.IsFile() || entry.Mode == filemode.Symlink {
+		return nil, ""
+	}
+
+	// Compressed files are keyed apart from uncompressed ones of the same blob
+	format := resultFormat(gitURL.FilePath, gitURL.Format)
+	if _, compressed := uncompressedPath(gitURL.FilePath); compressed {
+		format += ".gz"
+	}
+
+	blobURL := &GitURL{
+		RepoURL:      gitURL.RepoURL,
+		Blob:         entry.Hash.String(),
+		Format:       format,
+		cacheScope:   gitURL.cacheScope,
+		requestScope: gitURL.requestScope,
+	}
+	return blobURL, commit.Hash.String()
+}
+
+// displayPath names the URL's content in logs and errors: its file path or its blob
+func (u *GitURL) displayPath() string {
+	if u.Blob != "" {
+		return "blob " + u.Blob
+	}
+	return u.FilePath
+}
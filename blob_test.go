@@ -0,0 +1,184 @@
+// blob_test.go
+//
+// Blob loading tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// storeBlob writes content to the repository object store and points ref at it,
+// as a CI step publishing rendered configuration would; it returns the blob hash
+func (r *testRepository) storeBlob(ref, content string) string {
+	r.t.Helper()
+
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	writer, err := obj.Writer()
+	if err != nil {
+		r.t.Fatalf("Failed to create blob: %v", err)
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		r.t.Fatalf("Failed to write blob: %v", err)
+	}
+	_ = writer.Close()
+
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		r.t.Fatalf("Failed to store blob: %v", err)
+	}
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(ref), hash)); err != nil {
+		r.t.Fatalf("Failed to create %s: %v", ref, err)
+	}
+	return hash.String()
+}
+
+// TestGitProvider_Blob tests loading configuration from a blob by object hash
+func TestGitProvider_Blob(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"app.yaml": "service: api\n"})
+	rendered := repo.storeBlob("refs/tags/rendered-config", `{"service": "rendered", "replicas": 3}`)
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#?blob="
+
+	t.Run("Blob referenced by a tag", func(t *testing.T) {
+		result, err := provider.LoadDetailed(ctx, baseURL+rendered+"&format=json")
+		if err != nil {
+			t.Fatalf("LoadDetailed failed: %v", err)
+		}
+		if result.Config["service"] != "rendered" || result.Format != FormatJSON || result.Size != 38 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		// Blobs are immutable, so a second load is served from the cache
+		stats := provider.Metrics()
+		if _, err := provider.Load(ctx, baseURL+rendered+"&format=json"); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if provider.Metrics().CacheHits != stats.CacheHits+1 {
+			t.Error("Expected the blob to be served from the cache")
+		}
+	})
+
+	t.Run("Blob of a tree file", func(t *testing.T) {
+		head, _ := repo.repo.Head()
+		commit, _ := repo.repo.CommitObject(head.Hash())
+		file, err := commit.File("app.yaml")
+		if err != nil {
+			t.Fatalf("Failed to find app.yaml: %v", err)
+		}
+
+		config, err := provider.Load(ctx, baseURL+strings.ToUpper(file.Hash.String())+"&format=yml")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if config["service"] != "api" {
+			t.Errorf("Unexpected config: %v", config)
+		}
+	})
+
+	t.Run("Unreachable blob", func(t *testing.T) {
+		_, err := provider.Load(ctx, baseURL+strings.Repeat("ab", 20)+"&format=json")
+		if !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+			t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND, got %v", err)
+		}
+	})
+
+	t.Run("Parsed blob", func(t *testing.T) {
+		parsed, err := provider.ParseURL(baseURL + rendered + "&format=json")
+		if err != nil {
+			t.Fatalf("ParseURL failed: %v", err)
+		}
+		if parsed.Blob != rendered || parsed.Format != FormatJSON || !parsed.Pinned || parsed.Reference != "" {
+			t.Errorf("Unexpected parsed URL: %+v", parsed)
+		}
+	})
+
+	t.Run("Invalid blob URLs", func(t *testing.T) {
+		for _, query := range []string{
+			rendered,                         // No format
+			rendered + "&format=xml",         // Unregistered format
+			"abc123&format=json",             // Abbreviated hash
+			rendered + "&format=json&ref=v1", // Reference
+			rendered + "&format=json&release=latest",
+		} {
+			if err := provider.Validate(baseURL + query); err == nil {
+				t.Errorf("Expected %s to be rejected", query)
+			}
+		}
+
+		if err := provider.Validate("file://" + repo.dir + "#app.yaml?blob=" + rendered + "&format=json"); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected a blob with a file path to be rejected, got %v", err)
+		}
+	})
+}
+
+// TestGitProvider_BlobCache tests that commits leaving a file unchanged reuse the
+// configuration parsed for its blob
+func TestGitProvider_BlobCache(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"app.json":   `{"service": "api"}`,
+		"other.json": `{"version": 1}`,
+	})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configURL := "file://" + repo.dir + "#app.json"
+	load := func(t *testing.T) *LoadResult {
+		t.Helper()
+		result, err := provider.LoadDetailed(ctx, configURL)
+		if err != nil {
+			t.Fatalf("LoadDetailed failed: %v", err)
+		}
+		return result
+	}
+
+	load(t)
+	if hits := provider.Metrics().BlobCacheHits; hits != 0 {
+		t.Fatalf("Expected no blob cache hit on the first load, got %d", hits)
+	}
+
+	// A commit touching another file is a new commit but the same blob
+	second := repo.commit("touch other file", map[string]string{"other.json": `{"version": 2}`})
+	result := load(t)
+	if hits := provider.Metrics().BlobCacheHits; hits != 1 {
+		t.Errorf("Expected a blob cache hit for the unchanged file, got %d", hits)
+	}
+	if result.CommitHash != second || result.Config["service"] != "api" || result.Format != FormatJSON {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Changing the file itself parses the new blob
+	repo.commit("change file", map[string]string{"app.json": `{"service": "web"}`})
+	if result := load(t); result.Config["service"] != "web" {
+		t.Errorf("Expected the changed config, got %v", result.Config)
+	}
+	if hits := provider.Metrics().BlobCacheHits; hits != 1 {
+		t.Errorf("Expected no blob cache hit for the changed file, got %d", hits)
+	}
+}
@@ -0,0 +1,39 @@
+// cachescope.go: Request-scoped configuration cache partitions
+//
+// The configuration cache is keyed by repository, file, reference and commit, so
+// every caller loading the same file at the same commit shares one entry. Services
+// post-processing configurations per tenant or environment, e.g. expanding
+// variables that differ between tenants, attach a scope to the request context so
+// that each scope caches its own results:
+//
+//	ctx = git.WithCacheScope(ctx, "tenant-"+tenant.ID)
+//	config, err := provider.Load(ctx, "https://github.com/org/configs.git#app.json")
+//
+// Scopes partition the in-memory, last-known-good and disk caches alike. They
+// combine with the credential partitions of WithAuthOverride, and unlike
+// overrides they also apply to local repositories.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import "context"
+
+// cacheScopeKey is the context key of the request cache scope
+type cacheScopeKey struct{}
+
+// WithCacheScope returns a context whose Load, LoadDetailed, LoadFiles and Watch
+// calls cache configurations in a partition of their own named scope. Requests
+// without a scope, or with an empty one, share the unscoped partition.
+func WithCacheScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, cacheScopeKey{}, scope)
+}
+
+// applyCacheScope sets the cache partition of the URL from the request context, if any
+func applyCacheScope(ctx context.Context, gitURL *GitURL) {
+	if scope, ok := ctx.Value(cacheScopeKey{}).(string); ok {
+		gitURL.requestScope = scope
+	}
+}
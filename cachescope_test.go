@@ -0,0 +1,84 @@
+// cachescope_test.go
+//
+// Request cache scope tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestWithCacheScope tests that cache scopes keep results of the same commit apart
+func TestWithCacheScope(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"app.json": `{"endpoint": "${HOST}"}`})
+	commitHash := repo.commit("update", map[string]string{"app.json": `{"endpoint": "${HOST}"}`, "README.md": "configs\n"})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configURL := "file://" + repo.dir + "#app.json"
+	scoped := map[string]context.Context{
+		"":         ctx,
+		"tenant-a": WithCacheScope(ctx, "tenant-a"),
+		"tenant-b": WithCacheScope(ctx, "tenant-b"),
+	}
+
+	// Every scope misses the cache on its first load of the commit
+	for scope, scopeCtx := range scoped {
+		misses := provider.Metrics().CacheMisses
+		if _, err := provider.Load(scopeCtx, configURL); err != nil {
+			t.Fatalf("Load in scope %q failed: %v", scope, err)
+		}
+		if provider.Metrics().CacheMisses != misses+1 {
+			t.Errorf("Expected scope %q to miss the cache", scope)
+		}
+	}
+
+	// Post-process the cached result of each tenant as a tenant-aware layer would
+	for _, scope := range []string{"tenant-a", "tenant-b"} {
+		gitURL, err := provider.parseRequestURL(scoped[scope], configURL, true)
+		if err != nil {
+			t.Fatalf("parseRequestURL failed: %v", err)
+		}
+		provider.configCache.put(gitURL, commitHash, map[string]interface{}{"endpoint": scope + ".example.com"})
+	}
+
+	for scope, want := range map[string]string{
+		"":         "${HOST}",
+		"tenant-a": "tenant-a.example.com",
+		"tenant-b": "tenant-b.example.com",
+	} {
+		hits := provider.Metrics().CacheHits
+		config, err := provider.Load(scoped[scope], configURL)
+		if err != nil {
+			t.Fatalf("Load in scope %q failed: %v", scope, err)
+		}
+		if config["endpoint"] != want {
+			t.Errorf("Expected scope %q to load endpoint %q, got %v", scope, want, config["endpoint"])
+		}
+		if provider.Metrics().CacheHits != hits+1 {
+			t.Errorf("Expected scope %q to hit the cache", scope)
+		}
+	}
+
+	// An empty scope is the unscoped partition
+	config, err := provider.Load(WithCacheScope(ctx, ""), configURL)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config["endpoint"] != "${HOST}" {
+		t.Errorf("Expected the unscoped result for an empty scope, got %v", config)
+	}
+}
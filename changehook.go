@@ -0,0 +1,103 @@
+// changehook.go: Configuration change hooks for watches
+//
+// The watch channel only carries the new configuration. WithChangeHook registers
+// a callback that receives each change with its context: the configuration before
+// and after, and the commit and reference it came from, e.g. to bust caches or
+// signal a reload. Only real changes are reported: a new commit that leaves the
+// watched file's content unchanged (as compared by ConfigHash) fires no event, and
+// neither does the initial delivery.
+//
+// Hooks run on a goroutine of their own per watch, in delivery order, so a slow
+// hook never delays polling or channel delivery. While a hook falls behind by more
+// than changeHookQueueSize events, further events are dropped with a warning.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// changeHookQueueSize bounds the events of a watch waiting for its change hook
+const changeHookQueueSize = 16
+
+// ChangeEvent describes a configuration change delivered by a watch
+type ChangeEvent struct {
+	Time       time.Time              // When the new configuration was delivered
+	RepoURL    string                 // Repository URL with credentials redacted
+	FilePath   string                 // Configuration file path
+	Reference  string                 // Watched reference
+	CommitHash string                 // Commit the new configuration was read from
+	Old        map[string]interface{} // Configuration delivered before the change
+	New        map[string]interface{} // Configuration delivered with the change
+}
+
+// changeHookQueue runs the change hook of one watch on its own goroutine
+type changeHookQueue struct {
+	g      *GitProvider
+	gitURL *GitURL
+	events chan ChangeEvent
+}
+
+// startChangeHooks starts the change hook goroutine of a watch; it returns nil when
+// no change hook is configured
+func (g *GitProvider) startChangeHooks(gitURL *GitURL) *changeHookQueue {
+	if g.options.changeHook == nil {
+		return nil
+	}
+
+	q := &changeHookQueue{g: g, gitURL: gitURL, events: make(chan ChangeEvent, changeHookQueueSize)}
+	go func() {
+		for event := range q.events {
+			q.run(event)
+		}
+	}()
+	return q
+}
+
+// notify queues a change event between two delivered configurations
+func (q *changeHookQueue) notify(old map[string]interface{}, result *LoadResult) {
+	if q == nil {
+		return
+	}
+
+	event := ChangeEvent{
+		Time:       time.Now(),
+		RepoURL:    redactLogText(q.gitURL.RepoURL),
+		FilePath:   q.gitURL.FilePath,
+		Reference:  q.gitURL.Reference,
+		CommitHash: result.CommitHash,
+		Old:        old,
+		New:        result.Config,
+	}
+	select {
+	case q.events <- event:
+	default:
+		q.g.logEvent(context.Background(), slog.LevelWarn, "change hook behind, change event dropped",
+			append(logURLAttrs(q.gitURL), slog.String("commit", result.CommitHash))...)
+	}
+}
+
+// run calls the change hook; a panicking hook is logged instead of crashing the process
+func (q *changeHookQueue) run(event ChangeEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.g.logEvent(context.Background(), slog.LevelError, "change hook panicked",
+				append(logURLAttrs(q.gitURL), slog.String("panic", fmt.Sprint(r)))...)
+		}
+	}()
+	q.g.options.changeHook(event)
+}
+
+// stop ends the change hook goroutine once the queued events have run
+func (q *changeHookQueue) stop() {
+	if q != nil {
+		close(q.events)
+	}
+}
@@ -0,0 +1,95 @@
+// changehook_test.go
+//
+// Watch change hook tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestWithChangeHook tests that watches report content changes to the change hook
+func TestWithChangeHook(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"replicas": 1}`})
+
+	// The hook blocks until released, to show that a slow hook does not stall the watch
+	release := make(chan struct{})
+	events := make(chan ChangeEvent, 10)
+	provider, err := NewProvider(WithAllowLocalRepos(true), WithChangeHook(func(e ChangeEvent) {
+		<-release
+		events <- e
+	}))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	gitURL := repo.gitURL("config.json", "main")
+	gitURL.PollInterval = 20 * time.Millisecond
+	configs := make(chan map[string]interface{}, 1)
+	provider.incrementWatchCount()
+	go provider.startWatching(ctx, gitURL, configs, nil)
+
+	receive := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		select {
+		case config := <-configs:
+			return config
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for a configuration")
+			return nil
+		}
+	}
+
+	receive(t) // Initial delivery, which is not a change
+
+	// A commit that leaves the watched file unchanged is delivered but is no change
+	repo.commit("docs", map[string]string{"README.md": "configs\n"})
+	receive(t)
+
+	first := repo.commit("scale up", map[string]string{"config.json": `{"replicas": 2}`})
+	if config := receive(t); config["replicas"] != float64(2) {
+		t.Fatalf("Unexpected config: %v", config)
+	}
+	second := repo.commit("scale up again", map[string]string{"config.json": `{"replicas": 3}`})
+	if config := receive(t); config["replicas"] != float64(3) {
+		t.Fatalf("Unexpected config: %v", config)
+	}
+
+	close(release)
+	for i, want := range []struct {
+		commit   string
+		old, new float64
+	}{{first, 1, 2}, {second, 2, 3}} {
+		select {
+		case event := <-events:
+			if event.CommitHash != want.commit || event.Reference != "main" || event.FilePath != "config.json" {
+				t.Errorf("Unexpected event %d: %+v", i, event)
+			}
+			if event.Old["replicas"] != want.old || event.New["replicas"] != want.new {
+				t.Errorf("Expected event %d to change replicas from %v to %v, got %v -> %v", i, want.old, want.new, event.Old, event.New)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for change event %d", i)
+		}
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("Unexpected extra event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := NewProvider(WithChangeHook(nil)); err == nil {
+		t.Error("Expected error for nil change hook")
+	}
+}
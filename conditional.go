@@ -0,0 +1,121 @@
+// conditional.go: Conditional configuration loads
+//
+// Services polling configuration from their own loops, rather than through Watch,
+// can ask for a configuration only if it changed since the commit they last saw,
+// as an If-None-Match request would:
+//
+//	config, commit, changed, err := provider.LoadIfChanged(ctx, configURL, lastCommit)
+//	if err == nil && changed {
+//	    apply(config)
+//	    lastCommit = commit
+//	}
+//
+// The check is a single remote reference listing; the repository is only cloned
+// when the commit moved. Commits resolved for revision expressions are mapped to
+// the ancestor through the configuration cache, so they compare like branches.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agilira/go-errors"
+)
+
+// LoadIfChanged loads the configuration at configURL unless the commit it resolves to
+// is lastCommit. When it is, changed is false and the configuration is nil, without
+// cloning; otherwise the configuration is loaded as by LoadDetailed and returned with
+// the commit it was read from, to pass as lastCommit on the next call. An empty
+// lastCommit always loads. When the commit cannot be looked up, the configuration is
+// loaded, so fallback references and stale fallback apply as for Load; a load served
+// by lastCommit, or a stale configuration served during an outage, is reported as
+// unchanged. Release and blob URLs, which have no commit to compare, fail with
+// ARGUS_INVALID_CONFIG.
+func (g *GitProvider) LoadIfChanged(ctx context.Context, configURL, lastCommit string) (config map[string]interface{}, commit string, changed bool, err error) {
+	current, err := g.currentCommit(ctx, configURL)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if lastCommit != "" && strings.EqualFold(current, lastCommit) {
+		return nil, current, false, nil
+	}
+
+	result, err := g.LoadDetailed(ctx, configURL)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if servesLastCommit(result, lastCommit) {
+		return nil, lastCommit, false, nil
+	}
+
+	// Raw downloads report no commit; the one looked up identifies the content instead
+	commit = result.CommitHash
+	if commit == "" {
+		commit = current
+	}
+	return result.Config, commit, true, nil
+}
+
+// servesLastCommit reports whether a load brought nothing new to a caller at lastCommit:
+// a fallback reference may serve that commit again, and stale fallback the last-known-good
+// configuration during an outage
+func servesLastCommit(result *LoadResult, lastCommit string) bool {
+	return lastCommit != "" && (result.Stale || strings.EqualFold(result.CommitHash, lastCommit))
+}
+
+// currentCommit returns the commit a configuration URL would be loaded from, or the
+// empty string when it cannot be determined without loading
+func (g *GitProvider) currentCommit(ctx context.Context, configURL string) (string, error) {
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err := g.parseRequestURL(ctx, configURL, true)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+	if gitURL.Release != "" || gitURL.Blob != "" {
+		return "", errors.New("ARGUS_INVALID_CONFIG",
+			"conditional loads need a commit reference, not a release or blob")
+	}
+	gitURL, _ = g.applyRefPin(gitURL)
+
+	commitHash := strings.ToLower(revisionBase(gitURL.Reference))
+	if !isCommitHash(commitHash) {
+		if commitHash, err = g.getRemoteCommitHash(ctx, gitURL); err != nil {
+			g.logEvent(ctx, slog.LevelDebug, "commit lookup failed, loading",
+				append(logURLAttrs(gitURL), logErrorAttr(err))...)
+			return "", nil
+		}
+	}
+
+	// A revision expression resolves its base; the ancestor is known once it was loaded
+	if isRevisionExpression(gitURL.Reference) {
+		cached, found := g.configCache.getResult(gitURL, commitHash)
+		if !found {
+			return "", nil
+		}
+		return cached.CommitHash, nil
+	}
+	return commitHash, nil
+}
@@ -0,0 +1,88 @@
+// conditional_test.go
+//
+// Conditional configuration load tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestGitProvider_LoadIfChanged tests that unchanged commits are reported without cloning
+func TestGitProvider_LoadIfChanged(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+	first := repo.commit("version 2", map[string]string{"config.json": `{"version": 2}`})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configURL := "file://" + repo.dir + "#config.json?ref=main"
+	config, commit, changed, err := provider.LoadIfChanged(ctx, configURL, "")
+	if err != nil {
+		t.Fatalf("LoadIfChanged failed: %v", err)
+	}
+	if !changed || commit != first || config["version"] != float64(2) {
+		t.Errorf("Expected version 2 at %s, got %v at %s (changed=%v)", first, config, commit, changed)
+	}
+
+	clones := provider.Metrics().TempDirsCreated
+	config, commit, changed, err = provider.LoadIfChanged(ctx, configURL, first)
+	if err != nil || changed || config != nil || commit != first {
+		t.Errorf("Expected no change at %s, got %v at %s (changed=%v, err=%v)", first, config, commit, changed, err)
+	}
+	if created := provider.Metrics().TempDirsCreated; created != clones {
+		t.Errorf("Expected no clone for an unchanged commit, got %d", created-clones)
+	}
+
+	// Ancestors compare by their own commit, which the first load resolved
+	_, parent, _, err := provider.LoadIfChanged(ctx, "file://"+repo.dir+"#config.json?ref=main~1", "")
+	if err != nil || parent == first {
+		t.Fatalf("Expected the parent commit of %s, got %s (err=%v)", first, parent, err)
+	}
+	clones = provider.Metrics().TempDirsCreated
+	if _, _, changed, err := provider.LoadIfChanged(ctx, "file://"+repo.dir+"#config.json?ref=main~1", parent); err != nil || changed {
+		t.Errorf("Expected no change of main~1, got changed=%v (err=%v)", changed, err)
+	}
+	if created := provider.Metrics().TempDirsCreated; created != clones {
+		t.Errorf("Expected no clone for an unchanged ancestor, got %d", created-clones)
+	}
+
+	second := repo.commit("version 3", map[string]string{"config.json": `{"version": 3}`})
+	config, commit, changed, err = provider.LoadIfChanged(ctx, configURL, first)
+	if err != nil || !changed || commit != second || config["version"] != float64(3) {
+		t.Errorf("Expected version 3 at %s, got %v at %s (changed=%v, err=%v)", second, config, commit, changed, err)
+	}
+
+	// A missing reference served by its fallback compares by the commit that served it
+	fallbackURL := "file://" + repo.dir + "#config.json?ref=staging&fallback_ref=main"
+	if _, _, changed, err := provider.LoadIfChanged(ctx, fallbackURL, second); err != nil || changed {
+		t.Errorf("Expected no change of the fallback commit, got changed=%v (err=%v)", changed, err)
+	}
+
+	if _, _, _, err := provider.LoadIfChanged(ctx, "file://"+repo.dir+"#?blob="+first, first); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for a blob URL, got %v", err)
+	}
+
+	// The last-known-good configuration served during an outage is no change
+	stale := &LoadResult{CommitHash: first, Stale: true}
+	if !servesLastCommit(stale, second) {
+		t.Error("Expected a stale configuration not to be reported as changed")
+	}
+	if servesLastCommit(stale, "") {
+		t.Error("Expected a stale configuration to be returned without a last commit")
+	}
+}
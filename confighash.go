@@ -0,0 +1,147 @@
+// confighash.go: Canonical configuration hashing
+//
+// ConfigHash fingerprints a parsed configuration independently of how it was
+// written: map keys are hashed in sorted order, and numbers by value rather than
+// by Go type, so the same settings in JSON, YAML or TOML hash the same.
+// Numbers compare as the format decoders produce them: 3, 3.0, int64(3) and
+// json.Number("3") are equal, and timestamps decoded by YAML or TOML are hashed as
+// their RFC 3339 text, as JSON would write them. Strings and numbers never collide,
+// so {"port": "8080"} and {"port": 8080} hash differently.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ConfigHash returns the hex SHA-256 of the canonical form of a configuration, for
+// detecting content changes independently of key order and source format.
+//
+// Example:
+//
+//	if git.ConfigHash(config) != deployedHash {
+//	    rollout(config)
+//	}
+func ConfigHash(config map[string]interface{}) string {
+	h := sha256.New()
+	writeCanonical(h, config)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCanonical writes the canonical encoding of a decoded configuration value: a
+// JSON-like text with sorted object keys and normalized numbers
+func writeCanonical(h hash.Hash, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		_, _ = h.Write([]byte("null"))
+	case bool:
+		_, _ = h.Write([]byte(strconv.FormatBool(v)))
+	case string:
+		_, _ = h.Write([]byte(strconv.Quote(v)))
+	case time.Time:
+		_, _ = h.Write([]byte(strconv.Quote(v.Format(time.RFC3339Nano))))
+	case json.Number:
+		_, _ = h.Write([]byte(canonicalJSONNumber(v)))
+	case float64:
+		_, _ = h.Write([]byte(canonicalFloat(v)))
+	case float32:
+		_, _ = h.Write([]byte(canonicalFloat(float64(v))))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		writeCanonicalObject(h, keys, func(key string) interface{} { return v[key] })
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(v))
+		values := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name := fmt.Sprint(key)
+			keys = append(keys, name)
+			values[name] = item
+		}
+		writeCanonicalObject(h, keys, func(key string) interface{} { return values[key] })
+	case []interface{}:
+		_, _ = h.Write([]byte("["))
+		for i, item := range v {
+			if i > 0 {
+				_, _ = h.Write([]byte(","))
+			}
+			writeCanonical(h, item)
+		}
+		_, _ = h.Write([]byte("]"))
+	default:
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			_, _ = h.Write([]byte(strconv.FormatInt(rv.Int(), 10)))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, _ = h.Write([]byte(strconv.FormatUint(rv.Uint(), 10)))
+		case reflect.Slice, reflect.Array:
+			items := make([]interface{}, rv.Len())
+			for i := range items {
+				items[i] = rv.Index(i).Interface()
+			}
+			writeCanonical(h, items)
+		default:
+			// Decoder-specific values, e.g. TOML local dates, are hashed by their text with their type
+			_, _ = h.Write([]byte(strconv.Quote(fmt.Sprintf("%T:%v", value, value))))
+		}
+	}
+}
+
+// writeCanonicalObject writes an object with its keys in sorted order
+func writeCanonicalObject(h hash.Hash, keys []string, valueOf func(string) interface{}) {
+	sort.Strings(keys)
+	_, _ = h.Write([]byte("{"))
+	for i, key := range keys {
+		if i > 0 {
+			_, _ = h.Write([]byte(","))
+		}
+		_, _ = h.Write([]byte(strconv.Quote(key) + ":"))
+		writeCanonical(h, valueOf(key))
+	}
+	_, _ = h.Write([]byte("}"))
+}
+
+// canonicalFloat formats a float so that integral values match their integer form
+func canonicalFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case f == 0:
+		return "0" // Including negative zero
+	case f == math.Trunc(f):
+		return big.NewFloat(f).Text('f', 0)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// canonicalJSONNumber formats a json.Number as the integer or float it holds
+func canonicalJSONNumber(n json.Number) string {
+	if i, ok := new(big.Int).SetString(n.String(), 10); ok {
+		return i.String()
+	}
+	if f, err := n.Float64(); err == nil {
+		return canonicalFloat(f)
+	}
+	return strconv.Quote(n.String())
+}
@@ -0,0 +1,101 @@
+// confighash_test.go
+//
+// Canonical configuration hashing tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// TestConfigHash tests that logically equal configurations hash the same across formats
+func TestConfigHash(t *testing.T) {
+	provider := GetProvider().(*GitProvider)
+	parse := func(t *testing.T, filePath, content string) map[string]interface{} {
+		t.Helper()
+		config, err := provider.parseConfigFile(filePath, []byte(content))
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", filePath, err)
+		}
+		return config
+	}
+
+	fromJSON := parse(t, "app.json", `{
+		"service": "api",
+		"replicas": 3,
+		"ratio": 0.25,
+		"enabled": true,
+		"started": "2025-01-02T03:04:05Z",
+		"database": {"port": 5432, "hosts": ["a", "b"], "password": null}
+	}`)
+	fromYAML := parse(t, "app.yaml", `
+database:
+  password: ~
+  hosts: [a, b]
+  port: 5432
+started: 2025-01-02T03:04:05Z
+enabled: true
+ratio: 0.25
+replicas: 3.0
+service: api
+`)
+
+	hash := ConfigHash(fromJSON)
+	if len(hash) != 64 {
+		t.Fatalf("Expected a hex SHA-256, got %q", hash)
+	}
+	if ConfigHash(fromYAML) != hash {
+		t.Error("Expected JSON and YAML with the same content to hash the same")
+	}
+	for i := 0; i < 10; i++ {
+		if ConfigHash(fromJSON) != hash {
+			t.Fatal("Expected the hash to be independent of map iteration order")
+		}
+	}
+
+	t.Run("TOML", func(t *testing.T) {
+		fromTOML := parse(t, "app.toml", "service = \"api\"\nreplicas = 3\n")
+		if ConfigHash(fromTOML) != ConfigHash(map[string]interface{}{"replicas": 3.0, "service": "api"}) {
+			t.Error("Expected TOML integers to hash like JSON numbers")
+		}
+	})
+
+	t.Run("Number representations", func(t *testing.T) {
+		want := ConfigHash(map[string]interface{}{"n": 3})
+		for _, n := range []interface{}{int64(3), uint8(3), 3.0, float32(3), json.Number("3"), json.Number("3.0")} {
+			if ConfigHash(map[string]interface{}{"n": n}) != want {
+				t.Errorf("Expected %T(%v) to hash like 3", n, n)
+			}
+		}
+		if ConfigHash(map[string]interface{}{"n": 0.0}) != ConfigHash(map[string]interface{}{"n": math.Copysign(0, -1)}) {
+			t.Error("Expected zero to hash consistently")
+		}
+	})
+
+	t.Run("Differences are detected", func(t *testing.T) {
+		base := map[string]interface{}{"port": 8080}
+		for _, other := range []map[string]interface{}{
+			{"port": "8080"},
+			{"port": 8081},
+			{"port": 8080, "debug": false},
+			{"port": []interface{}{8080}},
+			{"Port": 8080},
+			{},
+		} {
+			if ConfigHash(other) == ConfigHash(base) {
+				t.Errorf("Expected %v to hash differently from %v", other, base)
+			}
+		}
+
+		// Nesting is part of the structure, not flattened into keys
+		if ConfigHash(map[string]interface{}{"a": map[string]interface{}{"b": 1}}) == ConfigHash(map[string]interface{}{"a.b": 1}) {
+			t.Error("Expected nested and dotted keys to hash differently")
+		}
+	})
+}
@@ -0,0 +1,266 @@
+// credentials.go: Runtime credential rotation
+//
+// Long-running services rotating deploy tokens through a secrets manager can hand
+// the new credentials to a running provider instead of recreating it:
+//
+//	err := provider.SetCredential("https://github.com/org/configs.git",
+//	    git.AuthMethodSpec{Type: "token", Token: rotated})
+//
+// Registered credentials replace the authentication of every URL of the repository,
+// including the auth parameter of URLs watched before the rotation: they are looked
+// up whenever a Git operation authenticates, so active watches use them from their
+// next poll on without being restarted. Request-scoped overrides from WithAuthOverride
+// still take precedence.
+//
+// When many repositories share the credentials of their host, they can be registered
+// once per host instead, like a Git credential helper:
+//
+//	err := provider.SetHostCredential("gitlab.internal",
+//	    git.AuthMethodSpec{Type: "token", Token: deployToken})
+//
+// Host credentials are used by URLs of the host without an auth parameter of their
+// own; credentials in the URL or registered for the repository take precedence. As
+// Git does with a rejected helper credential, host credentials the server rejects are
+// removed from the store, so later requests do not repeat them.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/agilira/go-errors"
+)
+
+// registeredCredential holds the validated credentials registered for a repository
+type registeredCredential struct {
+	authType    string
+	authData    map[string]string
+	fingerprint string // Distinguishes rotated credentials in the authentication cache
+}
+
+// SetCredential registers the credentials used for a repository, replacing those of
+// its URLs and any registered before. repoURL accepts the same base URLs as
+// configuration URLs; a file path or parameters in it are ignored. Cached
+// authentication for the repository is discarded. Invalid specs fail with
+// ARGUS_INVALID_CONFIG or ARGUS_AUTH_ERROR as for WithAuthOverride.
+func (g *GitProvider) SetCredential(repoURL string, spec AuthMethodSpec) error {
+	gitURL, err := g.parseGitURLWith(repoURL, false)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(gitURL.RepoURL, "file://") {
+		return errors.New("ARGUS_INVALID_CONFIG", "authentication is not supported for local repositories")
+	}
+	if isGitProtocolURL(gitURL.RepoURL) {
+		return errors.New("ARGUS_INVALID_CONFIG", "authentication is not supported for git:// repositories")
+	}
+
+	return g.setCredential(gitURL.RepoURL, spec)
+}
+
+// RemoveCredential removes the credentials registered for a repository with
+// SetCredential, so that its URLs authenticate with their own auth parameters again
+func (g *GitProvider) RemoveCredential(repoURL string) error {
+	gitURL, err := g.parseGitURLWith(repoURL, false)
+	if err != nil {
+		return err
+	}
+
+	g.credentialsMutex.Lock()
+	delete(g.credentials, gitURL.RepoURL)
+	g.credentialsMutex.Unlock()
+
+	g.evictAuthCache(gitURL.RepoURL)
+	return nil
+}
+
+// setCredential registers credentials for a normalized repository URL
+func (g *GitProvider) setCredential(repoURL string, spec AuthMethodSpec) error {
+	authData, err := spec.authData()
+	if err != nil {
+		return err
+	}
+
+	g.credentialsMutex.Lock()
+	if g.credentials == nil {
+		g.credentials = make(map[string]registeredCredential)
+	}
+	g.credentials[repoURL] = registeredCredential{
+		authType:    strings.ToLower(spec.Type),
+		authData:    authData,
+		fingerprint: spec.fingerprint(),
+	}
+	g.credentialsMutex.Unlock()
+
+	g.evictAuthCache(repoURL)
+	return nil
+}
+
+// SetHostCredential registers the credentials used for repositories on a host whose
+// URLs carry no authentication of their own, replacing any registered before for the
+// host. host is a bare host name such as "gitlab.internal", matched case-insensitively
+// and regardless of port. Cached authentication for the host is discarded. Invalid
+// specs fail as for SetCredential; private and loopback hosts fail with
+// ARGUS_SECURITY_ERROR.
+func (g *GitProvider) SetHostCredential(host string, spec AuthMethodSpec) error {
+	host, err := normalizeCredentialHost(host)
+	if err != nil {
+		return err
+	}
+
+	return g.setHostCredential(host, spec)
+}
+
+// setHostCredential registers credentials for a normalized host name
+func (g *GitProvider) setHostCredential(host string, spec AuthMethodSpec) error {
+	authData, err := spec.authData()
+	if err != nil {
+		return err
+	}
+
+	g.credentialsMutex.Lock()
+	if g.hostCredentials == nil {
+		g.hostCredentials = make(map[string]registeredCredential)
+	}
+	g.hostCredentials[host] = registeredCredential{
+		authType:    strings.ToLower(spec.Type),
+		authData:    authData,
+		fingerprint: "host:" + spec.fingerprint(),
+	}
+	g.credentialsMutex.Unlock()
+
+	g.evictHostAuthCache(host)
+	return nil
+}
+
+// RemoveHostCredential removes the credentials registered for a host with
+// SetHostCredential
+func (g *GitProvider) RemoveHostCredential(host string) error {
+	host, err := normalizeCredentialHost(host)
+	if err != nil {
+		return err
+	}
+
+	g.credentialsMutex.Lock()
+	delete(g.hostCredentials, host)
+	g.credentialsMutex.Unlock()
+
+	g.evictHostAuthCache(host)
+	return nil
+}
+
+// normalizeCredentialHost validates a host name for the host credential store and
+// returns it in lowercase
+func normalizeCredentialHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || strings.ContainsAny(host, "/@?#:[] \t") {
+		return "", errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("invalid credential host %q: expected a bare host name", host))
+	}
+	if err := validateGitHost(host); err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// credentialHost returns the lowercase host name of a repository URL, including the
+// scp-like SSH form user@host:path
+func credentialHost(repoURL string) string {
+	if !strings.Contains(repoURL, "://") {
+		if at := strings.Index(repoURL, "@"); at != -1 {
+			if host, _, ok := strings.Cut(repoURL[at+1:], ":"); ok {
+				return strings.ToLower(host)
+			}
+		}
+		return ""
+	}
+	return repoHostname(repoURL)
+}
+
+// withCredential returns the URL with the credentials registered for its repository,
+// or for its host when the URL has no authentication, or the URL itself when there
+// are none or its credentials must not be replaced
+func (g *GitProvider) withCredential(gitURL *GitURL) *GitURL {
+	if gitURL.authOverride || gitURL.anonymous {
+		return gitURL
+	}
+
+	g.credentialsMutex.RLock()
+	credential, ok := g.credentials[gitURL.RepoURL]
+	if !ok && gitURL.AuthType == "" && len(g.hostCredentials) > 0 && !isGitProtocolURL(gitURL.RepoURL) {
+		credential, ok = g.hostCredentials[credentialHost(gitURL.RepoURL)]
+	}
+	g.credentialsMutex.RUnlock()
+	if !ok {
+		return gitURL
+	}
+
+	withCredential := *gitURL
+	withCredential.AuthType = credential.authType
+	withCredential.AuthData = credential.authData
+	withCredential.credentialID = credential.fingerprint
+	return &withCredential
+}
+
+// rejectHostCredential wraps a Git operation so that host credentials rejected by the
+// server are removed from the store. Credentials registered again since the operation
+// started are kept.
+func (g *GitProvider) rejectHostCredential(operation func(target *GitURL) error) func(target *GitURL) error {
+	return func(target *GitURL) error {
+		used := g.withCredential(target).credentialID
+		err := operation(target)
+		if err == nil || !strings.HasPrefix(used, "host:") || !isCredentialError(err) {
+			return err
+		}
+
+		host := credentialHost(target.RepoURL)
+		g.credentialsMutex.Lock()
+		credential, ok := g.hostCredentials[host]
+		if ok && credential.fingerprint == used {
+			delete(g.hostCredentials, host)
+		}
+		g.credentialsMutex.Unlock()
+		if !ok || credential.fingerprint != used {
+			return err
+		}
+
+		g.evictHostAuthCache(host)
+		g.logEvent(context.Background(), slog.LevelWarn, "host credentials rejected, removed from the credential store",
+			append(logURLAttrs(target), slog.String("host", host), logErrorAttr(err))...)
+		return err
+	}
+}
+
+// evictHostAuthCache discards the cached authentication of every repository on a host
+func (g *GitProvider) evictHostAuthCache(host string) {
+	g.authCacheMutex.Lock()
+	defer g.authCacheMutex.Unlock()
+
+	for key := range g.authCache {
+		if _, rest, ok := strings.Cut(key, ":"); ok {
+			repoURL, _, _ := strings.Cut(rest, "#")
+			if credentialHost(repoURL) == host {
+				delete(g.authCache, key)
+			}
+		}
+	}
+}
+
+// evictAuthCache discards the cached authentication of a repository
+func (g *GitProvider) evictAuthCache(repoURL string) {
+	g.authCacheMutex.Lock()
+	defer g.authCacheMutex.Unlock()
+
+	for key := range g.authCache {
+		if _, rest, ok := strings.Cut(key, ":"); ok && (rest == repoURL || strings.HasPrefix(rest, repoURL+"#")) {
+			delete(g.authCache, key)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// credentials_test.go
+//
+// Runtime credential rotation tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestGitProvider_SetCredential tests that rotated credentials are used by loads and active watches
+func TestGitProvider_SetCredential(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+	backend := repo.httpBackend()
+
+	// The server accepts a single bearer token at a time, as after a rotation
+	var mu sync.Mutex
+	accepted := "Bearer old-token"
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ok := r.Header.Get("Authorization") == accepted
+		seen = append(seen, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	rotate := func(header string) {
+		mu.Lock()
+		accepted, seen = header, nil
+		mu.Unlock()
+	}
+	lastSeen := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(seen) == 0 {
+			return ""
+		}
+		return seen[len(seen)-1]
+	}
+
+	provider := newNoRetryProvider()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Mock server URLs bypass SSRF validation on purpose
+	newURL := func() *GitURL {
+		return &GitURL{
+			RepoURL:      server.URL + "/.git",
+			FilePath:     "config.json",
+			Reference:    "main",
+			AuthType:     "bearer",
+			AuthData:     map[string]string{"token": "old-token"},
+			PollInterval: 20 * time.Millisecond,
+		}
+	}
+	if _, err := provider.loadConfigFromRepo(ctx, newURL()); err != nil {
+		t.Fatalf("Load with the original token failed: %v", err)
+	}
+
+	configs := make(chan map[string]interface{}, 1)
+	provider.incrementWatchCount()
+	go provider.startWatching(ctx, newURL(), configs, nil)
+	select {
+	case <-configs:
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the initial configuration")
+	}
+
+	rotate("Bearer new-token")
+	if _, err := provider.loadConfigFromRepo(ctx, newURL()); err == nil {
+		t.Fatal("Expected the rotated-out token to be rejected")
+	}
+
+	if err := provider.setCredential(server.URL+"/.git", AuthMethodSpec{Type: "bearer", Token: "new-token"}); err != nil {
+		t.Fatalf("setCredential failed: %v", err)
+	}
+	if _, err := provider.loadConfigFromRepo(ctx, newURL()); err != nil {
+		t.Fatalf("Load after rotation failed: %v", err)
+	}
+	if header := lastSeen(); header != "Bearer new-token" {
+		t.Errorf("Expected the rotated token to be sent, got %q", header)
+	}
+
+	// The watch started with the old token picks up the next commit with the new one
+	repo.commit("bump", map[string]string{"config.json": `{"version": 2}`})
+	for {
+		select {
+		case config := <-configs:
+			if config["version"] == float64(2) {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the watch to deliver the change after rotation")
+		}
+	}
+}
+
+// TestGitProvider_SetCredentialValidation tests which credentials and repositories are accepted
+func TestGitProvider_SetCredentialValidation(t *testing.T) {
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	const repoURL = "https://github.com/org/configs.git"
+	if err := provider.SetCredential(repoURL, AuthMethodSpec{Type: "token", Token: "ghp_rotated"}); err != nil {
+		t.Fatalf("SetCredential failed: %v", err)
+	}
+
+	// Every URL of the repository authenticates with the registered token
+	gitURL, err := provider.parseGitURL("https://github.com/org/configs#app.json?auth=token:ghp_expired")
+	if err != nil {
+		t.Fatalf("parseGitURL failed: %v", err)
+	}
+	effective := provider.withCredential(gitURL)
+	if effective.AuthType != "token" || effective.AuthData["token"] != "ghp_rotated" {
+		t.Errorf("Expected the registered token, got %s %v", effective.AuthType, effective.AuthData)
+	}
+	if gitURL.AuthData["token"] != "ghp_expired" {
+		t.Error("Expected the parsed URL to be left unchanged")
+	}
+
+	if err := provider.RemoveCredential(repoURL); err != nil {
+		t.Fatalf("RemoveCredential failed: %v", err)
+	}
+	if effective := provider.withCredential(gitURL); effective.AuthData["token"] != "ghp_expired" {
+		t.Errorf("Expected the URL token after removal, got %v", effective.AuthData)
+	}
+
+	for name, tc := range map[string]struct {
+		repoURL string
+		spec    AuthMethodSpec
+		code    errors.ErrorCode
+	}{
+		"Missing token":    {repoURL, AuthMethodSpec{Type: "token"}, "ARGUS_INVALID_CONFIG"},
+		"Unsupported type": {repoURL, AuthMethodSpec{Type: "kerberos"}, "ARGUS_AUTH_ERROR"},
+		"Local repository": {"file:///srv/configs", AuthMethodSpec{Type: "token", Token: "t"}, "ARGUS_INVALID_CONFIG"},
+		"Private host":     {"https://127.0.0.1/org/configs.git", AuthMethodSpec{Type: "token", Token: "t"}, "ARGUS_SECURITY_ERROR"},
+	} {
+		if err := provider.SetCredential(tc.repoURL, tc.spec); !errors.HasCode(err, tc.code) {
+			t.Errorf("%s: expected %s, got %v", name, tc.code, err)
+		}
+	}
+}
+
+// TestGitProvider_SetHostCredential tests that bare URLs authenticate with the credentials of their host
+func TestGitProvider_SetHostCredential(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "api"}`})
+	backend := repo.httpBackend()
+
+	var mu sync.Mutex
+	accepted := "Bearer host-token"
+	var lastSeen string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastSeen = r.Header.Get("Authorization")
+		ok := lastSeen == accepted
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	seen := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastSeen
+	}
+
+	provider := newNoRetryProvider()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Mock server URLs bypass SSRF validation on purpose
+	bareURL := func() *GitURL {
+		return &GitURL{RepoURL: server.URL + "/.git", FilePath: "config.json", Reference: "main", AuthData: map[string]string{}}
+	}
+	if _, err := provider.loadConfigFromRepo(ctx, bareURL()); err == nil {
+		t.Fatal("Expected the bare URL to be rejected without host credentials")
+	}
+
+	if err := provider.setHostCredential("127.0.0.1", AuthMethodSpec{Type: "bearer", Token: "host-token"}); err != nil {
+		t.Fatalf("setHostCredential failed: %v", err)
+	}
+	if _, err := provider.loadConfigFromRepo(ctx, bareURL()); err != nil {
+		t.Fatalf("Load with host credentials failed: %v", err)
+	}
+	if header := seen(); header != "Bearer host-token" {
+		t.Errorf("Expected the host token to be sent, got %q", header)
+	}
+
+	// Authentication in the URL overrides the host credentials
+	withAuth := bareURL()
+	withAuth.AuthType, withAuth.AuthData = "bearer", map[string]string{"token": "url-token"}
+	if _, err := provider.loadConfigFromRepo(ctx, withAuth); err == nil || seen() != "Bearer url-token" {
+		t.Errorf("Expected the URL token to be sent and rejected, got %q (err=%v)", seen(), err)
+	}
+
+	// Rejected host credentials are removed from the store
+	mu.Lock()
+	accepted = "Bearer rotated-token"
+	mu.Unlock()
+	if _, err := provider.loadConfigFromRepo(ctx, bareURL()); err == nil {
+		t.Fatal("Expected the rotated-out host token to be rejected")
+	}
+	if effective := provider.withCredential(bareURL()); effective.AuthType != "" {
+		t.Errorf("Expected the rejected host credentials to be removed, got %s", effective.AuthType)
+	}
+	if _, err := provider.loadConfigFromRepo(ctx, bareURL()); err == nil || seen() != "" {
+		t.Errorf("Expected an unauthenticated request after removal, got %q (err=%v)", seen(), err)
+	}
+}
+
+// TestGitProvider_SetHostCredentialValidation tests host matching and which hosts are accepted
+func TestGitProvider_SetHostCredentialValidation(t *testing.T) {
+	provider := newNoRetryProvider()
+	if err := provider.SetHostCredential("GitLab.Internal", AuthMethodSpec{Type: "token", Token: "glpat_host"}); err != nil {
+		t.Fatalf("SetHostCredential failed: %v", err)
+	}
+	if err := provider.SetCredential("https://gitlab.internal/team/pinned.git", AuthMethodSpec{Type: "token", Token: "glpat_repo"}); err != nil {
+		t.Fatalf("SetCredential failed: %v", err)
+	}
+
+	for repoURL, want := range map[string]string{
+		"https://gitlab.internal/team/configs.git":       "glpat_host",
+		"https://gitlab.internal:8443/team/configs.git":  "glpat_host",
+		"https://gitlab.internal/team/pinned.git":        "glpat_repo",
+		"https://gitlab.internal/team/configs.git?auth=": "glpat_host",
+		"https://github.com/org/configs.git":             "",
+	} {
+		gitURL, err := provider.parseGitURLWith(repoURL, false)
+		if err != nil {
+			t.Fatalf("parseGitURL(%s) failed: %v", repoURL, err)
+		}
+		if token := provider.withCredential(gitURL).AuthData["token"]; token != want {
+			t.Errorf("Expected token %q for %s, got %q", want, repoURL, token)
+		}
+	}
+
+	gitURL, err := provider.parseGitURL("https://gitlab.internal/team/configs.git#app.json?auth=token:glpat_url")
+	if err != nil {
+		t.Fatalf("parseGitURL failed: %v", err)
+	}
+	if token := provider.withCredential(gitURL).AuthData["token"]; token != "glpat_url" {
+		t.Errorf("Expected the URL token to take precedence, got %q", token)
+	}
+	if host := credentialHost("git@GitLab.internal:team/configs.git"); host != "gitlab.internal" {
+		t.Errorf("Expected gitlab.internal as the scp-like URL host, got %q", host)
+	}
+
+	if err := provider.RemoveHostCredential("gitlab.internal"); err != nil {
+		t.Fatalf("RemoveHostCredential failed: %v", err)
+	}
+	if effective := provider.withCredential(gitURL); effective.AuthData["token"] != "glpat_url" {
+		t.Errorf("Expected the URL token after removal, got %v", effective.AuthData)
+	}
+
+	for host, code := range map[string]errors.ErrorCode{
+		"":                         "ARGUS_INVALID_CONFIG",
+		"https://gitlab.internal":  "ARGUS_INVALID_CONFIG",
+		"gitlab.internal:8443":     "ARGUS_INVALID_CONFIG",
+		"deploy@gitlab.internal":   "ARGUS_INVALID_CONFIG",
+		"127.0.0.1":                "ARGUS_SECURITY_ERROR",
+		"metadata.google.internal": "ARGUS_SECURITY_ERROR",
+	} {
+		if err := provider.SetHostCredential(host, AuthMethodSpec{Type: "token", Token: "t"}); !errors.HasCode(err, code) {
+			t.Errorf("Expected %s for host %q, got %v", code, host, err)
+		}
+	}
+	if err := provider.SetHostCredential("gitlab.internal", AuthMethodSpec{Type: "token"}); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for a spec without token, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+// diff.go: Structured configuration diffs between Git references
+//
+// Diff loads the same configuration file at two references and reports the
+// keys that were added, removed or changed, flattened to dotted paths.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/agilira/go-errors"
+)
+
+// ConfigChange describes a single configuration key that differs between two references.
+// OldValue is nil for added keys and NewValue is nil for removed keys.
+type ConfigChange struct {
+	Key      string      // Flattened dotted key path, e.g. "database.port"
+	OldValue interface{} // Value at the from reference
+	NewValue interface{} // Value at the to reference
+}
+
+// ConfigDiff is the structured difference of a configuration file between two references.
+// Changes are sorted by key. When the file does not exist at one of the references,
+// its commit is empty and every key is reported as added or removed; Diff fails with
+// ARGUS_CONFIG_NOT_FOUND when it exists at neither.
+type ConfigDiff struct {
+	FilePath   string         // Configuration file that was compared
+	FromRef    string         // Reference the diff starts from
+	ToRef      string         // Reference the diff ends at
+	FromCommit string         // Commit the from configuration was read from
+	ToCommit   string         // Commit the to configuration was read from
+	Added      []ConfigChange // Keys present only at ToRef
+	Removed    []ConfigChange // Keys present only at FromRef
+	Changed    []ConfigChange // Keys whose value differs
+}
+
+// HasChanges reports whether the configuration differs between the two references
+func (d *ConfigDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// Diff loads the configuration file of configURL at fromRef and toRef and returns the
+// keys that change between them. Any reference given in configURL is ignored.
+//
+// Example:
+//
+//	diff, err := provider.Diff(ctx, "https://github.com/org/configs.git#app.json", "main", "feature-x")
+//	for _, change := range diff.Changed {
+//	    fmt.Printf("%s: %v -> %v\n", change.Key, change.OldValue, change.NewValue)
+//	}
+func (g *GitProvider) Diff(ctx context.Context, configURL, fromRef, toRef string) (*ConfigDiff, error) {
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	if fromRef == "" || toRef == "" {
+		return nil, errors.New("ARGUS_INVALID_CONFIG", "diff requires both a from and a to reference")
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err := g.parseRequestURL(ctx, configURL, true)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	return g.diffRefs(ctx, gitURL, fromRef, toRef)
+}
+
+// diffRefs loads the configuration at both references and diffs the results
+func (g *GitProvider) diffRefs(ctx context.Context, gitURL *GitURL, fromRef, toRef string) (*ConfigDiff, error) {
+	from, err := g.loadAtRef(ctx, gitURL, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	to, err := g.loadAtRef(ctx, gitURL, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == nil && to == nil {
+		return nil, errors.Wrap(configNotFoundError(gitURL.FilePath), "ARGUS_CONFIG_NOT_FOUND",
+			fmt.Sprintf("configuration file %s does not exist at %s or %s", gitURL.FilePath, fromRef, toRef))
+	}
+
+	diff := &ConfigDiff{
+		FilePath: gitURL.FilePath,
+		FromRef:  fromRef,
+		ToRef:    toRef,
+	}
+
+	var fromConfig, toConfig map[string]interface{}
+	if from != nil {
+		diff.FromCommit = from.CommitHash
+		fromConfig = from.Config
+	}
+	if to != nil {
+		diff.ToCommit = to.CommitHash
+		toConfig = to.Config
+	}
+
+	diff.Added, diff.Removed, diff.Changed = diffConfigs(fromConfig, toConfig)
+	return diff, nil
+}
+
+// loadAtRef loads the configuration at a reference, returning nil if the file does not exist there
+func (g *GitProvider) loadAtRef(ctx context.Context, gitURL *GitURL, ref string) (*LoadResult, error) {
+	refURL := *gitURL
+	refURL.FallbackRefs = nil
+	if err := refURL.setReference(ref, false); err != nil {
+		return nil, err
+	}
+
+	result, err := g.loadConfigFromRepo(ctx, &refURL)
+	if os.IsNotExist(errors.RootCause(err)) {
+		return nil, nil
+	}
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// diffConfigs compares two configurations by their flattened keys
+func diffConfigs(from, to map[string]interface{}) (added, removed, changed []ConfigChange) {
+	fromFlat := make(map[string]interface{})
+	toFlat := make(map[string]interface{})
+	flattenConfig("", from, fromFlat)
+	flattenConfig("", to, toFlat)
+
+	for key, oldValue := range fromFlat {
+		newValue, ok := toFlat[key]
+		switch {
+		case !ok:
+			removed = append(removed, ConfigChange{Key: key, OldValue: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changed = append(changed, ConfigChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, newValue := range toFlat {
+		if _, ok := fromFlat[key]; !ok {
+			added = append(added, ConfigChange{Key: key, NewValue: newValue})
+		}
+	}
+
+	sortChanges(added)
+	sortChanges(removed)
+	sortChanges(changed)
+	return added, removed, changed
+}
+
+// flattenConfig writes every leaf of config into flat under its dotted key path.
+// Arrays and empty maps are leaves, so a reordered list is reported as one change.
+func flattenConfig(prefix string, config map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range config {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenConfig(path, nested, flat)
+			continue
+		}
+		flat[path] = value
+	}
+}
+
+// sortChanges orders changes by key
+func sortChanges(changes []ConfigChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+}
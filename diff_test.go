@@ -0,0 +1,119 @@
+// diff_test.go
+//
+// Configuration diff tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// changeKeys returns the keys of a list of changes
+func changeKeys(changes []ConfigChange) string {
+	keys := make([]string, 0, len(changes))
+	for _, change := range changes {
+		keys = append(keys, change.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+// TestGitProvider_Diff tests diffing two commits of testdata/config.json
+func TestGitProvider_Diff(t *testing.T) {
+	original, err := os.ReadFile("testdata/config.json")
+	if err != nil {
+		t.Fatalf("Failed to read testdata: %v", err)
+	}
+
+	repo := newTestRepository(t, map[string]string{"testdata/config.json": string(original)})
+	releaseCommit := repo.commit("release", nil)
+	repo.branch("release")
+	modified := strings.NewReplacer(
+		`"port": 5432`, `"port": 6432`,
+		`"level": "info",`, `"level": "debug",`,
+		`    "monitoring": true`, `    "monitoring": true,
+    "tracing": {"enabled": true}`,
+		`    "ssl": true`, `    "pool": 20`,
+	).Replace(string(original))
+	mainCommit := repo.commit("tune config", map[string]string{"testdata/config.json": modified})
+
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	diff, err := provider.diffRefs(ctx, repo.gitURL("testdata/config.json", ""), "release", "main")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if diff.FromCommit != releaseCommit || diff.ToCommit != mainCommit {
+		t.Errorf("Unexpected commits: from=%s to=%s", diff.FromCommit, diff.ToCommit)
+	}
+	if got := changeKeys(diff.Added); got != "database.pool,features.tracing.enabled" {
+		t.Errorf("Unexpected added keys: %s", got)
+	}
+	if got := changeKeys(diff.Removed); got != "database.ssl" {
+		t.Errorf("Unexpected removed keys: %s", got)
+	}
+	if got := changeKeys(diff.Changed); got != "database.port,logging.level" {
+		t.Errorf("Unexpected changed keys: %s", got)
+	}
+	if diff.Changed[0].OldValue != float64(5432) || diff.Changed[0].NewValue != float64(6432) {
+		t.Errorf("Unexpected port change: %+v", diff.Changed[0])
+	}
+	if diff.Removed[0].NewValue != nil || diff.Added[0].OldValue != nil {
+		t.Error("Expected nil values on the missing side of added/removed keys")
+	}
+
+	t.Run("Identical refs", func(t *testing.T) {
+		diff, err := provider.diffRefs(ctx, repo.gitURL("testdata/config.json", ""), "main", "main")
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		if diff.HasChanges() {
+			t.Errorf("Expected no changes, got %+v", diff)
+		}
+	})
+
+	t.Run("File missing in one ref", func(t *testing.T) {
+		repo.commit("add service config", map[string]string{"service.yaml": "name: api\nreplicas: 3\n"})
+
+		diff, err := provider.diffRefs(ctx, repo.gitURL("service.yaml", ""), "release", "main")
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		if diff.FromCommit != "" || changeKeys(diff.Added) != "name,replicas" || len(diff.Removed) != 0 {
+			t.Errorf("Expected all keys added, got %+v", diff)
+		}
+
+		diff, err = provider.diffRefs(ctx, repo.gitURL("service.yaml", ""), "main", "release")
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		if diff.ToCommit != "" || changeKeys(diff.Removed) != "name,replicas" || len(diff.Added) != 0 {
+			t.Errorf("Expected all keys removed, got %+v", diff)
+		}
+
+		if _, err := provider.diffRefs(ctx, repo.gitURL("missing.json", ""), "release", "main"); !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+			t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND when the file exists in neither ref, got %v", err)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		if _, err := provider.Diff(ctx, "https://github.com/org/configs.git#app.json", "", "main"); err == nil {
+			t.Error("Expected error for empty from reference")
+		}
+		if _, err := provider.Diff(ctx, "not-a-git-url", "main", "release"); err == nil {
+			t.Error("Expected error for invalid URL")
+		}
+	})
+}
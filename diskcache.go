@@ -0,0 +1,189 @@
+// diskcache.go: On-disk persistence of the configuration cache
+//
+// With WithDiskCache, configurations cached after a clone are also written to a
+// directory, and new providers hydrate their cache from it, so process restarts
+// start warm instead of re-cloning every repository.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// diskCacheVersion is the version of the on-disk entry layout; other versions are discarded
+const diskCacheVersion = 1
+
+// diskCacheEntry is the serialized form of a cached configuration.
+// The raw file content is stored rather than the decoded map, so hydrated
+// configurations are decoded exactly as a fresh load would decode them.
+type diskCacheEntry struct {
+	Version    int       `json:"version"`
+	Key        string    `json:"key"`
+	FilePath   string    `json:"file_path"`
+	Format     Format    `json:"format,omitempty"` // Explicit format overriding the file extension
+	CommitHash string    `json:"commit_hash"`
+	CachedAt   time.Time `json:"cached_at"`
+	Pinned     bool      `json:"pinned"`
+	Trailers   Trailers  `json:"trailers,omitempty"`
+	Content    []byte    `json:"content"`
+	Checksum   string    `json:"checksum"` // Hex SHA-256 of Content
+}
+
+// diskCacheFileName returns the file name of the entry for a cache key
+func diskCacheFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// contentChecksum returns the hex SHA-256 of raw configuration content
+func contentChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// persistCacheEntry writes a freshly loaded configuration to the disk cache.
+// Persistence is best-effort: a failed write only costs a clone after the next restart.
+func (g *GitProvider) persistCacheEntry(gitURL *GitURL, commitHash string, result *LoadResult) {
+	// Entries record a single commit, which for ancestors is not the one they were read from
+	if g.options.diskCacheDir == "" || result.content == nil || isRevisionExpression(gitURL.Reference) {
+		return
+	}
+
+	key := g.configCache.getCacheKey(gitURL, commitHash)
+	data, err := json.Marshal(&diskCacheEntry{
+		Version:    diskCacheVersion,
+		Key:        key,
+		FilePath:   gitURL.FilePath,
+		Format:     gitURL.Format,
+		CommitHash: commitHash,
+		CachedAt:   time.Now(),
+		Pinned:     isCommitHash(revisionBase(gitURL.Reference)),
+		Trailers:   result.Trailers,
+		Content:    result.content,
+		Checksum:   contentChecksum(result.content),
+	})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so readers never observe a partial entry
+	tempFile, err := os.CreateTemp(g.options.diskCacheDir, ".entry-*")
+	if err != nil {
+		return
+	}
+	tempName := tempFile.Name()
+
+	_, writeErr := tempFile.Write(data)
+	closeErr := tempFile.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(tempName)
+		return
+	}
+
+	if err := os.Rename(tempName, filepath.Join(g.options.diskCacheDir, diskCacheFileName(key))); err != nil {
+		_ = os.Remove(tempName)
+	}
+}
+
+// hydrateDiskCache loads the persisted entries into the configuration cache.
+// Entries that are corrupt, expired or no longer decodable are removed from disk;
+// when there are more valid entries than the cache holds, the newest are kept.
+func (g *GitProvider) hydrateDiskCache() error {
+	dirEntries, err := os.ReadDir(g.options.diskCacheDir)
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_IO_ERROR",
+			fmt.Sprintf("failed to read disk cache directory: %s", g.options.diskCacheDir))
+	}
+
+	var entries []*diskCacheEntry
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if !dirEntry.Type().IsRegular() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		path := filepath.Join(g.options.diskCacheDir, name)
+		entry, ok := g.readDiskCacheEntry(path)
+		if !ok || entry.Key == "" || diskCacheFileName(entry.Key) != name {
+			_ = os.Remove(path)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CachedAt.After(entries[j].CachedAt)
+	})
+
+	c := g.configCache
+	for _, entry := range entries {
+		if len(c.entries) >= c.maxSize {
+			break
+		}
+
+		config, err := g.parseConfigFileAs(entry.FilePath, entry.Format, entry.Content)
+		if err != nil {
+			_ = os.Remove(filepath.Join(g.options.diskCacheDir, diskCacheFileName(entry.Key)))
+			continue
+		}
+
+		c.mutex.Lock()
+		c.entries[entry.Key] = &configCacheEntry{
+			Config:      config,
+			Format:      resultFormat(entry.FilePath, entry.Format),
+			Size:        len(entry.Content),
+			CommitHash:  entry.CommitHash,
+			Trailers:    entry.Trailers,
+			CachedAt:    entry.CachedAt,
+			AccessCount: 1,
+			Pinned:      entry.Pinned,
+		}
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// readDiskCacheEntry reads and verifies a persisted entry, reporting whether it is usable
+func (g *GitProvider) readDiskCacheEntry(path string) (*diskCacheEntry, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > 2*maxConfigFileSize {
+		return nil, false
+	}
+
+	// #nosec G304 - Path is a regular file listed from the configured cache directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	// SECURITY: Only trust content that matches its checksum
+	if entry.Version != diskCacheVersion || contentChecksum(entry.Content) != entry.Checksum {
+		return nil, false
+	}
+
+	if !entry.Pinned && time.Since(entry.CachedAt) > g.configCache.ttl {
+		return nil, false
+	}
+
+	return &entry, true
+}
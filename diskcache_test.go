@@ -0,0 +1,147 @@
+// diskcache_test.go
+//
+// Disk cache persistence tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// rewriteDiskCacheEntries applies modify to every persisted entry in dir
+func rewriteDiskCacheEntries(t *testing.T, dir string, modify func(entry *diskCacheEntry)) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected persisted entries in %s: %v", dir, err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		var entry diskCacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Fatalf("Failed to decode %s: %v", file, err)
+		}
+		modify(&entry)
+		if data, err = json.Marshal(&entry); err != nil {
+			t.Fatalf("Failed to encode %s: %v", file, err)
+		}
+		if err := os.WriteFile(file, data, 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+}
+
+// TestWithDiskCache tests that a second provider reads persisted entries without cloning
+func TestWithDiskCache(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.yaml": "service: api\nreplicas: 3\n"})
+	gitURL := repo.gitURL("config.yaml", "main")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// newPrimedCache persists one entry through a first provider and returns its directory
+	newPrimedCache := func(t *testing.T) string {
+		dir := filepath.Join(t.TempDir(), "cache")
+		provider, err := NewProvider(WithDiskCache(dir))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if _, err := provider.loadConfigFromRepo(ctx, gitURL); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return dir
+	}
+
+	t.Run("Second instance starts warm", func(t *testing.T) {
+		dir := newPrimedCache(t)
+
+		info, err := os.Stat(dir)
+		if err != nil || info.Mode().Perm() != 0o700 {
+			t.Errorf("Expected cache directory with 0700 permissions, got %v, %v", info, err)
+		}
+
+		provider, err := NewProvider(WithDiskCache(dir))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		result, err := provider.loadConfigFromRepo(ctx, gitURL)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		// YAML integers stay int: entries are decoded from the persisted raw content
+		if result.Config["service"] != "api" || result.Config["replicas"] != 3 || result.Format != FormatYAML {
+			t.Errorf("Unexpected hydrated result: %+v", result)
+		}
+		metrics := provider.Metrics()
+		if metrics.CacheHits != 1 || metrics.TempDirsCreated != 0 {
+			t.Errorf("Expected a cache hit without cloning, got %d hits / %d clones", metrics.CacheHits, metrics.TempDirsCreated)
+		}
+	})
+
+	t.Run("Corrupt entries are discarded", func(t *testing.T) {
+		dir := newPrimedCache(t)
+		rewriteDiskCacheEntries(t, dir, func(entry *diskCacheEntry) {
+			entry.Content = []byte("service: tampered\n")
+		})
+
+		provider, err := NewProvider(WithDiskCache(dir))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+			t.Errorf("Expected corrupt entries to be removed, found %v", files)
+		}
+
+		result, err := provider.loadConfigFromRepo(ctx, gitURL)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if result.Config["service"] != "api" || provider.Metrics().TempDirsCreated != 1 {
+			t.Errorf("Expected a fresh clone, got %+v", result)
+		}
+	})
+
+	t.Run("Expired entries are discarded", func(t *testing.T) {
+		dir := newPrimedCache(t)
+		rewriteDiskCacheEntries(t, dir, func(entry *diskCacheEntry) {
+			entry.CachedAt = time.Now().Add(-time.Hour)
+		})
+
+		provider, err := NewProvider(WithDiskCache(dir))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if entries := provider.Metrics().ConfigCache.Entries; entries != 0 {
+			t.Errorf("Expected no hydrated entries past the TTL, got %d", entries)
+		}
+	})
+
+	t.Run("Invalid directory", func(t *testing.T) {
+		if _, err := NewProvider(WithDiskCache("")); err == nil {
+			t.Error("Expected error for empty directory")
+		}
+
+		file := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(file, nil, 0o600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if _, err := NewProvider(WithDiskCache(file)); err == nil {
+			t.Error("Expected error when the directory is a file")
+		}
+	})
+}
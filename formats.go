@@ -0,0 +1,588 @@
+// formats.go: Configuration format registry for the Git provider
+//
+// Configuration files are decoded by the decoder registered for their file
+// extension. JSON, YAML and TOML are registered by default; RegisterFormat adds
+// new formats or replaces a built-in decoder. Files with an additional .gz
+// suffix are gunzipped and decoded by the decoder of the inner extension.
+// Content is decoded as UTF-8, without the byte order mark some Windows editors
+// write; UTF-16 files with a byte order mark are transcoded to UTF-8 first.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/agilira/go-errors"
+	toml "github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeFunc decodes raw configuration file content into a configuration map.
+type DecodeFunc func(content []byte) (map[string]interface{}, error)
+
+// compressedExtension is the suffix of gzip-compressed configuration files, e.g. app.json.gz
+const compressedExtension = ".gz"
+
+// formatDecoder associates a decoder with the format reported in LoadResult
+type formatDecoder struct {
+	format Format
+	decode DecodeFunc
+	// builtin is the option-aware decoder of a built-in format; nil for registered decoders
+	builtin func(content []byte, opts *providerOptions) (map[string]interface{}, error)
+}
+
+// formatRegistry maps lowercase file extensions (with leading dot) to decoders
+var formatRegistry = struct {
+	sync.RWMutex
+	decoders map[string]formatDecoder
+}{
+	decoders: map[string]formatDecoder{
+		".json": {format: FormatJSON, builtin: decodeJSON},
+		".yaml": {format: FormatYAML, builtin: decodeYAML},
+		".yml":  {format: FormatYAML, builtin: decodeYAML},
+		".toml": {format: FormatTOML, builtin: decodeTOML},
+	},
+}
+
+// RegisterFormat registers a decoder for configuration files with the given extension.
+//
+// The extension may be given with or without the leading dot and is matched
+// case-insensitively. Registering an extension that already has a decoder
+// replaces it, which allows e.g. a stricter YAML decoder to be installed for
+// ".yaml" and ".yml". Registered extensions are accepted by configuration path
+// validation. The registry is shared by all providers and safe for concurrent use.
+// Registered decoders are used as-is regardless of decoding options such as WithStrictDecoding.
+//
+// Example:
+//
+//	err := git.RegisterFormat("xml", func(content []byte) (map[string]interface{}, error) {
+//	    return decodeXMLConfig(content)
+//	})
+func RegisterFormat(ext string, decode DecodeFunc) error {
+	if decode == nil {
+		return errors.New("ARGUS_INVALID_CONFIG", "format decoder cannot be nil")
+	}
+
+	normalized, err := normalizeFormatExtension(ext)
+	if err != nil {
+		return err
+	}
+
+	formatRegistry.Lock()
+	defer formatRegistry.Unlock()
+
+	// Overriding a built-in decoder keeps the built-in format name
+	format := Format(strings.TrimPrefix(normalized, "."))
+	if existing, ok := formatRegistry.decoders[normalized]; ok {
+		format = existing.format
+	}
+
+	formatRegistry.decoders[normalized] = formatDecoder{format: format, decode: decode}
+	return nil
+}
+
+// normalizeFormatExtension lowercases an extension and ensures a single leading dot
+func normalizeFormatExtension(ext string) (string, error) {
+	normalized := "." + strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+
+	if len(normalized) < 2 {
+		return "", errors.New("ARGUS_INVALID_CONFIG", "format extension cannot be empty")
+	}
+	if normalized == compressedExtension {
+		return "", errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("format extension %s is reserved for compressed files", compressedExtension))
+	}
+
+	// Extensions are matched with filepath.Ext, so they must be a single simple suffix
+	for _, r := range normalized[1:] {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_') {
+			return "", errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("invalid format extension: %q", ext))
+		}
+	}
+
+	return normalized, nil
+}
+
+// lookupFormat returns the registered decoder for a file path's extension.
+// Compressed files use the decoder of their inner extension.
+func lookupFormat(filePath string) (formatDecoder, bool) {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	innerPath, _ := uncompressedPath(filePath)
+	decoder, ok := formatRegistry.decoders[strings.ToLower(filepath.Ext(innerPath))]
+	return decoder, ok
+}
+
+// lookupFormatName returns the registered decoder for a format named by its extension,
+// e.g. "yaml", "yml" or ".json", as given in an explicit format parameter
+func lookupFormatName(name string) (formatDecoder, bool) {
+	normalized, err := normalizeFormatExtension(name)
+	if err != nil {
+		return formatDecoder{}, false
+	}
+
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	decoder, ok := formatRegistry.decoders[normalized]
+	return decoder, ok
+}
+
+// parseFormatName resolves an explicit format name to the format of its registered decoder
+func parseFormatName(name string) (Format, error) {
+	decoder, ok := lookupFormatName(name)
+	if !ok {
+		return "", errors.New("ARGUS_UNSUPPORTED_FORMAT",
+			fmt.Sprintf("unsupported configuration format: %s (supported: %s)", name, strings.Join(registeredFormats(), ", ")))
+	}
+	return decoder.format, nil
+}
+
+// uncompressedPath returns filePath without its .gz suffix and reports whether it had one
+func uncompressedPath(filePath string) (string, bool) {
+	n := len(filePath) - len(compressedExtension)
+	if n > 0 && strings.EqualFold(filePath[n:], compressedExtension) {
+		return filePath[:n], true
+	}
+	return filePath, false
+}
+
+// yamlNodeSize is the size of a YAML node with its aliases expanded
+type yamlNodeSize struct {
+	nodes int // Nodes in the expanded subtree
+	depth int // Nesting depth of mappings and sequences
+}
+
+// checkYAMLLimits returns the expanded node count and nesting depth of a YAML node,
+// failing once either exceeds its limit. Sizes are memoized per node, so shared
+// anchors are measured once.
+func checkYAMLLimits(node *yaml.Node, sizes map[*yaml.Node]yamlNodeSize) (int, int, error) {
+	if size, ok := sizes[node]; ok {
+		if size.nodes < 0 {
+			return 0, 0, errors.New("ARGUS_SECURITY_ERROR", "recursive YAML alias")
+		}
+		return size.nodes, size.depth, nil
+	}
+	sizes[node] = yamlNodeSize{nodes: -1}
+
+	nodes, depth := 1, 0
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		aliasNodes, aliasDepth, err := checkYAMLLimits(node.Alias, sizes)
+		if err != nil {
+			return 0, 0, err
+		}
+		nodes, depth = aliasNodes, aliasDepth
+	}
+	for _, child := range node.Content {
+		childNodes, childDepth, err := checkYAMLLimits(child, sizes)
+		if err != nil {
+			return 0, 0, err
+		}
+		nodes += childNodes
+		depth = max(depth, childDepth)
+	}
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		depth++
+	}
+
+	if nodes > maxYAMLExpandedNodes {
+		return 0, 0, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("YAML aliases expand to too many nodes (max %d)", maxYAMLExpandedNodes))
+	}
+	if depth > maxConfigDepth {
+		return 0, 0, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration nested too deeply (max depth %d)", maxConfigDepth))
+	}
+
+	sizes[node] = yamlNodeSize{nodes: nodes, depth: depth}
+	return nodes, depth, nil
+}
+
+// checkConfigDepth fails when maps and lists in a decoded value nest deeper than maxConfigDepth
+func checkConfigDepth(value interface{}, depth int) error {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, []map[string]interface{}:
+	default:
+		return nil
+	}
+	if depth++; depth > maxConfigDepth {
+		return errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration nested too deeply (max depth %d)", maxConfigDepth))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			if err := checkConfigDepth(child, depth); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if err := checkConfigDepth(child, depth); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for _, child := range v {
+			if err := checkConfigDepth(child, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decompressConfig gunzips compressed configuration content.
+//
+// SECURITY: A small gzip stream can expand to gigabytes, so the decompressed
+// content is bounded by maxConfigFileSize like an uncompressed file.
+func decompressConfig(content []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "failed to decompress gzip configuration")
+	}
+	defer func() { _ = reader.Close() }()
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxConfigFileSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "failed to decompress gzip configuration")
+	}
+	if len(decompressed) > maxConfigFileSize {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("decompressed configuration file too large (max %d bytes)", maxConfigFileSize))
+	}
+
+	return decompressed, nil
+}
+
+// Byte order marks of the text encodings recognized in configuration files
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// decodeTextEncoding returns configuration content as UTF-8 without a byte order mark,
+// transcoding UTF-16 content that starts with one. Content without a byte order mark
+// is returned unchanged.
+func decodeTextEncoding(content []byte) ([]byte, error) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return content[len(utf8BOM):], nil
+	case bytes.HasPrefix(content, utf16LEBOM):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(content, utf16BEBOM):
+		order = binary.BigEndian
+	default:
+		return content, nil
+	}
+
+	content = content[2:]
+	if len(content)%2 != 0 {
+		return nil, errors.New("ARGUS_PARSE_ERROR", "invalid UTF-16 configuration: odd number of bytes")
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[2*i:])
+	}
+
+	// SECURITY: Transcoding may grow the content by half, so the result is bounded again
+	decoded := make([]byte, 0, len(content))
+	for _, r := range utf16.Decode(units) {
+		decoded = utf8.AppendRune(decoded, r)
+		if len(decoded) > maxConfigFileSize {
+			return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+				fmt.Sprintf("transcoded configuration file too large (max %d bytes)", maxConfigFileSize))
+		}
+	}
+	return decoded, nil
+}
+
+// Binary content detection: like Git, only the start of a file is inspected
+const (
+	binarySniffLength      = 8000
+	maxInvalidUTF8Fraction = 0.1
+)
+
+// checkTextContent rejects content that looks binary, i.e. contains a NUL byte or
+// more than maxInvalidUTF8Fraction of invalid UTF-8 bytes within its first
+// binarySniffLength bytes, so text decoders never see images, archives or compiled
+// files committed under a configuration file name.
+func checkTextContent(content []byte) error {
+	sample := content
+	if len(sample) > binarySniffLength {
+		sample = sample[:binarySniffLength]
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return errors.New("ARGUS_PARSE_ERROR", "configuration file contains binary data (NUL byte)")
+	}
+
+	invalid := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A multi-byte character cut off by the sample is not invalid
+			if len(sample) < len(content) && !utf8.FullRune(sample[i:]) {
+				break
+			}
+			invalid++
+		}
+		i += size
+	}
+	if float64(invalid) > maxInvalidUTF8Fraction*float64(len(sample)) {
+		return errors.New("ARGUS_PARSE_ERROR",
+			fmt.Sprintf("configuration file contains binary data (%d of %d bytes are not valid UTF-8)", invalid, len(sample)))
+	}
+	return nil
+}
+
+// registeredExtensions returns the sorted list of extensions with a registered decoder
+func registeredExtensions() []string {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	extensions := make([]string, 0, len(formatRegistry.decoders))
+	for ext := range formatRegistry.decoders {
+		extensions = append(extensions, ext)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+// registeredFormats returns the sorted list of format names with a registered decoder
+func registeredFormats() []string {
+	extensions := registeredExtensions()
+	formats := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		formats = append(formats, strings.TrimPrefix(ext, "."))
+	}
+	return formats
+}
+
+// decodeWith decodes content with the built-in decoder honoring opts, or the registered decoder
+func (d formatDecoder) decodeWith(content []byte, opts *providerOptions) (map[string]interface{}, error) {
+	if d.builtin != nil {
+		return d.builtin(content, opts)
+	}
+	return d.decode(content)
+}
+
+// decodeJSON is the built-in JSON decoder.
+// Strict mode rejects duplicate object keys at any depth; trailing data is always rejected.
+func decodeJSON(content []byte, opts *providerOptions) (map[string]interface{}, error) {
+	if opts.strictDecoding {
+		if err := checkJSONDuplicateKeys(json.NewDecoder(bytes.NewReader(content))); err != nil {
+			return nil, err
+		}
+	}
+
+	var config map[string]interface{}
+	var root interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if opts.jsonUseNumber {
+		dec.UseNumber()
+	}
+
+	// Object roots decode directly; anything else is decoded generically for rootToConfig
+	target := interface{}(&config)
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] != '{' {
+		target = &root
+	}
+	if err := dec.Decode(target); err != nil {
+		return nil, err
+	}
+
+	// Match json.Unmarshal, which rejects anything after the top-level value
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value at offset %d", dec.InputOffset())
+	}
+
+	if target == &root {
+		return rootToConfig(root, FormatJSON, opts)
+	}
+	return config, nil
+}
+
+// checkJSONDuplicateKeys walks one JSON value and reports the first duplicate object key
+func checkJSONDuplicateKeys(dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		seen := make(map[string]bool)
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			if seen[key] {
+				return fmt.Errorf("duplicate key %q at offset %d", key, dec.InputOffset())
+			}
+			seen[key] = true
+			if err := checkJSONDuplicateKeys(dec); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for dec.More() {
+			if err := checkJSONDuplicateKeys(dec); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// decodeYAML is the built-in YAML decoder.
+// Duplicate mapping keys are always rejected by yaml.v3. Files with several
+// documents are rejected rather than truncated to the first one, unless a
+// documents key collects them; empty documents, such as after a trailing "---",
+// are ignored.
+func decodeYAML(content []byte, opts *providerOptions) (map[string]interface{}, error) {
+	documents, err := decodeYAMLDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(documents) > 1 {
+		return decodeYAMLDocumentList(documents, opts)
+	}
+
+	// Empty documents have no content node
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	// SECURITY: Reject alias expansion bombs and deep nesting before decoding expands them
+	root := documents[0]
+	if _, _, err := checkYAMLLimits(root, make(map[*yaml.Node]yamlNodeSize)); err != nil {
+		return nil, err
+	}
+	if opts.includes {
+		rewriteYAMLIncludes(root)
+	}
+
+	if root.Kind == yaml.MappingNode || (root.Kind == yaml.ScalarNode && root.Tag == "!!null") {
+		var config map[string]interface{}
+		err := root.Decode(&config)
+		return config, err
+	}
+
+	var value interface{}
+	if err := root.Decode(&value); err != nil {
+		return nil, err
+	}
+	return rootToConfig(value, FormatYAML, opts)
+}
+
+// decodeYAMLDocuments returns the root nodes of the non-empty documents in content
+func decodeYAMLDocuments(content []byte) ([]*yaml.Node, error) {
+	var documents []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := dec.Decode(&document); err != nil {
+			if err == io.EOF {
+				return documents, nil
+			}
+			return nil, err
+		}
+		if len(document.Content) == 0 || isEmptyYAMLNode(document.Content[0]) {
+			continue
+		}
+		documents = append(documents, document.Content[0])
+	}
+}
+
+// isEmptyYAMLNode reports whether node is an implicit null, as decoded from a document without content
+func isEmptyYAMLNode(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null" && node.Value == "" && node.Anchor == ""
+}
+
+// decodeYAMLDocumentList decodes several YAML documents into a list under the
+// documents key, or reports them as an ARGUS_PARSE_ERROR when none is configured
+func decodeYAMLDocumentList(documents []*yaml.Node, opts *providerOptions) (map[string]interface{}, error) {
+	if opts.yamlDocumentsKey == "" {
+		return nil, errors.New("ARGUS_PARSE_ERROR",
+			fmt.Sprintf("failed to parse YAML configuration: file contains %d documents, only single-document files "+
+				"are supported (use WithYAMLDocumentsKey to collect them)", len(documents)))
+	}
+
+	// SECURITY: Documents are limited together, as they are decoded into one configuration
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: documents}
+	if _, _, err := checkYAMLLimits(list, make(map[*yaml.Node]yamlNodeSize)); err != nil {
+		return nil, err
+	}
+	if opts.includes {
+		for _, document := range documents {
+			rewriteYAMLIncludes(document)
+		}
+	}
+
+	var values []interface{}
+	if err := list.Decode(&values); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{opts.yamlDocumentsKey: values}, nil
+}
+
+// decodeTOML is the built-in TOML decoder.
+// go-toml already rejects duplicate keys, so strict mode needs no extra checks.
+func decodeTOML(content []byte, _ *providerOptions) (map[string]interface{}, error) {
+	var config map[string]interface{}
+	err := toml.Unmarshal(content, &config)
+	return config, err
+}
+
+// rootToConfig converts a non-object root value into a configuration map.
+// With a root key configured the value is wrapped under it; otherwise the
+// root type is reported as an ARGUS_PARSE_ERROR.
+func rootToConfig(root interface{}, format Format, opts *providerOptions) (map[string]interface{}, error) {
+	if root == nil {
+		return nil, nil
+	}
+
+	if opts.rootKey != "" {
+		return map[string]interface{}{opts.rootKey: root}, nil
+	}
+
+	rootType := "scalar"
+	if _, ok := root.([]interface{}); ok {
+		rootType = "array"
+	}
+
+	return nil, errors.New("ARGUS_PARSE_ERROR",
+		fmt.Sprintf("failed to parse %s configuration: root must be an object, got %s "+
+			"(use WithRootKey to wrap non-object roots)", strings.ToUpper(string(format)), rootType))
+}
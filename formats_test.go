@@ -0,0 +1,862 @@
+// formats_test.go
+//
+// Configuration format registry tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/agilira/go-errors"
+)
+
+// restoreFormatRegistry snapshots the format registry and restores it when the test ends
+func restoreFormatRegistry(t *testing.T) {
+	t.Helper()
+
+	formatRegistry.RLock()
+	saved := make(map[string]formatDecoder, len(formatRegistry.decoders))
+	for ext, decoder := range formatRegistry.decoders {
+		saved[ext] = decoder
+	}
+	formatRegistry.RUnlock()
+
+	t.Cleanup(func() {
+		formatRegistry.Lock()
+		formatRegistry.decoders = saved
+		formatRegistry.Unlock()
+	})
+}
+
+// decodeKeyValue is a toy decoder for "key=value" lines
+func decodeKeyValue(content []byte) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line: %q", line)
+		}
+		config[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return config, nil
+}
+
+// TestRegisterFormat_CustomDecoder tests loading a file with a registered decoder
+func TestRegisterFormat_CustomDecoder(t *testing.T) {
+	restoreFormatRegistry(t)
+
+	if err := validateConfigFilePath("app.custom"); err == nil {
+		t.Fatal("Expected unregistered extension to be rejected")
+	}
+
+	if err := RegisterFormat(".CUSTOM", decodeKeyValue); err != nil {
+		t.Fatalf("RegisterFormat failed: %v", err)
+	}
+
+	if err := validateConfigFilePath("configs/app.custom"); err != nil {
+		t.Fatalf("Expected registered extension to pass validation: %v", err)
+	}
+
+	repo := newTestRepository(t, map[string]string{
+		"configs/app.custom": "service = billing\nregion = eu-west-1\n",
+		"configs/bad.custom": "not a key value line\n",
+	})
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := provider.loadConfigFromRepo(ctx, repo.gitURL("configs/app.custom", "main"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if result.Format != Format("custom") {
+		t.Errorf("Expected format custom, got %q", result.Format)
+	}
+	if result.Config["service"] != "billing" || result.Config["region"] != "eu-west-1" {
+		t.Errorf("Unexpected config: %v", result.Config)
+	}
+
+	_, err = provider.loadConfigFromRepo(ctx, repo.gitURL("configs/bad.custom", "main"))
+	if err == nil || !strings.Contains(err.Error(), "ARGUS_PARSE_ERROR") {
+		t.Errorf("Expected ARGUS_PARSE_ERROR from custom decoder, got %v", err)
+	}
+}
+
+// TestRegisterFormat_OverrideBuiltin tests replacing a built-in decoder
+func TestRegisterFormat_OverrideBuiltin(t *testing.T) {
+	restoreFormatRegistry(t)
+
+	called := false
+	err := RegisterFormat("yaml", func(content []byte) (map[string]interface{}, error) {
+		called = true
+		return decodeYAML(content, &providerOptions{})
+	})
+	if err != nil {
+		t.Fatalf("RegisterFormat failed: %v", err)
+	}
+
+	provider := GetProvider().(*GitProvider)
+	if _, err := provider.parseConfigFile("app.yaml", []byte("port: 8080\n")); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !called {
+		t.Error("Expected overriding decoder to be used for .yaml")
+	}
+	if formatForPath("app.yaml") != FormatYAML {
+		t.Errorf("Expected overridden decoder to keep the yaml format, got %q", formatForPath("app.yaml"))
+	}
+
+	// ".yml" keeps the built-in decoder
+	called = false
+	if _, err := provider.parseConfigFile("app.yml", []byte("port: 8080\n")); err != nil || called {
+		t.Errorf("Expected .yml to keep the built-in decoder (called=%v, err=%v)", called, err)
+	}
+}
+
+// TestRegisterFormat_InvalidInput tests registry input validation
+func TestRegisterFormat_InvalidInput(t *testing.T) {
+	restoreFormatRegistry(t)
+
+	invalid := []struct {
+		ext    string
+		decode DecodeFunc
+	}{
+		{"", decodeKeyValue},
+		{".", decodeKeyValue},
+		{"tar.gz", decodeKeyValue},
+		{"gz", decodeKeyValue},
+		{"../x", decodeKeyValue},
+		{"xml", nil},
+	}
+
+	for _, tc := range invalid {
+		if err := RegisterFormat(tc.ext, tc.decode); err == nil {
+			t.Errorf("Expected RegisterFormat(%q) to fail", tc.ext)
+		}
+	}
+}
+
+// TestWithStrictDecoding tests that strict decoding rejects duplicate JSON keys, and
+// that other ambiguous content is rejected in every mode
+func TestWithStrictDecoding(t *testing.T) {
+	strict, err := NewProvider(WithStrictDecoding(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	lenient := GetProvider().(*GitProvider)
+
+	testCases := []struct {
+		name          string
+		filePath      string
+		content       string
+		lenientPasses bool
+	}{
+		{"JSON duplicate key", "app.json", `{"port": 8080, "port": 9090}`, true},
+		{"JSON nested duplicate key", "app.json", `{"db": {"host": "a", "host": "b"}}`, true},
+		{"JSON duplicate key in array element", "app.json", `{"servers": [{"id": 1, "id": 2}]}`, true},
+		{"JSON trailing data", "app.json", `{"port": 8080} {"port": 9090}`, false},
+		{"YAML duplicate key", "app.yaml", "port: 8080\nport: 9090\n", false},
+		{"YAML multiple documents", "app.yml", "port: 8080\n---\nport: 9090\n", false},
+		{"TOML duplicate key", "app.toml", "port = 8080\nport = 9090\n", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := strict.parseConfigFile(tc.filePath, []byte(tc.content))
+			if err == nil {
+				t.Fatal("Expected strict decoding to fail")
+			}
+			if !strings.Contains(err.Error(), "ARGUS_PARSE_ERROR") {
+				t.Errorf("Expected ARGUS_PARSE_ERROR, got %v", err)
+			}
+
+			_, err = lenient.parseConfigFile(tc.filePath, []byte(tc.content))
+			if (err == nil) != tc.lenientPasses {
+				t.Errorf("Unexpected lenient result: %v", err)
+			}
+		})
+	}
+
+	t.Run("Deprecated alias", func(t *testing.T) {
+		provider, err := NewProvider(WithStrictYAML(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if _, err := provider.parseConfigFile("app.json", []byte(`{"port": 8080, "port": 9090}`)); err == nil {
+			t.Error("Expected WithStrictYAML to reject duplicate JSON keys")
+		}
+	})
+
+	t.Run("Valid content passes", func(t *testing.T) {
+		valid := map[string]string{
+			"app.json": `{"db": {"host": "a", "port": 5432}, "servers": [{"id": 1}, {"id": 2}], "port": 8080}`,
+			"app.yaml": "db:\n  host: a\nport: 8080\n",
+			"app.toml": "port = 8080\n[db]\nhost = \"a\"\n",
+		}
+		for filePath, content := range valid {
+			config, err := strict.parseConfigFile(filePath, []byte(content))
+			if err != nil {
+				t.Errorf("Expected %s to pass strict decoding: %v", filePath, err)
+				continue
+			}
+			if _, ok := config["port"]; !ok {
+				t.Errorf("Expected port in %s, got %v", filePath, config)
+			}
+		}
+	})
+}
+
+// TestWithJSONUseNumber tests that large integers survive JSON decoding intact
+func TestWithJSONUseNumber(t *testing.T) {
+	const content = `{"snowflake_id": 9223372036854775807, "ratio": 0.25, "nested": {"ids": [1234567890123456789]}}`
+
+	provider, err := NewProvider(WithJSONUseNumber(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	config, err := provider.parseConfigFile("ids.json", []byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	id, ok := config["snowflake_id"].(json.Number)
+	if !ok {
+		t.Fatalf("Expected json.Number, got %T", config["snowflake_id"])
+	}
+	if n, err := id.Int64(); err != nil || n != 9223372036854775807 {
+		t.Errorf("Expected 9223372036854775807, got %v (err=%v)", id, err)
+	}
+
+	nested := config["nested"].(map[string]interface{})["ids"].([]interface{})
+	if nested[0].(json.Number).String() != "1234567890123456789" {
+		t.Errorf("Nested integer mangled: %v", nested[0])
+	}
+	if ratio, _ := config["ratio"].(json.Number).Float64(); ratio != 0.25 {
+		t.Errorf("Expected ratio 0.25, got %v", config["ratio"])
+	}
+
+	t.Run("Combined with strict decoding", func(t *testing.T) {
+		provider, err := NewProvider(WithJSONUseNumber(true), WithStrictDecoding(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if _, err := provider.parseConfigFile("ids.json", []byte(`{"id": 1, "id": 2}`)); err == nil {
+			t.Error("Expected duplicate key to be rejected")
+		}
+		if _, err := provider.parseConfigFile("ids.json", []byte(content)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Default keeps float64", func(t *testing.T) {
+		config, err := GetProvider().(*GitProvider).parseConfigFile("ids.json", []byte(content))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if _, ok := config["snowflake_id"].(float64); !ok {
+			t.Errorf("Expected float64 by default, got %T", config["snowflake_id"])
+		}
+	})
+}
+
+// TestParseConfigFile_EmptyContent tests that blank files decode to an empty configuration
+func TestParseConfigFile_EmptyContent(t *testing.T) {
+	providers := map[string]*GitProvider{
+		"default": GetProvider().(*GitProvider),
+	}
+	if strict, err := NewProvider(WithStrictDecoding(true), WithJSONUseNumber(true)); err == nil {
+		providers["strict"] = strict
+	}
+
+	contents := map[string]string{
+		"empty":         "",
+		"whitespace":    " \n\t\r\n  ",
+		"YAML comments": "# populated later\n",
+	}
+
+	for providerName, provider := range providers {
+		for _, filePath := range []string{"app.json", "app.yaml", "app.yml", "app.toml"} {
+			for contentName, content := range contents {
+				if contentName == "YAML comments" && formatForPath(filePath) != FormatYAML {
+					continue
+				}
+
+				t.Run(providerName+"/"+filePath+"/"+contentName, func(t *testing.T) {
+					config, err := provider.parseConfigFile(filePath, []byte(content))
+					if err != nil {
+						t.Fatalf("Expected blank content to parse, got %v", err)
+					}
+					if config == nil || len(config) != 0 {
+						t.Errorf("Expected empty non-nil map, got %#v", config)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestParseConfigFile_NonObjectRoot tests array and scalar configuration roots
+func TestParseConfigFile_NonObjectRoot(t *testing.T) {
+	files := map[string]string{
+		"flags.json": `["beta-ui", "fast-checkout"]`,
+		"flags.yaml": "- beta-ui\n- fast-checkout\n",
+	}
+
+	t.Run("Rejected by default", func(t *testing.T) {
+		provider := GetProvider().(*GitProvider)
+
+		for filePath, content := range files {
+			_, err := provider.parseConfigFile(filePath, []byte(content))
+			if err == nil {
+				t.Fatalf("Expected %s to be rejected", filePath)
+			}
+			if !strings.Contains(err.Error(), "ARGUS_PARSE_ERROR") || !strings.Contains(err.Error(), "root must be an object, got array") {
+				t.Errorf("Expected root-type ARGUS_PARSE_ERROR for %s, got %v", filePath, err)
+			}
+		}
+
+		_, err := provider.parseConfigFile("value.json", []byte(`42`))
+		if err == nil || !strings.Contains(err.Error(), "got scalar") {
+			t.Errorf("Expected scalar root to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Wrapped under root key", func(t *testing.T) {
+		provider, err := NewProvider(WithRootKey("items"), WithStrictDecoding(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		for filePath, content := range files {
+			config, err := provider.parseConfigFile(filePath, []byte(content))
+			if err != nil {
+				t.Fatalf("Parse of %s failed: %v", filePath, err)
+			}
+			items, ok := config["items"].([]interface{})
+			if !ok || len(items) != 2 || items[0] != "beta-ui" {
+				t.Errorf("Expected wrapped items for %s, got %#v", filePath, config)
+			}
+		}
+
+		// Object roots are never wrapped
+		config, err := provider.parseConfigFile("app.json", []byte(`{"port": 8080}`))
+		if err != nil || config["port"] == nil || config["items"] != nil {
+			t.Errorf("Expected object root unchanged, got %#v (err=%v)", config, err)
+		}
+	})
+
+	t.Run("Invalid root key", func(t *testing.T) {
+		for _, key := range []string{"", "  ", "bad\nkey"} {
+			if _, err := NewProvider(WithRootKey(key)); err == nil {
+				t.Errorf("Expected root key %q to be rejected", key)
+			}
+		}
+	})
+}
+
+// TestYAMLMultipleDocuments tests that multi-document YAML is rejected or collected, never truncated
+func TestYAMLMultipleDocuments(t *testing.T) {
+	content := []byte("kind: Service\nname: api\n---\nkind: Deployment\nname: api\nreplicas: 3\n")
+
+	t.Run("Rejected by default", func(t *testing.T) {
+		provider := GetProvider().(*GitProvider)
+
+		_, err := provider.parseConfigFile("resources.yaml", content)
+		if err == nil || !strings.Contains(err.Error(), "ARGUS_PARSE_ERROR") || !strings.Contains(err.Error(), "2 documents") {
+			t.Errorf("Expected ARGUS_PARSE_ERROR for two documents, got %v", err)
+		}
+
+		// A trailing separator or leading marker does not start another document
+		for _, single := range []string{"port: 8080\n---\n", "---\nport: 8080\n", "port: 8080\n...\n"} {
+			config, err := provider.parseConfigFile("app.yaml", []byte(single))
+			if err != nil || config["port"] != 8080 {
+				t.Errorf("Expected %q to load as one document, got %#v (err=%v)", single, config, err)
+			}
+		}
+	})
+
+	t.Run("Collected under documents key", func(t *testing.T) {
+		provider, err := NewProvider(WithYAMLDocumentsKey("documents"), WithStrictDecoding(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		config, err := provider.parseConfigFile("resources.yaml", content)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		documents, ok := config["documents"].([]interface{})
+		if !ok || len(documents) != 2 {
+			t.Fatalf("Expected two collected documents, got %#v", config)
+		}
+		second, ok := documents[1].(map[string]interface{})
+		if !ok || second["kind"] != "Deployment" || second["replicas"] != 3 {
+			t.Errorf("Expected the second document in order, got %#v", documents[1])
+		}
+
+		// Empty documents are skipped and single documents are decoded as usual
+		config, err = provider.parseConfigFile("resources.yaml", []byte("a: 1\n---\n---\nb: 2\n"))
+		if err != nil || len(config["documents"].([]interface{})) != 2 {
+			t.Errorf("Expected empty document to be skipped, got %#v (err=%v)", config, err)
+		}
+		config, err = provider.parseConfigFile("app.yaml", []byte("port: 8080\n"))
+		if err != nil || config["port"] != 8080 || config["documents"] != nil {
+			t.Errorf("Expected single document unchanged, got %#v (err=%v)", config, err)
+		}
+	})
+
+	t.Run("Documents limited together", func(t *testing.T) {
+		provider, err := NewProvider(WithYAMLDocumentsKey("documents"))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		_, err = provider.parseConfigFile("resources.yaml", []byte("name: a\n---\n"+yamlAliasBomb(9)))
+		if err == nil || !strings.Contains(err.Error(), "ARGUS_RESOURCE_LIMIT") {
+			t.Errorf("Expected ARGUS_RESOURCE_LIMIT for an alias bomb in a later document, got %v", err)
+		}
+	})
+
+	t.Run("Invalid documents key", func(t *testing.T) {
+		for _, key := range []string{"", "  ", "bad\nkey"} {
+			if _, err := NewProvider(WithYAMLDocumentsKey(key)); err == nil {
+				t.Errorf("Expected documents key %q to be rejected", key)
+			}
+		}
+	})
+}
+
+// gzipContent compresses content with gzip
+func gzipContent(t *testing.T, content []byte) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(content); err != nil {
+		t.Fatalf("Failed to compress content: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress content: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// TestParseConfigFile_Gzip tests loading gzip-compressed files as their inner format
+func TestParseConfigFile_Gzip(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"app.json.gz":        string(gzipContent(t, []byte(`{"service": "api", "port": 8080}`))),
+		"services/db.YML.GZ": string(gzipContent(t, []byte("host: db.internal\n"))),
+	})
+
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := provider.loadConfigFromRepo(ctx, repo.gitURL("app.json.gz", "main"))
+	if err != nil {
+		t.Fatalf("Load of app.json.gz failed: %v", err)
+	}
+	if result.Format != FormatJSON || result.Config["service"] != "api" {
+		t.Errorf("Expected decompressed JSON, got %s %v", result.Format, result.Config)
+	}
+
+	result, err = provider.loadConfigFromRepo(ctx, repo.gitURL("services/db.YML.GZ", "main"))
+	if err != nil {
+		t.Fatalf("Load of services/db.YML.GZ failed: %v", err)
+	}
+	if result.Format != FormatYAML || result.Config["host"] != "db.internal" {
+		t.Errorf("Expected decompressed YAML, got %s %v", result.Format, result.Config)
+	}
+
+	t.Run("Inner extension validated", func(t *testing.T) {
+		for _, filePath := range []string{"app.gz", "app.exe.gz", "app.json.gz.gz"} {
+			if err := validateConfigFilePathWith(filePath, defaultConfigExtensions()); err == nil {
+				t.Errorf("Expected %s to be rejected", filePath)
+			}
+		}
+		if err := validateConfigFilePathWith("app.json.gz", []string{".yaml"}); err == nil {
+			t.Error("Expected app.json.gz to be rejected when only .yaml is allowed")
+		}
+	})
+
+	t.Run("Corrupt stream", func(t *testing.T) {
+		_, err := provider.parseConfigFile("app.json.gz", []byte(`{"service": "api"}`))
+		if !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+			t.Errorf("Expected ARGUS_PARSE_ERROR for non-gzip content, got %v", err)
+		}
+	})
+
+	t.Run("Decompression bomb", func(t *testing.T) {
+		bomb := gzipContent(t, make([]byte, maxConfigFileSize+1))
+		if len(bomb) >= maxConfigFileSize/100 {
+			t.Fatalf("Expected a small compressed bomb, got %d bytes", len(bomb))
+		}
+		_, err := provider.parseConfigFile("bomb.json.gz", bomb)
+		if !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+			t.Errorf("Expected ARGUS_RESOURCE_LIMIT for oversized decompressed content, got %v", err)
+		}
+	})
+}
+
+// utf16Content encodes text as UTF-16 with a byte order mark
+func utf16Content(text string, bigEndian bool) []byte {
+	content := []byte{0xFF, 0xFE}
+	if bigEndian {
+		content = []byte{0xFE, 0xFF}
+	}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		if bigEndian {
+			content = append(content, byte(unit>>8), byte(unit))
+		} else {
+			content = append(content, byte(unit), byte(unit>>8))
+		}
+	}
+	return content
+}
+
+// TestParseConfigFile_ByteOrderMarks tests decoding files with UTF-8 and UTF-16 byte order marks
+func TestParseConfigFile_ByteOrderMarks(t *testing.T) {
+	const bom = "\xEF\xBB\xBF"
+	repo := newTestRepository(t, map[string]string{
+		"app.json":   bom + `{"service": "api", "port": 8080}`,
+		"app.yaml":   bom + "service: api\nport: 8080\n",
+		"app.toml":   bom + "service = \"api\"\nport = 8080\n",
+		"app.yml.gz": string(gzipContent(t, []byte(bom+"service: api\nport: 8080\n"))),
+	})
+
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, filePath := range []string{"app.json", "app.yaml", "app.toml", "app.yml.gz"} {
+		result, err := provider.loadConfigFromRepo(ctx, repo.gitURL(filePath, "main"))
+		if err != nil {
+			t.Fatalf("Load of BOM-prefixed %s failed: %v", filePath, err)
+		}
+		if result.Config["service"] != "api" {
+			t.Errorf("Unexpected config for %s: %v", filePath, result.Config)
+		}
+	}
+
+	t.Run("UTF-16", func(t *testing.T) {
+		for _, bigEndian := range []bool{false, true} {
+			config, err := provider.parseConfigFile("app.json", utf16Content(`{"service": "api", "region": "zürich 🏔"}`, bigEndian))
+			if err != nil {
+				t.Fatalf("Parse of UTF-16 (big endian %v) failed: %v", bigEndian, err)
+			}
+			if config["service"] != "api" || config["region"] != "zürich 🏔" {
+				t.Errorf("Unexpected config: %v", config)
+			}
+		}
+
+		truncated := utf16Content("service: api\n", false)
+		_, err := provider.parseConfigFile("app.yaml", truncated[:len(truncated)-1])
+		if !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+			t.Errorf("Expected ARGUS_PARSE_ERROR for truncated UTF-16, got %v", err)
+		}
+	})
+
+	t.Run("BOM only", func(t *testing.T) {
+		config, err := provider.parseConfigFile("app.json", []byte(bom))
+		if err != nil || len(config) != 0 {
+			t.Errorf("Expected an empty configuration, got %v (err=%v)", config, err)
+		}
+	})
+}
+
+// TestParseConfigFile_BinaryContent tests that binary files are rejected before decoding
+func TestParseConfigFile_BinaryContent(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x10"
+	repo := newTestRepository(t, map[string]string{
+		"logo.json":   png,
+		"app.json.gz": string(gzipContent(t, []byte(png))),
+	})
+
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, filePath := range []string{"logo.json", "app.json.gz"} {
+		_, err := provider.loadConfigFromRepo(ctx, repo.gitURL(filePath, "main"))
+		if !errors.HasCode(err, "ARGUS_PARSE_ERROR") || !strings.Contains(err.Error(), "binary data") {
+			t.Errorf("Expected a binary data ARGUS_PARSE_ERROR for %s, got %v", filePath, err)
+		}
+	}
+
+	// Mostly invalid UTF-8 without NUL bytes is binary too
+	noise := bytes.Repeat([]byte{0xC3, 0x28, 0xFF, 0x41}, 64)
+	if _, err := provider.parseConfigFile("app.yaml", noise); !errors.HasCode(err, "ARGUS_PARSE_ERROR") || !strings.Contains(err.Error(), "not valid UTF-8") {
+		t.Errorf("Expected a binary data ARGUS_PARSE_ERROR, got %v", err)
+	}
+
+	// Multi-byte text, also when the inspected prefix ends within a character
+	text := `{"text": "` + strings.Repeat("こんにちは", binarySniffLength/5) + `"}`
+	config, err := provider.parseConfigFile("app.json", []byte(text))
+	if err != nil || config["text"] == nil {
+		t.Errorf("Expected long multi-byte text to parse, got %v", err)
+	}
+
+	// Registered decoders may accept binary formats
+	restoreFormatRegistry(t)
+	if err := RegisterFormat("bin", func([]byte) (map[string]interface{}, error) {
+		return map[string]interface{}{"decoded": true}, nil
+	}); err != nil {
+		t.Fatalf("RegisterFormat failed: %v", err)
+	}
+	if _, err := provider.parseConfigFile("app.bin", []byte(png)); err != nil {
+		t.Errorf("Expected a registered decoder to receive binary content, got %v", err)
+	}
+}
+
+// yamlAliasBomb returns a "billion laughs" YAML document whose aliases expand to 10^levels nodes
+func yamlAliasBomb(levels int) string {
+	var builder strings.Builder
+	builder.WriteString("l0: &l0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i < levels; i++ {
+		ref := fmt.Sprintf("*l%d", i-1)
+		fmt.Fprintf(&builder, "l%d: &l%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(ref+", ", 10), ", "))
+	}
+	return builder.String()
+}
+
+// nestedJSON returns a JSON object nested depth levels deep
+func nestedJSON(depth int) string {
+	return strings.Repeat(`{"a": `, depth) + "1" + strings.Repeat("}", depth)
+}
+
+// TestParseConfigFile_Limits tests alias expansion and nesting limits of the parsers
+func TestParseConfigFile_Limits(t *testing.T) {
+	provider := GetProvider().(*GitProvider)
+
+	t.Run("YAML alias bomb", func(t *testing.T) {
+		bomb := yamlAliasBomb(9)
+		if len(bomb) > 1024 {
+			t.Fatalf("Expected a small bomb, got %d bytes", len(bomb))
+		}
+		_, err := provider.parseConfigFile("bomb.yaml", []byte(bomb))
+		if !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+			t.Errorf("Expected ARGUS_RESOURCE_LIMIT for an alias bomb, got %v", err)
+		}
+	})
+
+	t.Run("YAML aliases and merge keys", func(t *testing.T) {
+		content := "base: &base {timeout: 30, retries: 3}\n" +
+			"prod:\n  <<: *base\n  retries: 5\n" +
+			yamlAliasBomb(3) // 1000 expanded nodes is well within the limit
+		config, err := provider.parseConfigFile("app.yaml", []byte(content))
+		if err != nil {
+			t.Fatalf("Expected ordinary aliases to parse, got %v", err)
+		}
+		prod, _ := config["prod"].(map[string]interface{})
+		if prod["timeout"] != 30 || prod["retries"] != 5 {
+			t.Errorf("Expected merged prod settings, got %v", config["prod"])
+		}
+	})
+
+	t.Run("Nesting depth", func(t *testing.T) {
+		deepYAML := "a: " + strings.Repeat("[", maxConfigDepth) + strings.Repeat("]", maxConfigDepth) + "\n"
+		tooDeep := map[string]string{
+			"deep.json": nestedJSON(maxConfigDepth + 1),
+			"deep.yaml": deepYAML,
+		}
+		for filePath, content := range tooDeep {
+			if _, err := provider.parseConfigFile(filePath, []byte(content)); !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+				t.Errorf("Expected ARGUS_RESOURCE_LIMIT for %s, got %v", filePath, err)
+			}
+		}
+
+		if _, err := provider.parseConfigFile("limit.json", []byte(nestedJSON(maxConfigDepth))); err != nil {
+			t.Errorf("Expected nesting at the limit to parse, got %v", err)
+		}
+	})
+
+	t.Run("Registered decoder", func(t *testing.T) {
+		restoreFormatRegistry(t)
+
+		deep := map[string]interface{}{}
+		for i := 0; i < maxConfigDepth; i++ {
+			deep = map[string]interface{}{"a": deep}
+		}
+		if err := RegisterFormat("deep", func([]byte) (map[string]interface{}, error) {
+			return deep, nil
+		}); err != nil {
+			t.Fatalf("RegisterFormat failed: %v", err)
+		}
+		if _, err := provider.parseConfigFile("app.deep", []byte("x")); !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+			t.Errorf("Expected ARGUS_RESOURCE_LIMIT for a deep registered decoder result, got %v", err)
+		}
+	})
+}
+
+// TestGitProvider_ForcedFormat tests parsing files as an explicit format regardless of their extension
+func TestGitProvider_ForcedFormat(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"settings.txt":   `{"service": "api", "port": 8080}`,
+		"app.config":     "service: worker\n",
+		"secrets.txt":    `{"password": "hunter2"}`,
+		"conf/data.yaml": `{"service": "json-in-yaml"}`,
+	})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#"
+
+	t.Run("Text file forced as JSON", func(t *testing.T) {
+		result, err := provider.LoadDetailed(ctx, baseURL+"settings.txt?format=json")
+		if err != nil {
+			t.Fatalf("LoadDetailed failed: %v", err)
+		}
+		if result.Config["service"] != "api" || result.Format != FormatJSON {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		parsed, err := provider.ParseURL(baseURL + "settings.txt?format=JSON")
+		if err != nil || parsed.Format != FormatJSON {
+			t.Errorf("Expected ParseURL to report the forced format, got %+v (err=%v)", parsed, err)
+		}
+	})
+
+	t.Run("Extension still required without a format", func(t *testing.T) {
+		if err := provider.Validate(baseURL + "settings.txt"); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG, got %v", err)
+		}
+	})
+
+	t.Run("Format overrides a registered extension", func(t *testing.T) {
+		result, err := provider.LoadDetailed(ctx, baseURL+"conf/data.yaml?format=json")
+		if err != nil {
+			t.Fatalf("LoadDetailed failed: %v", err)
+		}
+		if result.Format != FormatJSON || result.Config["service"] != "json-in-yaml" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		// The same file without a format is cached separately
+		result, err = provider.LoadDetailed(ctx, baseURL+"conf/data.yaml")
+		if err != nil || result.Format != FormatYAML {
+			t.Errorf("Expected the file to load as YAML without a format, got %+v (err=%v)", result, err)
+		}
+	})
+
+	t.Run("WithFormat option", func(t *testing.T) {
+		yamlProvider, err := NewProvider(WithAllowLocalRepos(true), WithFormat("yml"))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		config, err := yamlProvider.Load(ctx, baseURL+"app.config")
+		if err != nil || config["service"] != "worker" {
+			t.Errorf("Expected app.config to load as YAML, got %v (err=%v)", config, err)
+		}
+
+		// The URL parameter takes precedence over the option
+		config, err = yamlProvider.Load(ctx, baseURL+"settings.txt?format=json")
+		if err != nil || config["port"] != float64(8080) {
+			t.Errorf("Expected settings.txt to load as JSON, got %v (err=%v)", config, err)
+		}
+	})
+
+	t.Run("Path checks still apply", func(t *testing.T) {
+		for _, filePath := range []string{"secrets.txt", "../outside.txt", ".env", ".ssh/config"} {
+			if err := provider.Validate(baseURL + filePath + "?format=json"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+				t.Errorf("Expected ARGUS_SECURITY_ERROR for %s, got %v", filePath, err)
+			}
+		}
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		if err := provider.Validate(baseURL + "settings.txt?format=ini"); !errors.HasCode(err, "ARGUS_UNSUPPORTED_FORMAT") {
+			t.Errorf("Expected ARGUS_UNSUPPORTED_FORMAT, got %v", err)
+		}
+		if _, err := NewProvider(WithFormat("xml")); !errors.HasCode(err, "ARGUS_UNSUPPORTED_FORMAT") {
+			t.Errorf("Expected ARGUS_UNSUPPORTED_FORMAT from WithFormat, got %v", err)
+		}
+	})
+}
+
+// TestTOMLValueTypes tests that TOML values keep their Go types through the caches
+func TestTOMLValueTypes(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.toml": `deployed = 2025-03-14T09:26:53Z
+build = 2025-03-14T09:26:53
+release = 2025-03-14
+replicas = 3
+enabled = true
+
+[[servers]]
+started = 2025-03-14T10:00:00+01:00
+`})
+	gitURL := repo.gitURL("config.toml", "main")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deployed := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	checkTypes := func(t *testing.T, source string, config map[string]interface{}) {
+		t.Helper()
+		if value, ok := config["deployed"].(time.Time); !ok || !value.Equal(deployed) {
+			t.Errorf("%s: expected deployed as time.Time %s, got %T %v", source, deployed, config["deployed"], config["deployed"])
+		}
+		for key, want := range map[string]string{"build": "toml.LocalDateTime", "release": "toml.LocalDate", "replicas": "int64", "enabled": "bool"} {
+			if got := fmt.Sprintf("%T", config[key]); got != want {
+				t.Errorf("%s: expected %s as %s, got %s", source, key, want, got)
+			}
+		}
+		servers, _ := config["servers"].([]interface{})
+		if len(servers) != 1 {
+			t.Fatalf("%s: expected one server, got %v", source, config["servers"])
+		}
+		if _, ok := servers[0].(map[string]interface{})["started"].(time.Time); !ok {
+			t.Errorf("%s: expected the nested datetime as time.Time, got %T", source, servers[0].(map[string]interface{})["started"])
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	provider, err := NewProvider(WithDiskCache(dir))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	result, err := provider.loadConfigFromRepo(ctx, gitURL)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	checkTypes(t, "fresh load", result.Config)
+
+	// Modifying a result, nested values included, leaves the cached configuration intact
+	result.Config["servers"].([]interface{})[0].(map[string]interface{})["started"] = "modified"
+	result.Config["deployed"] = "modified"
+
+	cached, err := provider.loadConfigFromRepo(ctx, gitURL)
+	if err != nil || !cached.cacheHit {
+		t.Fatalf("Expected a cache hit, got %+v (err=%v)", cached, err)
+	}
+	checkTypes(t, "memory cache", cached.Config)
+
+	hydrated, err := NewProvider(WithDiskCache(dir))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	result, err = hydrated.loadConfigFromRepo(ctx, gitURL)
+	if err != nil || !result.cacheHit {
+		t.Fatalf("Expected a disk cache hit, got %+v (err=%v)", result, err)
+	}
+	checkTypes(t, "disk cache", result.Config)
+}
@@ -86,10 +86,15 @@ func FuzzValidateGitHost(f *testing.F) {
 // FuzzValidateRepositoryPath tests path traversal protection
 func FuzzValidateRepositoryPath(f *testing.F) {
 	seeds := []string{
-		"/user/repo.git",            // Valid
-		"/../../../etc/passwd",      // Path traversal
-		"/%2e%2e/etc/passwd",        // URL encoded traversal
-		"/user/../../../etc/shadow", // Mixed traversal
+		"/user/repo.git",                                                // Valid
+		"/../../../etc/passwd",                                          // Path traversal
+		"/%2e%2e/etc/passwd",                                            // URL encoded traversal
+		"/user/../../../etc/shadow",                                     // Mixed traversal
+		"/user/%252e%252e/repo.git",                                     // Double URL encoded traversal
+		"/user/%2e%2E%5crepo.git",                                       // Mixed case and backslash encoding
+		"/%25252e%25252e/repo.git",                                      // Excessive encoding layers
+		"/org/" + strings.Repeat("r", maxRepoPathLength) + ".git",       // Overlong path
+		"/org/" + strings.Repeat("%2572", maxRepoPathLength/5) + ".git", // Overlong encoded path
 	}
 
 	for _, seed := range seeds {
@@ -114,10 +119,14 @@ func FuzzValidateRepositoryPath(f *testing.F) {
 // FuzzValidateConfigFilePath tests config file path validation
 func FuzzValidateConfigFilePath(f *testing.F) {
 	seeds := []string{
-		"config.json",              // Valid
-		"../../../etc/passwd",      // Path traversal
-		"config.exe",               // Invalid extension
-		"config.json\x00malicious", // Null byte
+		"config.json",                // Valid
+		"../../../etc/passwd",        // Path traversal
+		"config.exe",                 // Invalid extension
+		"config.json\x00malicious",   // Null byte
+		"%252e%252e/app.json",        // Double URL encoded traversal
+		"conf%5c..%5c..%5capp.json",  // Encoded backslash traversal
+		"%2E%2e%2Fsecret.json",       // Mixed-case encoding
+		"%zz/%2e%2e/%2e%2e/app.json", // Malformed escape shielding an encoded one
 	}
 
 	for _, seed := range seeds {
@@ -139,6 +148,39 @@ func FuzzValidateConfigFilePath(f *testing.F) {
 	})
 }
 
+// FuzzParseConfigFile tests that parsing hostile YAML and JSON stays within resource limits
+func FuzzParseConfigFile(f *testing.F) {
+	seeds := []string{
+		"service: api\nport: 8080\n",              // Valid
+		"base: &b {a: 1}\nprod: {<<: *b, b: 2}\n", // Merge key
+		yamlAliasBomb(9),                          // Billion laughs
+		"a: &a [*a]\n",                            // Self-referencing alias
+		"a: " + strings.Repeat("[", 5000) + "\n",  // Unterminated deep nesting
+		"a: " + strings.Repeat("{b: ", 200) + "1" + strings.Repeat("}", 200), // Deep mappings
+		nestedJSON(500), // Deep JSON
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	provider := GetProvider().(*GitProvider)
+	f.Fuzz(func(t *testing.T, content string) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("parseConfigFile panicked with: %q", truncate(content))
+			}
+		}()
+
+		for _, filePath := range []string{"config.yaml", "config.json"} {
+			config, err := provider.parseConfigFile(filePath, []byte(content))
+			if err == nil && checkConfigDepth(config, 0) != nil {
+				t.Errorf("SECURITY: Configuration nested beyond the limit accepted: %q", truncate(content))
+			}
+		}
+	})
+}
+
 // FuzzParseGitURL tests complete URL parsing
 func FuzzParseGitURL(f *testing.F) {
 	seeds := []string{
@@ -146,6 +188,18 @@ func FuzzParseGitURL(f *testing.F) {
 		"git://127.0.0.1/repo.git#../../../etc/passwd",
 		"git://github.com/repo.git#config.json?ref=../../etc/passwd",
 		"not-git://github.com/repo.git#config.json",
+		// Pathological query strings
+		"https://github.com/repo.git#config.json?" + strings.Repeat("a=1&", 2000),
+		"https://github.com/repo.git#config.json?" + strings.Repeat("&;", 2000),
+		"https://github.com/repo.git#config.json?ref=" + strings.Repeat("%25", 1000),
+		"https://github.com/repo.git?" + strings.Repeat("k", 1500) + "=1#config.json?fallback_ref=" + strings.Repeat(",", 500),
+		"https://github.com/repo.git#config.json?auth=header:" + strings.Repeat(":", 1000),
+		// Overlong references and repository paths
+		"https://github.com/repo.git#config.json?ref=" + strings.Repeat("r", 100000),
+		"https://github.com/repo.git#config.json?ref=main" + strings.Repeat("~1", 1000),
+		"https://github.com/repo.git#config.json?tag_constraint=" + strings.Repeat(">=1.0.0 || ", 200),
+		"https://github.com/" + strings.Repeat("r/", 1000) + "repo.git#config.json",
+		"git@github.com:" + strings.Repeat("r", 2000) + ".git#config.json",
 	}
 
 	for _, seed := range seeds {
@@ -161,7 +215,11 @@ func FuzzParseGitURL(f *testing.F) {
 			}
 		}()
 
+		start := time.Now()
 		gitURL, err := provider.parseGitURL(configURL)
+		if duration := time.Since(start); duration > 100*time.Millisecond {
+			t.Errorf("parseGitURL too slow (%v) for: %q", duration, truncate(configURL))
+		}
 
 		if err == nil && gitURL != nil {
 			// Check security
@@ -214,18 +272,28 @@ func isPrivate(host string) bool {
 }
 
 func hasPathTraversal(path string) bool {
-	// Check both original and URL decoded
+	// Check the original and every URL decoding layer
 	paths := []string{path}
-	if decoded, err := url.QueryUnescape(path); err == nil {
+	for i := 0; i < maxPathDecodeRounds; i++ {
+		decoded, err := url.QueryUnescape(paths[len(paths)-1])
+		if err != nil || decoded == paths[len(paths)-1] {
+			break
+		}
 		paths = append(paths, decoded)
 	}
 
-	patterns := []string{"..", "../", "..\\", "%2e%2e", "%2f", "%5c"}
-
-	for _, p := range paths {
-		lowerP := strings.ToLower(p)
-		for _, pattern := range patterns {
-			if strings.Contains(lowerP, pattern) {
+	for _, candidate := range paths {
+		// A path traverses if walking its segments ever climbs above the starting directory
+		depth := 0
+		for _, segment := range strings.Split(strings.ReplaceAll(candidate, "\\", "/"), "/") {
+			switch segment {
+			case "", ".":
+			case "..":
+				depth--
+			default:
+				depth++
+			}
+			if depth < 0 {
 				return true
 			}
 		}
@@ -0,0 +1,205 @@
+// gitenv.go: Git CLI environment compatibility
+//
+// The provider talks to Git servers through go-git rather than the git binary,
+// so the environment variables that configure the git CLI have no effect on it
+// by default. WithGitEnvironment maps a documented subset of them onto the
+// provider's transport settings, so the provider behaves like git in the same
+// environment:
+//
+//	GIT_SSL_CAINFO       CA bundle file trusted for HTTPS, like http.sslCAInfo
+//	GIT_SSL_NO_VERIFY    Skip HTTPS certificate verification, like http.sslVerify=false
+//	GIT_CONFIG_COUNT     With GIT_CONFIG_KEY_<n>/GIT_CONFIG_VALUE_<n>: the http.proxy,
+//	                     http.sslCAInfo and http.sslVerify settings
+//
+// As with git, GIT_SSL_* variables take precedence over GIT_CONFIG_* settings,
+// and explicit options (WithCABundle, WithHTTPProxy) take precedence over both.
+// GIT_SSH_COMMAND and GIT_PROXY_COMMAND require running external commands and
+// are not supported. The standard https_proxy and no_proxy variables are always
+// honored for HTTPS repositories without an explicit proxy.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	gohttp "net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// maxGitConfigEnvEntries bounds the GIT_CONFIG_COUNT entries that are read
+const maxGitConfigEnvEntries = 1000
+
+// unsupportedGitEnvVars are git CLI variables that cannot be honored without the git binary
+var unsupportedGitEnvVars = []string{"GIT_SSH_COMMAND", "GIT_SSH", "GIT_PROXY_COMMAND"}
+
+// applyGitEnvironment fills the transport settings that no explicit option set from
+// the git CLI environment, read through lookupEnv
+func (g *GitProvider) applyGitEnvironment(lookupEnv func(string) (string, bool)) error {
+	var caInfo, proxy, sslVerify string
+	var hasSSLVerify bool
+
+	// Git configuration passed through the environment; later entries override earlier ones
+	if count, ok := lookupEnv("GIT_CONFIG_COUNT"); ok && count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 || n > maxGitConfigEnvEntries {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid GIT_CONFIG_COUNT: %q", count))
+		}
+		for i := 0; i < n; i++ {
+			key, _ := lookupEnv(fmt.Sprintf("GIT_CONFIG_KEY_%d", i))
+			value, _ := lookupEnv(fmt.Sprintf("GIT_CONFIG_VALUE_%d", i))
+			switch strings.ToLower(key) {
+			case "http.sslcainfo":
+				caInfo = value
+			case "http.proxy":
+				proxy = value
+			case "http.sslverify":
+				sslVerify, hasSSLVerify = value, true
+			}
+		}
+	}
+
+	if value, ok := lookupEnv("GIT_SSL_CAINFO"); ok && value != "" {
+		caInfo = value
+	}
+
+	insecure := false
+	if hasSSLVerify {
+		verify, err := parseGitBool(sslVerify)
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid http.sslVerify in GIT_CONFIG environment")
+		}
+		insecure = !verify
+	}
+	if value, ok := lookupEnv("GIT_SSL_NO_VERIFY"); ok {
+		// Git disables verification whenever the variable is set; false values are
+		// honored here so that verification is never disabled by an explicit "0"
+		noVerify, err := parseGitBool(value)
+		insecure = err != nil || noVerify || value == ""
+	}
+
+	if g.options.caBundle == nil && caInfo != "" {
+		bundle, err := os.ReadFile(caInfo)
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_INVALID_CONFIG", "failed to read the CA bundle named by GIT_SSL_CAINFO")
+		}
+		if err := validateCABundle(bundle); err != nil {
+			return err
+		}
+		g.options.caBundle = bundle
+	}
+	if g.options.proxyURL == "" && proxy != "" {
+		proxyURL, err := normalizeProxyURL(proxy)
+		if err != nil {
+			return err
+		}
+		g.options.proxyURL = proxyURL
+	}
+	g.options.insecureSkipTLS = insecure
+
+	for _, name := range unsupportedGitEnvVars {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			g.logEvent(context.Background(), slog.LevelWarn, "unsupported git environment variable ignored",
+				slog.String("variable", name))
+		}
+	}
+
+	return nil
+}
+
+// parseGitBool parses a boolean as git config does
+func parseGitBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("bad boolean value %q", value)
+}
+
+// validateCABundle ensures a CA bundle holds at least one PEM certificate
+func validateCABundle(bundle []byte) error {
+	if !x509.NewCertPool().AppendCertsFromPEM(bundle) {
+		return errors.New("ARGUS_INVALID_CONFIG", "CA bundle contains no PEM certificates")
+	}
+	return nil
+}
+
+// normalizeProxyURL validates an HTTP proxy URL; like git, a bare host:port is an HTTP proxy
+func normalizeProxyURL(proxy string) (string, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	parsed, err := url.Parse(proxy)
+	if err != nil || parsed.Host == "" {
+		return "", errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid proxy URL: %s", redactLogText(proxy)))
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+		return proxy, nil
+	}
+	return "", errors.New("ARGUS_INVALID_CONFIG",
+		fmt.Sprintf("unsupported proxy scheme %q (use http, https or socks5)", parsed.Scheme))
+}
+
+// transportOptions returns the CA bundle, certificate verification and proxy settings
+// of Git operations on repoURL. They only apply to HTTP(S) repositories.
+func (g *GitProvider) transportOptions(repoURL string) ([]byte, bool, transport.ProxyOptions) {
+	if !isHTTPRepoURL(repoURL) {
+		return nil, false, transport.ProxyOptions{}
+	}
+	return g.options.caBundle, g.options.insecureSkipTLS, transport.ProxyOptions{URL: g.options.proxyURL}
+}
+
+// configureReleaseTransport applies the transport settings to the releases API client
+func (g *GitProvider) configureReleaseTransport() error {
+	if g.options.caBundle == nil && !g.options.insecureSkipTLS && g.options.proxyURL == "" {
+		if g.options.httpClient != nil && g.options.httpClient.Transport != nil {
+			g.releaseClient.Transport = g.options.httpClient.Transport
+		} else if g.options.dialer != nil {
+			g.releaseClient.Transport = g.baseHTTPTransport()
+		}
+		return nil
+	}
+
+	httpTransport := g.baseHTTPTransport()
+	if httpTransport.TLSClientConfig == nil {
+		httpTransport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		httpTransport.TLSClientConfig = httpTransport.TLSClientConfig.Clone()
+	}
+	if g.options.caBundle != nil {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		roots.AppendCertsFromPEM(g.options.caBundle)
+		httpTransport.TLSClientConfig.RootCAs = roots
+	}
+	// #nosec G402 - Only set through GIT_SSL_NO_VERIFY or http.sslVerify=false, as for git
+	httpTransport.TLSClientConfig.InsecureSkipVerify = g.options.insecureSkipTLS
+	if g.options.proxyURL != "" {
+		proxyURL, err := url.Parse(g.options.proxyURL)
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid proxy URL")
+		}
+		httpTransport.Proxy = gohttp.ProxyURL(proxyURL)
+	}
+
+	g.releaseClient.Transport = httpTransport
+	return nil
+}
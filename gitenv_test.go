@@ -0,0 +1,163 @@
+// gitenv_test.go
+//
+// Git CLI environment compatibility tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestWithGitEnvironment tests that GIT_SSL_* variables configure HTTPS clones
+func TestWithGitEnvironment(t *testing.T) {
+	// Start from an environment without git variables, restored when the test ends
+	for _, name := range []string{"GIT_SSL_CAINFO", "GIT_SSL_NO_VERIFY", "GIT_CONFIG_COUNT"} {
+		t.Setenv(name, "")
+		_ = os.Unsetenv(name)
+	}
+
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "api"}`})
+	server := repo.serveHTTPS()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caBundle, 0o600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	load := func(t *testing.T, opts ...Option) error {
+		t.Helper()
+		provider, err := NewProvider(opts...)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		provider.retryConfig = newNoRetryProvider().retryConfig
+		_, err = provider.loadConfigFromRepo(ctx, &GitURL{
+			RepoURL:   server.URL + "/.git",
+			FilePath:  "config.json",
+			Reference: "main",
+			AuthData:  make(map[string]string),
+		})
+		return err
+	}
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		t.Setenv("GIT_SSL_CAINFO", caFile)
+		if err := load(t); err == nil {
+			t.Error("Expected the environment to be ignored without WithGitEnvironment")
+		}
+	})
+
+	t.Run("GIT_SSL_CAINFO", func(t *testing.T) {
+		t.Setenv("GIT_SSL_CAINFO", caFile)
+		provider, err := NewProvider(WithGitEnvironment(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if !bytes.Equal(provider.options.caBundle, caBundle) {
+			t.Error("Expected the CA bundle to be read from GIT_SSL_CAINFO")
+		}
+		if err := load(t, WithGitEnvironment(true)); err != nil {
+			t.Errorf("Expected the clone to trust the CA bundle, got %v", err)
+		}
+	})
+
+	t.Run("http.sslCAInfo", func(t *testing.T) {
+		t.Setenv("GIT_CONFIG_COUNT", "1")
+		t.Setenv("GIT_CONFIG_KEY_0", "http.sslCAInfo")
+		t.Setenv("GIT_CONFIG_VALUE_0", caFile)
+		if err := load(t, WithGitEnvironment(true)); err != nil {
+			t.Errorf("Expected the clone to trust the configured CA bundle, got %v", err)
+		}
+	})
+
+	t.Run("GIT_SSL_NO_VERIFY", func(t *testing.T) {
+		t.Setenv("GIT_SSL_NO_VERIFY", "1")
+		if err := load(t, WithGitEnvironment(true)); err != nil {
+			t.Errorf("Expected verification to be skipped, got %v", err)
+		}
+
+		t.Setenv("GIT_SSL_NO_VERIFY", "false")
+		if err := load(t, WithGitEnvironment(true)); err == nil {
+			t.Error("Expected GIT_SSL_NO_VERIFY=false to keep verification")
+		}
+	})
+
+	t.Run("Explicit options take precedence", func(t *testing.T) {
+		t.Setenv("GIT_SSL_CAINFO", filepath.Join(t.TempDir(), "missing.pem"))
+		t.Setenv("GIT_CONFIG_COUNT", "1")
+		t.Setenv("GIT_CONFIG_KEY_0", "http.proxy")
+		t.Setenv("GIT_CONFIG_VALUE_0", "proxy.example.com:3128")
+
+		provider, err := NewProvider(WithGitEnvironment(true), WithCABundle(caBundle), WithHTTPProxy("http://egress.example.com:8080"))
+		if err != nil {
+			t.Fatalf("Expected explicit options to override the environment, got %v", err)
+		}
+		if provider.options.proxyURL != "http://egress.example.com:8080" {
+			t.Errorf("Unexpected proxy: %s", provider.options.proxyURL)
+		}
+	})
+}
+
+// TestApplyGitEnvironment tests mapping git CLI variables onto transport settings
+func TestApplyGitEnvironment(t *testing.T) {
+	apply := func(env map[string]string) (*GitProvider, error) {
+		provider := GetProvider().(*GitProvider)
+		err := provider.applyGitEnvironment(func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		})
+		return provider, err
+	}
+
+	provider, err := apply(map[string]string{
+		"GIT_CONFIG_COUNT":   "3",
+		"GIT_CONFIG_KEY_0":   "http.proxy",
+		"GIT_CONFIG_VALUE_0": "first.example.com:3128",
+		"GIT_CONFIG_KEY_1":   "HTTP.Proxy",
+		"GIT_CONFIG_VALUE_1": "proxy.example.com:3128",
+		"GIT_CONFIG_KEY_2":   "http.sslVerify",
+		"GIT_CONFIG_VALUE_2": "false",
+	})
+	if err != nil {
+		t.Fatalf("applyGitEnvironment failed: %v", err)
+	}
+	if provider.options.proxyURL != "http://proxy.example.com:3128" || !provider.options.insecureSkipTLS {
+		t.Errorf("Unexpected settings: proxy=%q insecure=%v", provider.options.proxyURL, provider.options.insecureSkipTLS)
+	}
+
+	// The proxy only applies to HTTP(S) repositories
+	if _, _, proxy := provider.transportOptions("ssh://git@github.com/org/repo.git"); proxy.URL != "" {
+		t.Errorf("Expected SSH repositories not to be proxied, got %s", proxy.URL)
+	}
+
+	for _, env := range []map[string]string{
+		{"GIT_CONFIG_COUNT": "many"},
+		{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "http.sslVerify", "GIT_CONFIG_VALUE_0": "maybe"},
+		{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "http.proxy", "GIT_CONFIG_VALUE_0": "ftp://proxy.example.com"},
+		{"GIT_SSL_CAINFO": filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		if _, err := apply(env); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG for %v, got %v", env, err)
+		}
+	}
+
+	if _, err := NewProvider(WithCABundle([]byte("not a certificate"))); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for an invalid CA bundle, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package gittest provides an in-memory stand-in for the Git configuration provider.
+//
+// Code loading configurations through the git.RemoteConfigProvider interface can be
+// unit-tested deterministically without network access or Git repositories: seed
+// the provider with file contents per repository and change them to simulate
+// commits seen by watches.
+//
+//	provider, err := gittest.NewInMemoryProvider(map[string]map[string][]byte{
+//	    "https://github.com/org/configs.git": {
+//	        "app.yaml": []byte("replicas: 2\n"),
+//	    },
+//	})
+//	config, err := provider.Load(ctx, "https://github.com/org/configs.git#app.yaml")
+//	...
+//	err = provider.SetFile("https://github.com/org/configs.git", "app.yaml", []byte("replicas: 3\n"))
+//
+// URLs are parsed and validated, and files decoded, exactly as by the Git provider,
+// so the same URLs and file contents work with both. References are not modelled:
+// every reference of a repository serves the same files.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+package gittest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	git "github.com/agilira/argus-provider-git"
+	"github.com/agilira/go-errors"
+)
+
+// Provider is an in-memory git.RemoteConfigProvider serving seeded file contents
+type Provider struct {
+	parser *git.GitProvider // Parses URLs and decodes files as the Git provider does
+
+	mutex    sync.Mutex
+	repos    map[string]map[string][]byte // Normalized repository URL -> file path -> content
+	watchers map[chan struct{}]struct{}   // Change notifications of active watches
+	closed   bool
+
+	done chan struct{} // Closed by Close to end every watch
+}
+
+var (
+	_ git.RemoteConfigProvider = (*Provider)(nil)
+	_ git.ClosableProvider     = (*Provider)(nil)
+)
+
+// NewInMemoryProvider creates a provider serving files, mapping repository URLs to
+// file paths to contents. Options configure URL validation and decoding as for
+// git.NewProvider, e.g. git.WithAllowLocalRepos(true) for file:// repository URLs.
+// Invalid repository URLs or file paths fail as they would in a configuration URL.
+func NewInMemoryProvider(files map[string]map[string][]byte, opts ...git.Option) (*Provider, error) {
+	parser, err := git.NewProvider(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		parser:   parser,
+		repos:    make(map[string]map[string][]byte),
+		watchers: make(map[chan struct{}]struct{}),
+		done:     make(chan struct{}),
+	}
+	for repoURL, repoFiles := range files {
+		for filePath, content := range repoFiles {
+			if err := p.SetFile(repoURL, filePath, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// SetFile sets the content of a file, as a commit changing it would. Watches of the
+// file deliver the new configuration when its content changed.
+func (p *Provider) SetFile(repoURL, filePath string, content []byte) error {
+	parsed, err := p.parser.ParseURL(repoURL + "#" + filePath)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.repos[parsed.RepoURL] == nil {
+		p.repos[parsed.RepoURL] = make(map[string][]byte)
+	}
+	p.repos[parsed.RepoURL][parsed.FilePath] = bytes.Clone(content)
+	p.notifyLocked()
+	return nil
+}
+
+// RemoveFile removes a file, as a commit deleting it would. Loads of the file then
+// fail with ARGUS_CONFIG_NOT_FOUND, while watches keep their last configuration.
+func (p *Provider) RemoveFile(repoURL, filePath string) error {
+	parsed, err := p.parser.ParseURL(repoURL + "#" + filePath)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.repos[parsed.RepoURL], parsed.FilePath)
+	p.notifyLocked()
+	return nil
+}
+
+// notifyLocked signals every watch to reload; callers hold the mutex
+func (p *Provider) notifyLocked() {
+	for changed := range p.watchers {
+		select {
+		case changed <- struct{}{}:
+		default: // A reload is already pending
+		}
+	}
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "Git Configuration Provider (in-memory)"
+}
+
+// Scheme returns the URL scheme of the Git provider
+func (p *Provider) Scheme() string {
+	return p.parser.Scheme()
+}
+
+// Load decodes the seeded content of the file the URL names
+func (p *Provider) Load(ctx context.Context, configURL string) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	parsed, err := p.parser.ParseURL(configURL)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	closed := p.closed
+	content, ok := p.repos[parsed.RepoURL][parsed.FilePath]
+	p.mutex.Unlock()
+
+	if closed {
+		return nil, errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	}
+	if !ok {
+		return nil, errors.New("ARGUS_CONFIG_NOT_FOUND",
+			fmt.Sprintf("configuration file not found: %s", parsed.FilePath))
+	}
+
+	return p.parser.ParseConfig(parsed.FilePath, parsed.Format, content)
+}
+
+// Watch delivers the configuration the URL names, and again whenever SetFile changes
+// its content. The channel is closed when ctx is done or the provider is closed.
+func (p *Provider) Watch(ctx context.Context, configURL string) (<-chan map[string]interface{}, error) {
+	if err := p.parser.Validate(configURL); err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	}
+
+	changed := make(chan struct{}, 1)
+	changed <- struct{}{} // Deliver the initial configuration
+	p.watchers[changed] = struct{}{}
+
+	configs := make(chan map[string]interface{}, 1)
+	go p.watch(ctx, configURL, changed, configs)
+
+	return configs, nil
+}
+
+// watch delivers the configuration after each change notification when its content
+// differs from the last delivered one, until ctx is done or the provider is closed
+func (p *Provider) watch(ctx context.Context, configURL string, changed chan struct{}, configs chan<- map[string]interface{}) {
+	defer close(configs)
+	defer func() {
+		p.mutex.Lock()
+		delete(p.watchers, changed)
+		p.mutex.Unlock()
+	}()
+
+	var lastHash string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		case <-changed:
+		}
+
+		// Like the Git provider, a watch skips configurations that fail to load
+		config, err := p.Load(ctx, configURL)
+		if err != nil {
+			continue
+		}
+		hash := git.ConfigHash(config)
+		if hash == lastHash {
+			continue
+		}
+
+		select {
+		case configs <- config:
+			lastHash = hash
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Validate validates a configuration URL as the Git provider does
+func (p *Provider) Validate(configURL string) error {
+	return p.parser.Validate(configURL)
+}
+
+// HealthCheck reports whether the repository the URL names has been seeded
+func (p *Provider) HealthCheck(ctx context.Context, configURL string) error {
+	parsed, err := p.parser.ParseURL(configURL)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	}
+	if _, ok := p.repos[parsed.RepoURL]; !ok {
+		return errors.New("ARGUS_GIT_ERROR", fmt.Sprintf("repository not found: %s", parsed.RepoURL))
+	}
+	return nil
+}
+
+// Close ends every watch and rejects further operations. It is safe to call more than once.
+func (p *Provider) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	return p.parser.Close()
+}
+
+// IsClosed reports whether Close has been called
+func (p *Provider) IsClosed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.closed
+}
@@ -0,0 +1,175 @@
+// gittest_test.go
+//
+// In-memory provider tests
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package gittest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	git "github.com/agilira/argus-provider-git"
+	"github.com/agilira/go-errors"
+)
+
+const testRepo = "https://github.com/org/configs.git"
+
+// newTestProvider creates an in-memory provider seeded with a JSON and a YAML file
+func newTestProvider(t *testing.T, opts ...git.Option) *Provider {
+	t.Helper()
+
+	provider, err := NewInMemoryProvider(map[string]map[string][]byte{
+		testRepo: {
+			"app.json":          []byte(`{"service": "api", "replicas": 2}`),
+			"services/db.yaml":  []byte("host: db.internal\nport: 5432\n"),
+			"flags/rollout.yml": []byte("- checkout-v2\n"),
+		},
+	}, opts...)
+	if err != nil {
+		t.Fatalf("NewInMemoryProvider failed: %v", err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+// TestInMemoryProvider_Load tests loading seeded files through configuration URLs
+func TestInMemoryProvider_Load(t *testing.T) {
+	provider := newTestProvider(t, git.WithRootKey("items"))
+	ctx := context.Background()
+
+	config, err := provider.Load(ctx, testRepo+"#app.json")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config["service"] != "api" || config["replicas"] != float64(2) {
+		t.Errorf("Unexpected config: %v", config)
+	}
+
+	// URLs are normalized as by the Git provider
+	config, err = provider.Load(ctx, "https://github.com/org/configs?file=services/db.yaml&ref=v1.0.0")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config["host"] != "db.internal" || config["port"] != 5432 {
+		t.Errorf("Unexpected config: %v", config)
+	}
+
+	// Provider options apply to decoding
+	config, err = provider.Load(ctx, testRepo+"#flags/rollout.yml")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if items, ok := config["items"].([]interface{}); !ok || len(items) != 1 {
+		t.Errorf("Expected the root key option to wrap the list, got %v", config)
+	}
+
+	if _, err := provider.Load(ctx, testRepo+"#missing.json"); !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+		t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND, got %v", err)
+	}
+	if _, err := provider.Load(ctx, testRepo+"#../etc/app.json"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR, got %v", err)
+	}
+
+	if err := provider.HealthCheck(ctx, testRepo+"#app.json"); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+	if err := provider.HealthCheck(ctx, "https://github.com/org/other.git#app.json"); err == nil {
+		t.Error("Expected HealthCheck of an unseeded repository to fail")
+	}
+
+	if _, err := NewInMemoryProvider(map[string]map[string][]byte{"http://localhost/repo.git": {"app.json": nil}}); err == nil {
+		t.Error("Expected an invalid repository URL to be rejected")
+	}
+}
+
+// TestInMemoryProvider_Watch tests that watches deliver simulated commits
+func TestInMemoryProvider_Watch(t *testing.T) {
+	provider := newTestProvider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configs, err := provider.Watch(ctx, testRepo+"#app.json")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	receive := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		select {
+		case config := <-configs:
+			return config
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for a configuration")
+			return nil
+		}
+	}
+
+	if config := receive(t); config["replicas"] != float64(2) {
+		t.Errorf("Unexpected initial config: %v", config)
+	}
+
+	// Changes to other files, rewrites of the same content and deletions are not delivered
+	if err := provider.SetFile(testRepo, "services/db.yaml", []byte("host: db2.internal\n")); err != nil {
+		t.Fatalf("SetFile failed: %v", err)
+	}
+	if err := provider.SetFile(testRepo, "app.json", []byte(`{"replicas": 2, "service": "api"}`)); err != nil {
+		t.Fatalf("SetFile failed: %v", err)
+	}
+	if err := provider.RemoveFile(testRepo, "app.json"); err != nil {
+		t.Fatalf("RemoveFile failed: %v", err)
+	}
+	if err := provider.SetFile(testRepo, "app.json", []byte(`{"service": "api", "replicas": 5}`)); err != nil {
+		t.Fatalf("SetFile failed: %v", err)
+	}
+	if config := receive(t); config["replicas"] != float64(5) {
+		t.Errorf("Expected the changed config, got %v", config)
+	}
+
+	// Closing the provider ends the watch
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case _, ok := <-configs:
+		if ok {
+			t.Error("Expected no further configurations")
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected the watch channel to be closed")
+	}
+
+	if _, err := provider.Watch(ctx, testRepo+"#app.json"); !errors.HasCode(err, "ARGUS_PROVIDER_CLOSED") {
+		t.Errorf("Expected ARGUS_PROVIDER_CLOSED, got %v", err)
+	}
+	if !provider.IsClosed() {
+		t.Error("Expected the provider to report being closed")
+	}
+}
+
+// TestInMemoryProvider_WatchCancel tests that cancelling the context ends a watch
+func TestInMemoryProvider_WatchCancel(t *testing.T) {
+	provider := newTestProvider(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	configs, err := provider.Watch(ctx, testRepo+"#app.json")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	<-configs
+	cancel()
+
+	select {
+	case _, ok := <-configs:
+		if ok {
+			t.Error("Expected no further configurations")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the watch channel to be closed")
+	}
+}
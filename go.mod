@@ -6,6 +6,8 @@ require (
 	github.com/agilira/go-errors v1.1.1
 	github.com/go-git/go-git/v5 v5.16.3
 	github.com/pelletier/go-toml/v2 v2.2.4
+	golang.org/x/crypto v0.43.0
+	golang.org/x/sys v0.37.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -27,8 +29,6 @@ require (
 	github.com/sergi/go-diff v1.4.0 // indirect
 	github.com/skeema/knownhosts v1.3.2 // indirect
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
-	golang.org/x/crypto v0.43.0 // indirect
 	golang.org/x/net v0.46.0 // indirect
-	golang.org/x/sys v0.37.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
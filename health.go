@@ -0,0 +1,120 @@
+// health.go: Aggregate health of watched configurations
+//
+// A readiness probe should reflect whether every configuration the service watches
+// can still be loaded, without knowing their URLs. HealthAll checks each watched
+// URL with a single remote reference listing and reports it together with the
+// outcome of the watch's latest load:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//	    for _, err := range provider.HealthAll(r.Context()) {
+//	        if err != nil {
+//	            http.Error(w, "configuration source unhealthy", http.StatusServiceUnavailable)
+//	            return
+//	        }
+//	    }
+//	})
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"sync"
+
+	"github.com/agilira/go-errors"
+)
+
+// watchedConfig is the health state of an active watch
+type watchedConfig struct {
+	configURL string // URL as passed to Watch
+	loadErr   error  // Error of the latest load, nil once one succeeded
+}
+
+// registerWatch records an active watch of configURL for HealthAll
+func (g *GitProvider) registerWatch(configURL string, gitURL *GitURL) {
+	g.watchedMutex.Lock()
+	defer g.watchedMutex.Unlock()
+	if g.watched == nil {
+		g.watched = make(map[*GitURL]*watchedConfig)
+	}
+	g.watched[gitURL] = &watchedConfig{configURL: configURL}
+}
+
+// unregisterWatch removes a watch that ended; unregistered watches are ignored
+func (g *GitProvider) unregisterWatch(gitURL *GitURL) {
+	g.watchedMutex.Lock()
+	delete(g.watched, gitURL)
+	g.watchedMutex.Unlock()
+}
+
+// recordWatchLoad records the outcome of a watch load
+func (g *GitProvider) recordWatchLoad(gitURL *GitURL, err error) {
+	g.watchedMutex.Lock()
+	if watched, ok := g.watched[gitURL]; ok {
+		watched.loadErr = err
+	}
+	g.watchedMutex.Unlock()
+}
+
+// HealthAll checks the repositories of all active watches, returning the status of
+// each watched URL, keyed as passed to Watch: nil when its repository answers a
+// remote reference listing within the health check timeout and its latest watch
+// load succeeded, or the error otherwise (ARGUS_HEALTH_CHECK_FAILED when the
+// repository is not accessible). Watches whose first load is still in progress
+// report only the listing. The repositories are checked concurrently; without
+// active watches the map is empty. Keys include any credentials the URLs carry,
+// so redact them before exposing the map.
+func (g *GitProvider) HealthAll(ctx context.Context) map[string]error {
+	g.watchedMutex.Lock()
+	watched := make(map[string]*GitURL, len(g.watched))
+	loadErrs := make(map[string]error, len(g.watched))
+	for gitURL, state := range g.watched {
+		// Several watches of one URL share a status; any failed load reports it
+		watched[state.configURL] = gitURL
+		if state.loadErr != nil {
+			loadErrs[state.configURL] = state.loadErr
+		}
+	}
+	g.watchedMutex.Unlock()
+
+	health := make(map[string]error, len(watched))
+	var healthMutex sync.Mutex
+	var wg sync.WaitGroup
+	for configURL, gitURL := range watched {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := g.checkRemoteHealth(ctx, gitURL)
+			if err == nil {
+				err = loadErrs[configURL]
+			}
+			healthMutex.Lock()
+			health[configURL] = err
+			healthMutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return health
+}
+
+// checkRemoteHealth verifies that the repository, or one of its mirrors, answers a
+// remote reference listing within the health check timeout
+func (g *GitProvider) checkRemoteHealth(ctx context.Context, gitURL *GitURL) error {
+	healthCtx, cancel := context.WithTimeout(ctx, g.options.effectiveHealthCheckTimeout())
+	defer cancel()
+
+	err := g.tryMirrors(gitURL, func(gitURL *GitURL) error {
+		return g.retryOperation(healthCtx, func() error {
+			_, err := g.listRemoteRefs(healthCtx, gitURL)
+			return err
+		}, "git health check")
+	})
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_HEALTH_CHECK_FAILED", "repository not accessible")
+	}
+	return nil
+}
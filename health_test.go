@@ -0,0 +1,105 @@
+// health_test.go
+//
+// Aggregate watch health tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestGitProvider_HealthAll tests reporting the health of every watched configuration
+func TestGitProvider_HealthAll(t *testing.T) {
+	api := newTestRepository(t, map[string]string{"config.json": `{"service": "api"}`})
+	worker := newTestRepository(t, map[string]string{"config.json": `{"service": "worker"}`})
+	apiURL := "file://" + api.dir + "#config.json?ref=main"
+	workerURL := "file://" + worker.dir + "#config.json?ref=main"
+	missingURL := "file://" + api.dir + "#missing.json?ref=main"
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if health := provider.HealthAll(ctx); len(health) != 0 {
+		t.Errorf("Expected no entries without watches, got %v", health)
+	}
+
+	var handles []*WatchHandle
+	for _, configURL := range []string{apiURL, workerURL} {
+		handle, err := provider.StartWatch(ctx, configURL)
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		defer handle.Stop()
+		select {
+		case <-handle.Config():
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the initial configuration")
+		}
+		handles = append(handles, handle)
+	}
+
+	health := provider.HealthAll(ctx)
+	if len(health) != 2 {
+		t.Fatalf("Expected both watches in the health map, got %v", health)
+	}
+	for _, configURL := range []string{apiURL, workerURL} {
+		if err, ok := health[configURL]; !ok || err != nil {
+			t.Errorf("Expected %s to be healthy, got %v (present: %v)", configURL, err, ok)
+		}
+	}
+
+	t.Run("Failed load", func(t *testing.T) {
+		handle, err := provider.StartWatch(ctx, missingURL)
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		defer handle.Stop()
+
+		// The initial load fails without a delivery; wait until it was recorded
+		for provider.HealthAll(ctx)[missingURL] == nil {
+			if ctx.Err() != nil {
+				t.Fatal("Timed out waiting for the failed load to be reported")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err := provider.HealthAll(ctx)[missingURL]; !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+			t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND for the failed load, got %v", err)
+		}
+	})
+
+	t.Run("Unreachable repository", func(t *testing.T) {
+		if err := os.RemoveAll(worker.dir); err != nil {
+			t.Fatalf("Failed to remove repository: %v", err)
+		}
+
+		health := provider.HealthAll(ctx)
+		if !errors.HasCode(health[workerURL], "ARGUS_HEALTH_CHECK_FAILED") {
+			t.Errorf("Expected ARGUS_HEALTH_CHECK_FAILED for the removed repository, got %v", health[workerURL])
+		}
+		if health[apiURL] != nil {
+			t.Errorf("Expected the other repository to stay healthy, got %v", health[apiURL])
+		}
+	})
+
+	t.Run("Stopped watches are removed", func(t *testing.T) {
+		handles[0].Stop()
+		if _, ok := provider.HealthAll(ctx)[apiURL]; ok {
+			t.Error("Expected the stopped watch to be removed from the health map")
+		}
+	})
+}
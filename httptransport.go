@@ -0,0 +1,252 @@
+// httptransport.go: Custom HTTP clients and dialers for Git over HTTP(S)
+//
+// Where direct egress is blocked, e.g. inside a service mesh whose sidecar listens
+// on a local Unix socket, WithDialer routes the provider's connections through a
+// custom dialer, and WithHTTPClient replaces the HTTP client altogether:
+//
+//	provider, err := git.NewProvider(git.WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+//	    var d net.Dialer
+//	    return d.DialContext(ctx, "unix", "/var/run/mesh/egress.sock")
+//	}))
+//
+// Clones, fetches, reference listings and releases API requests all use them.
+// Repository URLs are validated as always, so SSRF checks apply to the logical
+// host of the URL, which the dialer receives as its address; only where the
+// connection is made changes.
+//
+// go-git selects HTTP transports from a process-wide registry. The first provider
+// session installs a transport in it that hands sessions of providers to their
+// client and every other session to the transport registered before, which keeps
+// behaving as it did.
+//
+// SECURITY: Redirects are validated like repository URLs before they are followed,
+// since go-git would otherwise send every later request of a session to wherever
+// the first response redirected it, e.g. a cloud metadata endpoint or a private
+// address that passed no SSRF check. A redirect may not leave HTTPS, and one to
+// another host must pass the host validation of repository URLs.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"net"
+	gohttp "net/http"
+	"strings"
+	"sync"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// maxGitRedirects bounds the redirects followed by one Git-over-HTTP(S) request
+const maxGitRedirects = 10
+
+// installRoutedTransport registers routedTransport for HTTP(S) in go-git once
+var installRoutedTransport sync.Once
+
+// defaultGitTransport is the transport of providers without a custom client or dialer
+var defaultGitTransport = http.NewClient(&gohttp.Client{
+	Transport:     gohttp.DefaultTransport,
+	CheckRedirect: checkGitRedirect,
+})
+
+// WithHTTPClient sends Git-over-HTTP(S) and releases API requests through client, e.g.
+// one whose transport dials a mesh proxy. The client's Timeout applies to Git
+// requests, and its CheckRedirect to redirects that pass the provider's own checks;
+// releases API requests keep their own redirect checks and timeout.
+// WithCABundle, WithHTTPProxy and GIT_SSL_NO_VERIFY require its Transport to be an
+// *http.Transport (or nil), which is cloned to apply them. It cannot be combined
+// with WithDialer; a nil client fails with ARGUS_INVALID_CONFIG.
+func WithHTTPClient(client *gohttp.Client) Option {
+	return func(g *GitProvider) error {
+		if client == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "HTTP client cannot be nil")
+		}
+		g.options.httpClient = client
+		return nil
+	}
+}
+
+// WithDialer makes the connections of Git-over-HTTP(S) and releases API requests
+// through dial, which receives the logical "host:port" of the repository (or of the
+// proxy, with WithHTTPProxy) and may connect anywhere, e.g. to a local Unix socket.
+// TLS is still negotiated with the logical host. It cannot be combined with
+// WithHTTPClient; a nil dialer fails with ARGUS_INVALID_CONFIG.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(g *GitProvider) error {
+		if dial == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "dialer cannot be nil")
+		}
+		g.options.dialer = dial
+		return nil
+	}
+}
+
+// configureGitTransport creates the go-git HTTP transport of a custom client or dialer
+func (g *GitProvider) configureGitTransport() error {
+	if g.options.httpClient == nil && g.options.dialer == nil {
+		return nil
+	}
+	if g.options.httpClient != nil && g.options.dialer != nil {
+		return errors.New("ARGUS_INVALID_CONFIG", "WithHTTPClient and WithDialer cannot be combined")
+	}
+
+	var httpClient gohttp.Client
+	if g.options.httpClient != nil {
+		httpClient = *g.options.httpClient
+	}
+	if httpClient.Transport == nil || g.options.dialer != nil {
+		httpClient.Transport = g.baseHTTPTransport()
+	}
+	httpClient.CheckRedirect = chainRedirectCheck(httpClient.CheckRedirect)
+
+	// go-git clones the transport to apply the CA bundle, proxy and TLS verification
+	if _, ok := httpClient.Transport.(*gohttp.Transport); !ok &&
+		(g.options.caBundle != nil || g.options.insecureSkipTLS || g.options.proxyURL != "") {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			"the HTTP client must use an *http.Transport to apply CA bundle, proxy or TLS settings")
+	}
+
+	g.gitTransport = http.NewClient(&httpClient)
+	return nil
+}
+
+// checkGitRedirect validates a redirect of a Git-over-HTTP(S) request before it is
+// followed: it must stay on HTTPS if the request started there, and a redirect to
+// another host must pass validateGitHost, like the host of a repository URL
+func checkGitRedirect(req *gohttp.Request, via []*gohttp.Request) error {
+	if len(via) >= maxGitRedirects {
+		return errors.New("ARGUS_SECURITY_ERROR", "too many Git HTTP redirects")
+	}
+	if via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("Git HTTP redirect from https to %s not allowed", req.URL.Scheme))
+	}
+	if strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		return nil // The repository host was validated with its URL
+	}
+	if err := validateGitHost(req.URL.Host); err != nil {
+		return errors.Wrap(err, "ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("Git HTTP redirect to %s blocked", req.URL.Host))
+	}
+	return nil
+}
+
+// chainRedirectCheck returns a redirect check running checkGitRedirect, then the
+// custom client's own check if it has one
+func chainRedirectCheck(custom func(*gohttp.Request, []*gohttp.Request) error) func(*gohttp.Request, []*gohttp.Request) error {
+	if custom == nil {
+		return checkGitRedirect
+	}
+	return func(req *gohttp.Request, via []*gohttp.Request) error {
+		if err := checkGitRedirect(req, via); err != nil {
+			return err
+		}
+		return custom(req, via)
+	}
+}
+
+// baseHTTPTransport returns a new transport to configure: a clone of the custom
+// client's transport if it is an *http.Transport, else of the default transport,
+// dialing through the custom dialer if one is set
+func (g *GitProvider) baseHTTPTransport() *gohttp.Transport {
+	base := gohttp.DefaultTransport.(*gohttp.Transport)
+	if g.options.httpClient != nil {
+		if custom, ok := g.options.httpClient.Transport.(*gohttp.Transport); ok {
+			base = custom
+		}
+	}
+
+	httpTransport := base.Clone()
+	if g.options.dialer != nil {
+		httpTransport.DialContext = g.options.dialer
+		httpTransport.DialTLSContext = nil // TLS runs over the dialed connection
+	}
+	return httpTransport
+}
+
+// withHTTPRoute wraps the authentication of HTTP(S) repositories so that their
+// sessions use the provider's transport: its custom one, or the default transport
+// with redirect checks. nil authentication is wrapped too.
+func (g *GitProvider) withHTTPRoute(gitURL *GitURL, auth transport.AuthMethod) transport.AuthMethod {
+	if !isHTTPRepoURL(gitURL.RepoURL) {
+		return auth
+	}
+	installRoutedTransport.Do(func() {
+		for _, scheme := range []string{"http", "https"} {
+			client.InstallProtocol(scheme, &routedTransport{fallback: client.Protocols[scheme]})
+		}
+	})
+
+	target := g.gitTransport
+	if target == nil {
+		target = defaultGitTransport
+	}
+	return &routedAuth{transport: target, inner: auth}
+}
+
+// routedAuth carries the transport a go-git session is handed to, and the actual
+// authentication of the session
+type routedAuth struct {
+	transport transport.Transport
+	inner     transport.AuthMethod // nil for anonymous sessions
+}
+
+// Name returns the name of the actual authentication method
+func (a *routedAuth) Name() string {
+	if a.inner == nil {
+		return "anonymous"
+	}
+	return a.inner.Name()
+}
+
+// String describes the actual authentication method
+func (a *routedAuth) String() string {
+	if a.inner == nil {
+		return "anonymous"
+	}
+	return a.inner.String()
+}
+
+// SetAuth applies the actual authentication to a request
+func (a *routedAuth) SetAuth(r *gohttp.Request) {
+	if inner, ok := a.inner.(http.AuthMethod); ok {
+		inner.SetAuth(r)
+	}
+}
+
+// routedTransport hands go-git HTTP sessions authenticated with routedAuth to their
+// transport, and every other session to the transport registered before it
+type routedTransport struct {
+	fallback transport.Transport
+}
+
+// route returns the transport and authentication of a session
+func (t *routedTransport) route(auth transport.AuthMethod) (transport.Transport, transport.AuthMethod) {
+	if routed, ok := auth.(*routedAuth); ok {
+		return routed.transport, routed.inner
+	}
+	if t.fallback == nil {
+		return http.DefaultClient, auth
+	}
+	return t.fallback, auth
+}
+
+// NewUploadPackSession starts a fetch session through the session's transport
+func (t *routedTransport) NewUploadPackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	target, auth := t.route(auth)
+	return target.NewUploadPackSession(ep, auth)
+}
+
+// NewReceivePackSession starts a push session through the session's transport
+func (t *routedTransport) NewReceivePackSession(ep *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	target, auth := t.route(auth)
+	return target.NewReceivePackSession(ep, auth)
+}
@@ -0,0 +1,271 @@
+// httptransport_test.go
+//
+// Custom HTTP client and dialer tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestWithDialer tests that Git over HTTP connects through a custom dialer, here to a
+// Unix socket as behind a mesh proxy, while the dialer sees the logical host
+func TestWithDialer(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "meshed"}`})
+
+	// Short socket path, since Unix socket paths are limited to about 100 bytes
+	socketDir, err := os.MkdirTemp("", "mesh")
+	if err != nil {
+		t.Fatalf("Failed to create socket directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "egress.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets not available: %v", err)
+	}
+	server := &http.Server{Handler: repo.httpBackend(), ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	var mu sync.Mutex
+	var dialed []string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, network+" "+addr)
+		mu.Unlock()
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socketPath)
+	}
+
+	provider, err := NewProvider(WithDialer(dial))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+	provider.retryConfig = newNoRetryProvider().retryConfig
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// The logical host does not resolve; only the dialer makes the load possible
+	gitURL := &GitURL{
+		RepoURL:   "http://configs.mesh.invalid/.git",
+		FilePath:  "config.json",
+		Reference: "main",
+	}
+	result, err := provider.loadConfigFromRepo(ctx, gitURL)
+	if err != nil {
+		t.Fatalf("Load through the dialer failed: %v", err)
+	}
+	if result.Config["service"] != "meshed" {
+		t.Errorf("Unexpected config: %v", result.Config)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) == 0 || dialed[0] != "tcp configs.mesh.invalid:80" {
+		t.Errorf("Expected the dialer to receive the logical host, got %v", dialed)
+	}
+
+	// Providers without the option are unaffected by the routing transport
+	if _, err := newNoRetryProvider().loadConfigFromRepo(ctx, gitURL); err == nil {
+		t.Error("Expected a load without the dialer to fail")
+	}
+}
+
+// TestWithHTTPClient tests the custom HTTP client and the validation of both options
+func TestWithHTTPClient(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "api"}`})
+
+	var mu sync.Mutex
+	var requests int
+	backend := repo.httpBackend()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		backend.ServeHTTP(w, r)
+	}), ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", listener.Addr().String())
+		},
+	}}
+	provider, err := NewProvider(WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	gitURL := &GitURL{RepoURL: "http://configs.mesh.invalid/.git", FilePath: "config.json", Reference: "main"}
+	if _, err := provider.loadConfigFromRepo(ctx, gitURL); err != nil {
+		t.Fatalf("Load through the HTTP client failed: %v", err)
+	}
+	mu.Lock()
+	if requests == 0 {
+		t.Error("Expected requests through the custom client")
+	}
+	mu.Unlock()
+
+	// SSRF checks still apply to the logical host of configuration URLs
+	if _, err := provider.Load(ctx, "https://127.0.0.1/org/configs.git#config.json"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR for a private host, got %v", err)
+	}
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) { return nil, net.ErrClosed }
+	invalid := [][]Option{
+		{WithHTTPClient(nil)},
+		{WithDialer(nil)},
+		{WithHTTPClient(client), WithDialer(dial)},
+		{WithHTTPClient(&http.Client{Transport: roundTripperFunc(nil)}), WithHTTPProxy("http://proxy.example.com:3128")},
+	}
+	for i, opts := range invalid {
+		if _, err := NewProvider(opts...); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Options %d: expected ARGUS_INVALID_CONFIG, got %v", i, err)
+		}
+	}
+}
+
+// roundTripperFunc is an http.RoundTripper that is not an *http.Transport
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls the function
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// TestGitRedirectValidation tests that redirects of Git HTTP requests to blocked hosts
+// are refused before they are followed, while redirects within the host still work
+func TestGitRedirectValidation(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "internal"}`})
+
+	// The internal server would serve the repository to anyone who reaches it
+	var internalRequests atomic.Int64
+	backend := repo.httpBackend()
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalRequests.Add(1)
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(internal.Close)
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/internal/"):
+			http.Redirect(w, r, internal.URL+strings.TrimPrefix(r.URL.RequestURI(), "/internal"), http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, "/metadata/"):
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, "/moved/"):
+			http.Redirect(w, r, strings.TrimPrefix(r.URL.RequestURI(), "/moved"), http.StatusMovedPermanently)
+		default:
+			backend.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(redirector.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	load := func(provider *GitProvider, path string) (*LoadResult, error) {
+		return provider.loadConfigFromRepo(ctx, &GitURL{
+			RepoURL:   redirector.URL + path,
+			FilePath:  "config.json",
+			Reference: "main",
+			AuthData:  make(map[string]string),
+		})
+	}
+
+	for _, path := range []string{"/internal/.git", "/metadata/.git"} {
+		if _, err := load(newNoRetryProvider(), path); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+			t.Errorf("Expected ARGUS_SECURITY_ERROR for a redirect through %s, got %v", path, err)
+		}
+	}
+	if n := internalRequests.Load(); n != 0 {
+		t.Errorf("Expected no requests to reach the internal server, got %d", n)
+	}
+
+	// Custom clients get the same checks before their own
+	var customChecks atomic.Int64
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		customChecks.Add(1)
+		return nil
+	}}
+	provider, err := NewProvider(WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+	provider.retryConfig = newNoRetryProvider().retryConfig
+	if _, err := load(provider, "/internal/.git"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR through a custom client, got %v", err)
+	}
+
+	// Redirects within the repository host are followed
+	result, err := load(provider, "/moved/.git")
+	if err != nil {
+		t.Fatalf("Load through a same-host redirect failed: %v", err)
+	}
+	if result.Config["service"] != "internal" || customChecks.Load() == 0 {
+		t.Errorf("Unexpected result %v after %d custom checks", result.Config, customChecks.Load())
+	}
+}
+
+// TestCheckGitRedirect tests the redirect rules
+func TestCheckGitRedirect(t *testing.T) {
+	via := func(target string) []*http.Request {
+		return []*http.Request{httptest.NewRequest(http.MethodGet, target, nil)}
+	}
+	testCases := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{"https://github.com/org/configs.git/info/refs", "https://codeload.github.com/org/configs.git/info/refs", true},
+		{"https://github.com/org/configs.git/info/refs", "https://github.com/org/renamed.git/info/refs", true},
+		{"https://github.com/org/configs.git/info/refs", "http://github.com/org/configs.git/info/refs", false},
+		{"https://github.com/org/configs.git/info/refs", "https://127.0.0.1/org/configs.git/info/refs", false},
+		{"https://github.com/org/configs.git/info/refs", "https://10.0.0.8/org/configs.git/info/refs", false},
+		{"http://git.example.com/configs.git/info/refs", "https://git.example.com/configs.git/info/refs", true},
+	}
+	for _, tc := range testCases {
+		err := checkGitRedirect(httptest.NewRequest(http.MethodGet, tc.to, nil), via(tc.from))
+		if (err == nil) != tc.allowed {
+			t.Errorf("Redirect %s -> %s: expected allowed=%v, got %v", tc.from, tc.to, tc.allowed, err)
+		}
+	}
+
+	chain := via("https://github.com/org/configs.git")
+	for len(chain) < maxGitRedirects {
+		chain = append(chain, chain[0])
+	}
+	if err := checkGitRedirect(httptest.NewRequest(http.MethodGet, "https://github.com/x", nil), chain); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR after %d redirects, got %v", maxGitRedirects, err)
+	}
+}
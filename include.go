@@ -0,0 +1,289 @@
+// include.go: Include directives composing configuration files
+//
+// With WithIncludes(true), configurations can be composed from other files of the
+// same repository and commit. In every format, a mapping with an "$include" key
+// is replaced by the included files merged in order, with the mapping's own keys
+// taking precedence:
+//
+//	{"$include": ["shared/base.json", "shared/logging.json"], "service": "api"}
+//
+// YAML files may use the !include tag instead, which includes a file as a value:
+//
+//	database: !include shared/db.yaml
+//
+// Paths are relative to the including file and must be valid configuration paths,
+// so traversal and sensitive file checks apply. Included files may include others;
+// cycles, including those through symbolic links, and chains deeper than the
+// maximum include depth (WithMaxIncludeDepth, default 10) fail with
+// ARGUS_SECURITY_ERROR. The same file may still be included from several places.
+// Includes are read from the commit tree of the clone the file was loaded from.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// includeKey is the reserved mapping key of include directives
+	includeKey = "$include"
+
+	// includeTag is the YAML tag including a file as a value
+	includeTag = "!include"
+
+	// defaultMaxIncludeDepth bounds chains of files including each other
+	defaultMaxIncludeDepth = 10
+
+	// maxIncludedFiles bounds the files read for one configuration, since a file
+	// included from several places is read each time
+	maxIncludedFiles = 100
+)
+
+// includeResolver resolves the include directives of one configuration
+type includeResolver struct {
+	g     *GitProvider
+	tree  *object.Tree
+	chain []string // Files being resolved with symbolic links resolved, outermost first
+	depth int      // Maximum length of include chains below the loaded file
+	files int      // Included files read so far
+}
+
+// resolveResultIncludes replaces the include directives of a loaded configuration
+// with the included files of the commit it was read from
+func (g *GitProvider) resolveResultIncludes(repo *git.Repository, filePath string, result *LoadResult) error {
+	if !g.options.includes {
+		return nil
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(result.CommitHash))
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read commit for include resolution")
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read tree for include resolution")
+	}
+
+	root, err := resolveTreeSymlinks(tree, filePath)
+	if err != nil {
+		return err
+	}
+
+	r := &includeResolver{g: g, tree: tree, chain: []string{root}, depth: g.options.effectiveMaxIncludeDepth()}
+	resolved, err := r.resolve(result.Config)
+	if err != nil {
+		return err
+	}
+	result.Config = resolved.(map[string]interface{})
+
+	// The disk cache re-parses raw content, which would lose the included files
+	if r.files > 0 {
+		result.content = nil
+	}
+	return nil
+}
+
+// resolve returns value with the include directives in it replaced
+func (r *includeResolver) resolve(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		directive, hasInclude := v[includeKey]
+
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if key == includeKey {
+				continue
+			}
+			item, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = item
+		}
+		if !hasInclude {
+			return resolved, nil
+		}
+
+		paths, err := includePaths(directive)
+		if err != nil {
+			return nil, err
+		}
+		merged := make(map[string]interface{})
+		for _, includePath := range paths {
+			included, err := r.include(includePath)
+			if err != nil {
+				return nil, err
+			}
+			merged = mergeConfig(merged, included)
+		}
+		return mergeConfig(merged, resolved), nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			item, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = item
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// include reads, parses and resolves a file included by the innermost file of the chain
+func (r *includeResolver) include(includePath string) (map[string]interface{}, error) {
+	current := r.chain[len(r.chain)-1]
+	if strings.HasPrefix(includePath, "/") {
+		return nil, errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("absolute include path not allowed in %s: %s", current, includePath))
+	}
+
+	target := path.Join(path.Dir(current), includePath)
+	if err := r.g.validateConfigFilePath(target, ""); err != nil {
+		return nil, err
+	}
+
+	// Files are identified by their resolved path, so links cannot hide a cycle
+	resolvedPath, err := resolveTreeSymlinks(r.tree, target)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedPath != target {
+		if err := r.g.validateConfigFilePath(resolvedPath, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range r.chain {
+		if file == resolvedPath {
+			return nil, errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("include cycle detected: %s -> %s", strings.Join(r.chain, " -> "), resolvedPath))
+		}
+	}
+	if len(r.chain) > r.depth {
+		return nil, errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("includes nested too deeply at %s (max depth %d)", target, r.depth))
+	}
+	r.files++
+	if r.files > maxIncludedFiles {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("too many included files (max %d)", maxIncludedFiles))
+	}
+
+	content, err := r.readFile(target, resolvedPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := r.g.parseConfigFileAs(target, "", content)
+	if err != nil {
+		return nil, err
+	}
+
+	r.chain = append(r.chain, resolvedPath)
+	defer func() { r.chain = r.chain[:len(r.chain)-1] }()
+
+	resolved, err := r.resolve(config)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+// includePaths returns the paths of an include directive: a path or a list of paths
+func includePaths(directive interface{}) ([]string, error) {
+	switch v := directive.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			p, ok := item.(string)
+			if !ok {
+				return nil, errors.New("ARGUS_INVALID_CONFIG",
+					fmt.Sprintf("%s entries must be file paths, got %T", includeKey, item))
+			}
+			paths = append(paths, p)
+		}
+		return paths, nil
+	}
+	return nil, errors.New("ARGUS_INVALID_CONFIG",
+		fmt.Sprintf("%s must be a file path or a list of file paths, got %T", includeKey, directive))
+}
+
+// readFile reads an included file from the tree at the path its symbolic links resolve to
+func (r *includeResolver) readFile(filePath, resolvedPath string) ([]byte, error) {
+	file, err := r.tree.File(resolvedPath)
+	if err != nil {
+		return nil, configNotFoundError(filePath)
+	}
+	if file.Size > maxConfigFileSize {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration file too large: %d bytes (max %d)", file.Size, maxConfigFileSize))
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", fmt.Sprintf("failed to read configuration file: %s", filePath))
+	}
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxConfigFileSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", fmt.Sprintf("failed to read configuration file: %s", filePath))
+	}
+	return content, nil
+}
+
+// mergeConfig merges overlay into base: nested mappings are merged recursively and
+// any other value of overlay replaces the value in base
+func mergeConfig(base, overlay map[string]interface{}) map[string]interface{} {
+	for key, value := range overlay {
+		if overlayMap, ok := value.(map[string]interface{}); ok {
+			if baseMap, ok := base[key].(map[string]interface{}); ok {
+				base[key] = mergeConfig(baseMap, overlayMap)
+				continue
+			}
+		}
+		base[key] = value
+	}
+	return base
+}
+
+// rewriteYAMLIncludes replaces !include tagged nodes with {"$include": value} mappings,
+// so that YAML includes are resolved like the include key of every format
+func rewriteYAMLIncludes(node *yaml.Node) {
+	for _, child := range node.Content {
+		rewriteYAMLIncludes(child)
+	}
+
+	if node.Tag != includeTag || (node.Kind != yaml.ScalarNode && node.Kind != yaml.SequenceNode) {
+		return
+	}
+	value := *node
+	value.Tag = "!!str"
+	if node.Kind == yaml.SequenceNode {
+		value.Tag = "!!seq"
+	}
+	*node = yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: includeKey},
+			&value,
+		},
+	}
+}
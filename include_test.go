@@ -0,0 +1,161 @@
+// include_test.go
+//
+// Include directive tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestWithIncludes tests that include directives are resolved from the same commit
+func TestWithIncludes(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"app.yaml":            "service: api\ndatabase: !include shared/db.yaml\n",
+		"app.json":            `{"$include": ["shared/base.json", "shared/logging.json"], "replicas": 3, "logging": {"level": "debug"}}`,
+		"shared/base.json":    `{"replicas": 1, "region": "eu-west-1"}`,
+		"shared/logging.json": `{"$include": "format.json", "logging": {"level": "info"}}`,
+		"shared/format.json":  `{"logging": {"format": "json"}}`,
+		"shared/db.yaml":      "host: db.internal\nport: 5432\n",
+		"cycle/a.json":        `{"$include": "b.json"}`,
+		"cycle/b.json":        `{"$include": "a.json"}`,
+		"escape.json":         `{"$include": "../outside.json"}`,
+		"absolute.json":       `{"$include": "/etc/app.json"}`,
+		"invalid.json":        `{"$include": 42}`,
+	})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true), WithIncludes(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#"
+
+	t.Run("YAMLTag", func(t *testing.T) {
+		config, err := provider.Load(ctx, baseURL+"app.yaml")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		database, ok := config["database"].(map[string]interface{})
+		if !ok || database["host"] != "db.internal" || database["port"] != 5432 {
+			t.Errorf("Expected the included database config, got %v", config)
+		}
+	})
+
+	t.Run("IncludeKey", func(t *testing.T) {
+		config, err := provider.Load(ctx, baseURL+"app.json")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if _, ok := config[includeKey]; ok {
+			t.Errorf("Expected the directive to be removed, got %v", config)
+		}
+		// Later includes override earlier ones, and local keys override includes
+		if config["replicas"] != float64(3) || config["region"] != "eu-west-1" {
+			t.Errorf("Unexpected merged config: %v", config)
+		}
+		logging, ok := config["logging"].(map[string]interface{})
+		if !ok || logging["level"] != "debug" || logging["format"] != "json" {
+			t.Errorf("Expected nested includes to be merged, got %v", config["logging"])
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		testCases := []struct {
+			file string
+			code errors.ErrorCode
+		}{
+			{"cycle/a.json", "ARGUS_SECURITY_ERROR"},
+			{"escape.json", "ARGUS_SECURITY_ERROR"},
+			{"absolute.json", "ARGUS_SECURITY_ERROR"},
+			{"invalid.json", "ARGUS_INVALID_CONFIG"},
+		}
+		for _, tc := range testCases {
+			if _, err := provider.Load(ctx, baseURL+tc.file); !errors.HasCode(err, tc.code) {
+				t.Errorf("Load of %s: expected %s, got %v", tc.file, tc.code, err)
+			}
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		plain, err := NewProvider(WithAllowLocalRepos(true))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		defer func() { _ = plain.Close() }()
+
+		config, err := plain.Load(ctx, baseURL+"app.yaml")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if config["database"] != "shared/db.yaml" {
+			t.Errorf("Expected the tag to be loaded as a plain value, got %v", config["database"])
+		}
+	})
+}
+
+// TestWithMaxIncludeDepth tests the include depth limit and cycles through symbolic links
+func TestWithMaxIncludeDepth(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"chain/0.json": `{"$include": "1.json", "level": 0}`,
+		"chain/1.json": `{"$include": "2.json", "level": 1}`,
+		"chain/2.json": `{"$include": "3.json", "level": 2}`,
+		"chain/3.json": `{"level": 3, "deepest": true}`,
+		"loop/a.json":  `{"$include": "../linked.json"}`,
+	})
+	repo.symlink("link into loop", "linked.json", "loop/a.json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#"
+	load := func(t *testing.T, file string, opts ...Option) (map[string]interface{}, error) {
+		t.Helper()
+		provider, err := NewProvider(append([]Option{WithAllowLocalRepos(true), WithIncludes(true)}, opts...)...)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		defer func() { _ = provider.Close() }()
+		return provider.Load(ctx, baseURL+file)
+	}
+
+	// The default depth allows three nested includes
+	config, err := load(t, "chain/0.json")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config["level"] != float64(0) || config["deepest"] != true {
+		t.Errorf("Unexpected config: %v", config)
+	}
+
+	if _, err := load(t, "chain/0.json", WithMaxIncludeDepth(3)); err != nil {
+		t.Errorf("Expected a chain of depth 3 to load, got %v", err)
+	}
+	if _, err := load(t, "chain/0.json", WithMaxIncludeDepth(2)); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR for a chain deeper than 2, got %v", err)
+	}
+	if _, err := load(t, "chain/2.json", WithMaxIncludeDepth(1)); err != nil {
+		t.Errorf("Expected a single include to load at depth 1, got %v", err)
+	}
+
+	// A file including itself through a symbolic link is a cycle
+	if _, err := load(t, "loop/a.json"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR for a cycle through a link, got %v", err)
+	}
+
+	if _, err := NewProvider(WithMaxIncludeDepth(0)); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for depth 0, got %v", err)
+	}
+}
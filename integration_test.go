@@ -11,6 +11,7 @@ package git
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -326,3 +327,54 @@ func TestGitProvider_MemoryUsage(t *testing.T) {
 		t.Logf("Provider still functional after multiple operations: %v", err)
 	}
 }
+
+// TestGitProvider_IntegrationListConfigs lists the testdata configs of this repository
+func TestGitProvider_IntegrationListConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	provider := GetProvider().(*GitProvider)
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	configs, err := provider.ListConfigs(ctx, "https://github.com/agilira/argus-provider-git.git", "main")
+	if err != nil {
+		t.Fatalf("Failed to list configs: %v", err)
+	}
+
+	found := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		found[config] = true
+	}
+
+	for _, expected := range []string{"testdata/config.json", "testdata/config.yaml", "testdata/config.toml"} {
+		if !found[expected] {
+			t.Errorf("Expected %s in listed configs, got %v", expected, configs)
+		}
+	}
+}
+
+// TestGitProvider_IntegrationListRefs tests listing the branches and tags of this repository
+func TestGitProvider_IntegrationListRefs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	provider := GetProvider().(*GitProvider)
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	branches, _, err := provider.ListRefs(ctx, "https://github.com/agilira/argus-provider-git.git")
+	if err != nil {
+		t.Fatalf("Failed to list refs: %v", err)
+	}
+
+	if !slices.Contains(branches, "main") {
+		t.Errorf("Expected main among branches, got %v", branches)
+	}
+}
@@ -0,0 +1,76 @@
+// logging.go: Optional structured diagnostic logging
+//
+// With WithLogger, the provider reports what it is doing (references resolved,
+// cache hits and misses, clones, retries, mirror fallbacks and watch changes) to
+// a log/slog logger. Without a logger nothing is logged. Repository URLs and
+// error messages are redacted before they are logged, so credentials never
+// reach the log.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+var (
+	// logUserinfoPattern matches the user info of a URL, e.g. "https://token@host"
+	logUserinfoPattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*)://([^/@\s]+)@`)
+
+	// logSecretParamPattern matches credential-carrying URL parameters
+	logSecretParamPattern = regexp.MustCompile(`(?i)\b(auth|token|password|passphrase|header_value)=[^&#\s]+`)
+)
+
+// logEvent logs a diagnostic event if a logger is configured and enabled for the level
+func (g *GitProvider) logEvent(ctx context.Context, level slog.Level, msg string, args ...any) {
+	logger := g.options.logger
+	if logger == nil || !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, msg, args...)
+}
+
+// logURLAttrs returns the log attributes identifying the configuration of a URL
+func logURLAttrs(gitURL *GitURL) []any {
+	attrs := []any{slog.String("repo", redactLogText(gitURL.RepoURL))}
+	if gitURL.FilePath != "" {
+		attrs = append(attrs, slog.String("file", gitURL.FilePath))
+	}
+	if gitURL.Blob != "" {
+		attrs = append(attrs, slog.String("blob", gitURL.Blob))
+	}
+	if gitURL.Reference != "" {
+		attrs = append(attrs, slog.String("ref", gitURL.Reference))
+	}
+	return attrs
+}
+
+// logErrorAttr returns the redacted log attribute of an error
+func logErrorAttr(err error) slog.Attr {
+	return slog.String("error", redactLogText(err.Error()))
+}
+
+// redactLogText masks credentials in URL user info and URL parameters of text.
+//
+// SECURITY: SSH user names such as "git@" are kept, but user info of any other
+// scheme, and SSH user info with a password, may hold a token and is masked.
+func redactLogText(text string) string {
+	text = logUserinfoPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := logUserinfoPattern.FindStringSubmatch(match)
+		if strings.EqualFold(parts[1], "ssh") && !strings.Contains(parts[2], ":") {
+			return match
+		}
+		return parts[1] + "://" + maskedAuthValue + "@"
+	})
+
+	return logSecretParamPattern.ReplaceAllStringFunc(text, func(match string) string {
+		key, _, _ := strings.Cut(match, "=")
+		return key + "=" + maskedAuthValue
+	})
+}
@@ -30,13 +30,26 @@
 package git
 
 import (
+	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
+	"net"
+	gohttp "net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -46,12 +59,12 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
-	toml "github.com/pelletier/go-toml/v2"
-	"gopkg.in/yaml.v3"
 )
 
 // Security and resource limit constants for DoS prevention
@@ -62,6 +75,12 @@ const (
 	// Default timeout for Git operations (60 seconds)
 	defaultGitTimeout = 60 * time.Second
 
+	// Default timeout of a remote reference listing (git ls-remote)
+	defaultLsRemoteTimeout = 15 * time.Second
+
+	// Default timeout of a health check clone
+	defaultHealthCheckTimeout = 10 * time.Second
+
 	// Maximum concurrent clone/fetch operations
 	maxConcurrentOperations = 10
 
@@ -83,9 +102,76 @@ const (
 	// Maximum path length to prevent DoS
 	maxPathLength = 1024
 
+	// Maximum number of configuration files reported by ListConfigs
+	maxListedConfigs = 10000
+
+	// Maximum number of configuration files loaded together by LoadFiles
+	maxLoadFiles = 100
+
+	// Maximum nesting depth of maps and lists in a parsed configuration
+	maxConfigDepth = 100
+
+	// Maximum YAML nodes after alias expansion; an alias-free document of at most
+	// maxConfigFileSize bytes cannot exceed it, so only expansion bombs are rejected
+	maxYAMLExpandedNodes = maxConfigFileSize
+
+	// Maximum symbolic links followed while resolving a configuration file path
+	maxSymlinkHops = 8
+
+	// Maximum number of fallback references per configuration URL
+	maxFallbackRefs = 5
+
+	// Maximum number of mirror repositories per configuration URL
+	maxMirrors = 5
+
+	// Maximum time Close waits for in-flight operations before releasing resources
+	closeDrainTimeout = 10 * time.Second
+
+	// Age after which an unmodified clone directory is considered orphaned
+	staleTempDirAge = time.Hour
+
+	// Removal attempts for a clone directory on Windows, where files still held open
+	// (e.g. by antivirus scanners or indexers) cannot be deleted
+	tempDirRemoveAttempts = 5
+
+	// Delay before the first repeated removal attempt, doubled on each further one
+	tempDirRemoveBaseDelay = 50 * time.Millisecond
+
+	// Default history depth of clones; older commits are fetched on demand
+	defaultCloneDepth = 1
+
+	// Factor a shallow clone is deepened by while a requested commit is missing
+	cloneDeepenFactor = 8
+
+	// Depth beyond which a shallow clone is unshallowed instead of deepened further
+	maxCloneDeepenDepth = 512
+
+	// Fetch depth that unshallows a clone, as used by git fetch --unshallow
+	unshallowDepth = 0x7fffffff
+
 	// Maximum URL length to prevent DoS
 	maxURLLength = 2048
 
+	// Maximum query parameters of a URL, base query and fragment combined, and the
+	// maximum length of one parameter; the fragment is not covered by maxURLLength
+	maxQueryParams      = 64
+	maxQueryParamLength = maxURLLength
+
+	// Maximum length of a reference name
+	maxRefLength = 256
+
+	// Maximum length of a remote repository path; hosting services allow far less
+	maxRepoPathLength = 512
+
+	// Maximum percent-decoding rounds applied when canonicalizing paths
+	maxPathDecodeRounds = 4
+
+	// Username Bitbucket Cloud expects for repository/workspace access tokens
+	bitbucketTokenUsername = "x-token-auth"
+
+	// SSH username used when neither the repository URL nor WithSSHUser names one
+	defaultSSHUser = "git"
+
 	// Retry configuration constants
 	defaultMaxRetries = 3
 	defaultRetryDelay = time.Second
@@ -98,6 +184,7 @@ type retryConfig struct {
 	baseDelay     time.Duration // Base delay between retries
 	maxDelay      time.Duration // Maximum delay between retries
 	backoffFactor float64       // Exponential backoff multiplier
+	budget        time.Duration // Maximum total time for an operation and its retries; zero means unlimited
 }
 
 // defaultRetryConfig returns a sensible default retry configuration
@@ -163,6 +250,59 @@ func validateSecureGitURL(gitURL string) (*url.URL, error) {
 	return parsedURL, nil
 }
 
+// isLocalRepoURL reports whether a base URL refers to a repository on the local filesystem
+func isLocalRepoURL(baseURL string) bool {
+	return strings.HasPrefix(strings.ToLower(baseURL), "file://") || filepath.IsAbs(baseURL)
+}
+
+// validateLocalRepoURL validates a file:// URL or absolute path to a local repository.
+//
+// SECURITY: Local repositories are only accepted with WithAllowLocalRepos. The path
+// must be absolute and free of parent segments and control characters, and file://
+// URLs may not name a remote host.
+func validateLocalRepoURL(baseURL string) (*url.URL, error) {
+	if len(baseURL) > maxURLLength {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("git URL too long: %d bytes (max %d)", len(baseURL), maxURLLength))
+	}
+
+	var parsedURL *url.URL
+	if strings.HasPrefix(strings.ToLower(baseURL), "file://") {
+		var err error
+		if parsedURL, err = url.Parse(baseURL); err != nil {
+			return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid git URL format")
+		}
+		if parsedURL.Host != "" && !strings.EqualFold(parsedURL.Host, "localhost") {
+			return nil, errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("file URL must not name a remote host: %s", parsedURL.Host))
+		}
+	} else {
+		// Absolute paths are taken verbatim; only the query string is split off
+		localPath, rawQuery, _ := strings.Cut(baseURL, "?")
+		parsedURL = &url.URL{Path: filepath.ToSlash(localPath), RawQuery: rawQuery}
+	}
+	parsedURL.Scheme = "file"
+	parsedURL.Host = ""
+
+	if parsedURL.Path == "" || !filepath.IsAbs(filepath.FromSlash(parsedURL.Path)) {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("local repository path must be absolute: %q", parsedURL.Path))
+	}
+
+	for _, r := range parsedURL.Path {
+		if r < 32 || r == 0x7f {
+			return nil, errors.New("ARGUS_SECURITY_ERROR", "control character in local repository path")
+		}
+	}
+
+	// SECURITY: Canonical check - no path segment may climb a directory
+	if hasParentSegment(parsedURL.Path) {
+		return nil, errors.New("ARGUS_SECURITY_ERROR", "dangerous path traversal pattern detected: ..")
+	}
+
+	return parsedURL, nil
+}
+
 // validateGitHost validates the host part of Git URLs to prevent SSRF attacks.
 func validateGitHost(host string) error {
 	if host == "" {
@@ -251,10 +391,21 @@ func validateRepositoryPath(path string) error {
 		return errors.New("ARGUS_INVALID_CONFIG", "git repository path cannot be empty")
 	}
 
-	// SECURITY: URL decode to detect encoded path traversal
-	decodedPath := path
-	if decoded, err := url.QueryUnescape(path); err == nil {
-		decodedPath = decoded
+	// SECURITY: Bound the path before decoding it, and before it reaches go-git
+	if len(path) > maxRepoPathLength {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("git repository path too long: %d bytes (max %d)", len(path), maxRepoPathLength))
+	}
+
+	// SECURITY: Fully decode (all encoding layers) to detect encoded path traversal
+	decodedPath, err := decodePathFully(path)
+	if err != nil {
+		return err
+	}
+
+	// SECURITY: Canonical check - no decoded path segment may climb a directory
+	if hasParentSegment(decodedPath) {
+		return errors.New("ARGUS_SECURITY_ERROR", "dangerous path traversal pattern detected: ..")
 	}
 
 	// SECURITY: Detect path traversal patterns (both original and decoded)
@@ -294,8 +445,97 @@ func validateRepositoryPath(path string) error {
 	return nil
 }
 
-// validateConfigFilePath validates configuration file paths within repositories.
+// decodePathFully percent-decodes a path until it no longer changes and normalizes
+// Windows separators to "/", so traversal sequences cannot hide behind multiple
+// encoding layers (e.g. %252e%252e). Decoding is bounded to prevent DoS.
+func decodePathFully(p string) (string, error) {
+	decoded := p
+	for round := 0; round < maxPathDecodeRounds; round++ {
+		next := percentDecodeLenient(decoded)
+		if next == decoded {
+			return strings.ReplaceAll(decoded, "\\", "/"), nil
+		}
+		decoded = next
+	}
+
+	return "", errors.New("ARGUS_SECURITY_ERROR",
+		fmt.Sprintf("path has too many percent-encoding layers (max %d)", maxPathDecodeRounds))
+}
+
+// percentDecodeLenient decodes every valid %XX escape and keeps malformed escapes
+// as literal text, so a single bad escape cannot shield the rest of the path.
+func percentDecodeLenient(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHexDigit(s[i+1]) && isHexDigit(s[i+2]) {
+			b.WriteByte(unhexDigit(s[i+1])<<4 | unhexDigit(s[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhexDigit(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// hasParentSegment reports whether any "/"-separated segment of the path is ".."
+func hasParentSegment(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConfigFilePath validates configuration file paths within repositories
+// using the default allowed extensions.
 func validateConfigFilePath(filePath string) error {
+	return validateConfigFilePathWith(filePath, defaultConfigExtensions())
+}
+
+// defaultConfigExtensions returns the extensions accepted when no allow-list is configured:
+// every extension with a registered decoder plus formats recognized for validation only.
+func defaultConfigExtensions() []string {
+	return append(registeredExtensions(), ".hcl", ".ini", ".properties")
+}
+
+// validateConfigFilePathWith validates configuration file paths within repositories
+// against the default sensitive paths, accepting only files ending in one of
+// allowedExtensions (lowercase, with leading dot). A nil allowedExtensions accepts any
+// extension, for files parsed with an explicit format.
+func validateConfigFilePathWith(filePath string, allowedExtensions []string) error {
+	return validateConfigPath(filePath, allowedExtensions, defaultSensitivePaths)
+}
+
+// validateConfigFilePath validates a configuration file path with the extensions
+// allowed for format and the sensitive paths of this provider
+func (g *GitProvider) validateConfigFilePath(filePath string, format Format) error {
+	return validateConfigPath(filePath, g.extensionsFor(format), g.sensitivePaths())
+}
+
+// validateConfigPath validates a configuration file path, rejecting paths with a
+// segment matching one of sensitivePaths (lowercase path.Match patterns)
+func validateConfigPath(filePath string, allowedExtensions, sensitivePaths []string) error {
 	if filePath == "" {
 		return errors.New("ARGUS_INVALID_CONFIG", "configuration file path cannot be empty")
 	}
@@ -306,8 +546,15 @@ func validateConfigFilePath(filePath string) error {
 			fmt.Sprintf("config file path too long: %d bytes (max %d)", len(filePath), maxPathLength))
 	}
 
+	// SECURITY: Fully decode (all encoding layers) and normalize separators so that
+	// encoded traversal sequences are checked in their effective form
+	decodedPath, err := decodePathFully(filePath)
+	if err != nil {
+		return err
+	}
+
 	// SECURITY: Detect null bytes and control characters
-	for i, b := range []byte(filePath) {
+	for i, b := range []byte(decodedPath) {
 		if b == 0 {
 			return errors.New("ARGUS_SECURITY_ERROR", "null byte in file path not allowed")
 		}
@@ -318,28 +565,23 @@ func validateConfigFilePath(filePath string) error {
 	}
 
 	// SECURITY: Block absolute paths
-	if filepath.IsAbs(filePath) || strings.HasPrefix(filePath, "/") ||
-		(len(filePath) > 1 && filePath[1] == ':') { // Windows drive letters
+	if filepath.IsAbs(filePath) || strings.HasPrefix(decodedPath, "/") ||
+		(len(decodedPath) > 1 && decodedPath[1] == ':') { // Windows drive letters
 		return errors.New("ARGUS_SECURITY_ERROR",
 			fmt.Sprintf("absolute paths not allowed: %s", filePath))
 	}
 
-	// SECURITY: Block path traversal attempts
-	pathTraversalPatterns := []string{
-		"..", "/../", "\\..\\", "./", ".\\",
-		"../", "..\\", "./..", ".\\..",
-	}
-	for _, pattern := range pathTraversalPatterns {
-		if strings.Contains(filePath, pattern) {
-			return errors.New("ARGUS_SECURITY_ERROR",
-				fmt.Sprintf("path traversal attempt detected: %s", pattern))
-		}
+	// SECURITY: Canonical check - the cleaned path must stay within the repository root.
+	// Cleaning resolves "." and ".." segments, so only paths that climb above the root
+	// are rejected, while harmless forms like "./config.json" or "conf/v1.2/app.json" pass.
+	if cleaned := path.Clean(decodedPath); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("path traversal attempt detected: %s escapes repository root", filePath))
 	}
 
-	// SECURITY: Validate file extension (must be a config file)
-	allowedExtensions := []string{".json", ".yaml", ".yml", ".toml", ".hcl", ".ini", ".properties"}
+	// SECURITY: Validate file extension (must be a config file, optionally gzip-compressed)
 	hasValidExtension := false
-	lowerPath := strings.ToLower(filePath)
+	lowerPath, _ := uncompressedPath(strings.ToLower(filePath))
 
 	for _, ext := range allowedExtensions {
 		if strings.HasSuffix(lowerPath, ext) {
@@ -348,25 +590,50 @@ func validateConfigFilePath(filePath string) error {
 		}
 	}
 
-	if !hasValidExtension {
+	if !hasValidExtension && allowedExtensions != nil {
 		return errors.New("ARGUS_INVALID_CONFIG",
 			fmt.Sprintf("unsupported config file extension (allowed: %v)", allowedExtensions))
 	}
 
-	// SECURITY: Prevent access to sensitive files
-	sensitivePaths := []string{
-		".git/", ".ssh/", ".env", "passwd", "shadow", "id_rsa", "id_dsa",
-		"config.key", "private.key", "secret", "token",
+	// SECURITY: Prevent access to sensitive files, matching whole segments of the decoded
+	// path so that e.g. ".git/config" is blocked while ".config/app.yaml" is not
+	if sensitive, ok := matchSensitivePath(decodedPath, sensitivePaths); ok {
+		return errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("access to sensitive file not allowed: %s", sensitive))
 	}
 
-	for _, sensitive := range sensitivePaths {
-		if strings.Contains(lowerPath, sensitive) {
-			return errors.New("ARGUS_SECURITY_ERROR",
-				fmt.Sprintf("access to sensitive file not allowed: %s", sensitive))
-		}
+	return nil
+}
+
+// defaultSensitivePaths are the path segment patterns rejected in configuration file
+// paths by default: VCS metadata, credential directories, environment files, system
+// account databases, private keys and files named after secrets or tokens
+var defaultSensitivePaths = []string{
+	".git", ".ssh", ".gnupg", ".aws", ".env", ".env.*", "*.env",
+	"passwd", "passwd.*", "shadow", "shadow.*",
+	"id_rsa*", "id_dsa*", "id_ecdsa*", "id_ed25519*", "*.key", "*secret*", "*token*",
+}
+
+// matchSensitivePath returns the first pattern matching a segment of the path, with
+// the final segment also matched without a compression extension. A ".git" segment
+// always matches, since repository metadata is never configuration.
+func matchSensitivePath(decodedPath string, patterns []string) (string, bool) {
+	segments := strings.Split(path.Clean(strings.ToLower(decodedPath)), "/")
+	if last, ok := uncompressedPath(segments[len(segments)-1]); ok {
+		segments = append(segments, last)
 	}
 
-	return nil
+	for _, segment := range segments {
+		if segment == ".git" {
+			return ".git", true
+		}
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, segment); matched {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
 }
 
 // RemoteConfigProvider defines the interface for remote configuration sources.
@@ -399,19 +666,29 @@ type RemoteConfigProvider interface {
 	HealthCheck(ctx context.Context, configURL string) error
 }
 
+// ClosableProvider is implemented by providers holding resources that Close releases.
+// IsClosed lets holders of the interface check a provider before using it, e.g. to
+// hand over to a replacement provider, instead of handling ARGUS_PROVIDER_CLOSED.
+type ClosableProvider interface {
+	io.Closer
+
+	// IsClosed reports whether Close has been called
+	IsClosed() bool
+}
+
 // GitProvider implements RemoteConfigProvider for Git repositories
 //
 // This provider supports:
 // - Loading JSON/YAML/TOML configurations from Git repositories
 // - Public and private repositories (GitHub, GitLab, self-hosted)
-// - Multiple authentication methods (HTTP basic auth, tokens, SSH keys)
+// - Multiple authentication methods (HTTP basic auth, tokens, bearer tokens, SSH keys)
 // - Polling-based watching for configuration updates
 // - Branch, tag, and commit-specific configuration loading
 // - Secure validation against malicious repositories and paths
 //
 // URL Format Examples:
 //
-//	git://github.com/user/repo.git/config/app.json?ref=main&auth=token:ghp_xxx
+//	git://git-mirror.internal/user/repo.git#config/app.json?ref=main
 //	https://gitlab.com/user/repo.git/configs/prod.yaml?ref=v1.0.0&auth=basic:MYUSER:MYPASS
 //	ssh://git@bitbucket.org/user/repo.git/config.json?ref=develop&key=/path/to/key
 //	git+ssh://custom-git.example.com/repo.git/app.toml?ref=feature-branch
@@ -419,6 +696,7 @@ type GitProvider struct {
 	// Thread-safe operation counting for resource management
 	operationCount int64 // Current number of active operations
 	watchCount     int64 // Current number of active watches
+	inFlight       int64 // Operations and watch loads in progress, drained by Close
 
 	// Provider state management
 	closed int64 // Atomic flag indicating if provider is closed (1 = closed)
@@ -435,6 +713,23 @@ type GitProvider struct {
 	repoCacheMutex sync.RWMutex
 	repoCache      map[string]*repoMetadata // Cached repository information
 
+	// Commits references were pinned to (see WithPinResolvedRef)
+	refPinsMutex sync.RWMutex
+	refPins      map[string]string
+
+	// Credentials registered per repository URL (see SetCredential)
+	credentialsMutex sync.RWMutex
+	credentials      map[string]registeredCredential
+	hostCredentials  map[string]registeredCredential // Keyed by host name (see SetHostCredential)
+
+	// URL defaults registered per repository URL (see RegisterRepoDefaults)
+	repoDefaultsMutex sync.RWMutex
+	repoDefaults      map[string]RepoDefaults
+
+	// Active watches reported by HealthAll
+	watchedMutex sync.Mutex
+	watched      map[*GitURL]*watchedConfig
+
 	// Configuration cache for smart caching
 	configCache *configCache
 
@@ -443,6 +738,15 @@ type GitProvider struct {
 
 	// Metrics collection
 	metrics *gitProviderMetrics
+
+	// HTTP client of releases API requests for release asset URLs
+	releaseClient *gohttp.Client
+
+	// go-git transport of HTTP(S) sessions with a custom client or dialer; nil uses go-git's
+	gitTransport transport.Transport
+
+	// Optional behavior configured through NewProvider (zero value = defaults)
+	options providerOptions
 }
 
 // gitProviderMetrics contains metrics for monitoring provider performance
@@ -452,6 +756,7 @@ type gitProviderMetrics struct {
 	watchRequests    int64 // Total Watch() calls
 	cacheHits        int64 // Cache hits
 	cacheMisses      int64 // Cache misses
+	blobCacheHits    int64 // Cache misses served by the parse of an identical file blob
 	retryAttempts    int64 // Total retry attempts
 	failedOperations int64 // Failed operations
 
@@ -469,6 +774,25 @@ type gitProviderMetrics struct {
 	authErrors    int64 // Authentication errors
 	parseErrors   int64 // Configuration parsing errors
 	gitErrors     int64 // Git operation errors
+
+	// Remote traffic counters
+	remoteRefLookups int64 // Remote reference listings (git ls-remote)
+
+	// Retry termination counters
+	retryBudgetStops  int64 // Retries stopped by the retry budget or context deadline
+	retryAttemptStops int64 // Retries stopped by the maximum attempt count
+
+	// Failure mode counters
+	retryExhausted           int64 // Operations that failed after exhausting their retries
+	resourceLimitHits        int64 // Requests rejected by a resource limit
+	providerClosedRejections int64 // Requests rejected because the provider is closed
+	staleServes              int64 // Stale configurations served because the remote was unreachable
+	skippedFiles             int64 // Files left out of tolerant multi-file loads because they failed
+	invalidWatchLoads        int64 // Watch loads of configurations that failed to parse or validate
+
+	// Mirror usage, keyed by mirror repository URL
+	mirrorMutex  sync.Mutex
+	mirrorServes map[string]int64 // Operations served by a mirror because the primary was unreachable
 }
 
 // newGitProviderMetrics creates a new metrics collection
@@ -485,9 +809,13 @@ type repoMetadata struct {
 // configCacheEntry represents a cached configuration with its metadata
 type configCacheEntry struct {
 	Config      map[string]interface{} // Cached configuration data
+	Format      Format                 // Format the configuration was parsed as
+	Size        int                    // Size of the raw file content in bytes
 	CommitHash  string                 // Commit hash this config corresponds to
+	Trailers    Trailers               // Trailers of the commit message
 	CachedAt    time.Time              // When this config was cached
 	AccessCount int64                  // Number of times this cache entry was accessed
+	Pinned      bool                   // Content of a commit-pinned reference; immutable, never expires
 }
 
 // configCache provides intelligent caching for loaded configurations
@@ -496,16 +824,97 @@ type configCache struct {
 	mutex   sync.RWMutex                 // Protects the cache map
 	maxSize int                          // Maximum number of cache entries
 	ttl     time.Duration                // Cache time-to-live
+
+	// lastGood holds the most recent successful load per repository, file and reference.
+	// It is only used with WithStaleFallback and is not subject to the TTL.
+	lastGood map[string]*configCacheEntry
+
+	// blobs holds configurations by the blob of their file, shared by the commits
+	// that leave the file unchanged. Blobs are immutable, so entries do not expire.
+	blobs map[string]*configCacheEntry
 }
 
 // GitURL represents a parsed Git configuration URL
 type GitURL struct {
-	RepoURL      string            // Base repository URL
-	FilePath     string            // Path to configuration file within repo
-	Reference    string            // Git reference (branch, tag, commit)
-	AuthType     string            // Authentication type (token, basic, key)
-	AuthData     map[string]string // Authentication data
-	PollInterval time.Duration     // Custom polling interval for watch
+	RepoURL       string            // Base repository URL
+	FilePath      string            // Path to configuration file within repo
+	Reference     string            // Git reference (branch, tag, commit)
+	FallbackRefs  []string          // References tried in order when Reference cannot be loaded
+	Mirrors       []string          // Mirror repository URLs tried in order when RepoURL is unreachable
+	TagConstraint string            // Semantic version constraint resolved to the highest matching tag
+	Release       string            // Release tag (or "latest") whose asset named FilePath is loaded
+	Blob          string            // Object hash of a blob read instead of FilePath at Reference
+	Format        Format            // Explicit format overriding detection from the file extension; required with Blob
+	AuthType      string            // Authentication type (token, basic, key)
+	AuthData      map[string]string // Authentication data
+	PollInterval  time.Duration     // Custom polling interval for watch
+	SkipInitial   bool              // Watches deliver changes only, not the initial configuration
+
+	authOverride bool   // Authentication comes from a request context override
+	cacheScope   string // Partitions cached configurations by override credentials
+	requestScope string // Partitions cached configurations by the request's WithCacheScope
+	credentialID string // Fingerprint of credentials registered with SetCredential
+	anonymous    bool   // Neither URL nor registered credentials are used
+}
+
+// ParsedConfig describes how a configuration URL is interpreted, as returned by ParseURL.
+// It mirrors GitURL with authentication secrets masked, for debugging URLs.
+type ParsedConfig struct {
+	RepoURL       string            // Repository URL used for Git operations
+	FilePath      string            // Path to configuration file within repo
+	Reference     string            // Git reference (branch, tag, commit)
+	FallbackRefs  []string          // References tried in order when Reference cannot be loaded
+	Mirrors       []string          // Mirror repository URLs tried in order when RepoURL is unreachable
+	TagConstraint string            // Semantic version constraint resolved to the highest matching tag
+	Release       string            // Release tag (or "latest") whose asset named FilePath is loaded
+	Blob          string            // Object hash of a blob read instead of FilePath at Reference
+	Pinned        bool              // Reference is a full commit hash, or Blob is set: content is immutable
+	Format        Format            // Format the file will be parsed as (empty if no decoder is registered)
+	AuthType      string            // Authentication type (token, bearer, basic, header, ssh)
+	AuthData      map[string]string // Authentication data with secret values masked
+	PollInterval  time.Duration     // Polling interval for watch
+	SkipInitial   bool              // Watches deliver changes only, not the initial configuration
+}
+
+// maskedAuthValue replaces secret authentication values in ParsedConfig
+const maskedAuthValue = "*******"
+
+// secretAuthKeys are the GitURL.AuthData keys that hold secrets
+var secretAuthKeys = map[string]bool{
+	"token": true, "password": true, "header_value": true, "passphrase": true,
+}
+
+// Format identifies the serialization format of a configuration file
+type Format string
+
+// Supported configuration formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// LoadResult is the detailed outcome of LoadDetailed.
+//
+// Config holds the values with the Go types of the decoder of its format, whether it
+// was freshly parsed or served from a cache: JSON numbers are float64 (json.Number
+// with WithJSONUseNumber), YAML integers int, TOML integers int64, and YAML and TOML
+// timestamps time.Time, with TOML local dates and times as go-toml's Local types.
+type LoadResult struct {
+	Config     map[string]interface{} // Parsed configuration
+	Format     Format                 // Format the file was parsed as
+	CommitHash string                 // Commit the configuration was read from
+	Reference  string                 // Reference that served the configuration (may be a fallback)
+	Size       int                    // Size of the raw file content in bytes
+	Trailers   Trailers               // Trailers of the commit message; nil for releases and raw downloads
+
+	// Stale is set when the remote was unreachable and the last-known-good
+	// configuration was served instead (see WithStaleFallback)
+	Stale    bool
+	LoadedAt time.Time // When a stale configuration was originally loaded
+
+	content  []byte // Raw file content, retained only for the disk cache
+	cacheHit bool   // Served from the configuration cache, as reported in audit records
 }
 
 // Name returns the human-readable name of this provider
@@ -520,6 +929,18 @@ func (g *GitProvider) Scheme() string {
 
 // parseGitURL parses and validates a Git configuration URL
 func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
+	return g.parseGitURLWith(configURL, true)
+}
+
+// parseGitURLWith parses and validates a Git URL. When requireFile is false the
+// configuration file path may be omitted, e.g. for repository-level operations.
+func (g *GitProvider) parseGitURLWith(configURL string, requireFile bool) (*GitURL, error) {
+	return g.parseGitURLFile(configURL, requireFile, false)
+}
+
+// parseGitURLFile parses and validates a Git URL like parseGitURLWith. With rawFile,
+// the file path is validated as a raw file, which may have any extension.
+func (g *GitProvider) parseGitURLFile(configURL string, requireFile, rawFile bool) (*GitURL, error) {
 	// Manual parsing to handle Git-style URLs with fragments containing queries
 	// Parse URL like: https://github.com/user/repo.git#config.json?ref=main&auth=token:xxx
 
@@ -535,28 +956,41 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		fragmentPart = ""
 	}
 
-	// Validate the base URL
-	parsedURL, err := validateSecureGitURL(baseURL)
+	// Validate the base URL; local repositories require an explicit opt-in
+	local := g.options.allowLocalRepos && isLocalRepoURL(baseURL)
+	validate := validateSecureGitURL
+	if local {
+		validate = validateLocalRepoURL
+	}
+	parsedURL, err := validate(baseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build repository URL preserving user info for SSH
 	var repoURL string
-	if parsedURL.User != nil {
-		repoURL = fmt.Sprintf("%s://%s@%s%s", parsedURL.Scheme, parsedURL.User.Username(), parsedURL.Host, parsedURL.Path)
-	} else {
-		repoURL = fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, parsedURL.Path)
+	switch {
+	case local:
+		// Local repositories are used as-is, without a ".git" suffix
+		repoURL = "file://" + strings.TrimRight(parsedURL.Path, "/")
+	default:
+		repoURL = g.remoteRepoURL(parsedURL)
+	}
+
+	// Preserve unrecognized base URL query parameters (e.g. "?mirror=1" for enterprise gateways)
+	passThrough, err := passThroughQuery(parsedURL)
+	if err != nil {
+		return nil, err
 	}
-	// Ensure .git suffix
-	if !strings.HasSuffix(repoURL, ".git") {
-		repoURL += ".git"
+	if len(passThrough) > 0 {
+		repoURL += "?" + passThrough.Encode()
 	}
 
+	// Defaults registered for the repository apply to whatever the URL does not set
+	defaults := g.repoDefaultsFor(repoURL)
 	gitURL := &GitURL{
 		RepoURL:      repoURL,
-		Reference:    "main", // Default branch
-		PollInterval: defaultPollInterval,
+		Reference:    cmp.Or(defaults.Ref, "main"), // Default branch
+		PollInterval: cmp.Or(defaults.Poll, defaultPollInterval),
 		AuthData:     make(map[string]string),
 	}
 
@@ -574,6 +1008,12 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		}
 	}
 
+	// SECURITY: Bound the parameters before parsing them, so short malformed URLs
+	// cannot stress query parsing with thousands of parameters
+	if err := checkQueryBounds(parsedURL.RawQuery, queryString); err != nil {
+		return nil, err
+	}
+
 	// Also check original URL query parameters
 	originalQuery := parsedURL.Query()
 
@@ -585,6 +1025,27 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		fragmentQuery = make(url.Values)
 	}
 
+	// Extract a blob, read by object hash instead of by file path and reference
+	if gitURL.Blob = fragmentQuery.Get("blob"); gitURL.Blob == "" {
+		gitURL.Blob = originalQuery.Get("blob")
+	}
+	if gitURL.Blob != "" {
+		requireFile = false
+	}
+
+	// Extract an explicit format, which overrides detection from the file extension
+	var format string
+	if format = fragmentQuery.Get("format"); format == "" {
+		format = originalQuery.Get("format")
+	}
+	if format != "" {
+		if gitURL.Format, err = parseFormatName(format); err != nil {
+			return nil, err
+		}
+	} else {
+		gitURL.Format = g.options.format
+	}
+
 	// Extract file path from fragment or original query
 	if filePath != "" {
 		gitURL.FilePath = filePath
@@ -592,13 +1053,19 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		gitURL.FilePath = filepath
 	} else if filepath := fragmentQuery.Get("file"); filepath != "" {
 		gitURL.FilePath = filepath
-	} else {
+	} else if requireFile {
 		return nil, errors.New("ARGUS_INVALID_CONFIG", "configuration file path not specified (use #file.json or ?file=file.json)")
 	}
 
-	// Validate file path
-	if err := validateConfigFilePath(gitURL.FilePath); err != nil {
-		return nil, err
+	// Validate file path; an explicit format accepts any extension
+	if rawFile {
+		if err := g.validateRawFilePath(gitURL.FilePath); err != nil {
+			return nil, err
+		}
+	} else if gitURL.FilePath != "" || requireFile {
+		if err := g.validateConfigFilePath(gitURL.FilePath, gitURL.Format); err != nil {
+			return nil, err
+		}
 	}
 
 	// Extract git reference (branch, tag, commit) from fragment query or original query
@@ -620,6 +1087,83 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		gitURL.Reference = ref
 	}
 
+	// Extract a tag constraint, given as tag_constraint or as a constraint-like ref
+	constraint := fragmentQuery.Get("tag_constraint")
+	if constraint == "" {
+		constraint = originalQuery.Get("tag_constraint")
+	}
+	if constraint != "" {
+		gitURL.Reference = constraint
+	}
+
+	// SECURITY: Bound the reference before it is parsed as a constraint or expression
+	if len(gitURL.Reference) > maxRefLength {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("reference too long: %d bytes (max %d)", len(gitURL.Reference), maxRefLength))
+	}
+	if err := gitURL.setReference(gitURL.Reference, constraint != ""); err != nil {
+		return nil, err
+	}
+	if base := revisionBase(gitURL.Reference); strings.HasPrefix(base, "refs/") {
+		if _, ok := qualifiedRefName(base); !ok {
+			return nil, errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid reference name: %q", gitURL.Reference))
+		}
+	}
+
+	// Extract a release, whose asset named by the file path is loaded instead of a tree file
+	if gitURL.Release = fragmentQuery.Get("release"); gitURL.Release == "" {
+		gitURL.Release = originalQuery.Get("release")
+	}
+	if gitURL.Release != "" {
+		if local {
+			return nil, errors.New("ARGUS_INVALID_CONFIG", "release assets are not supported for local repositories")
+		}
+		if _, err := releaseAPIFor(gitURL.RepoURL); err != nil {
+			return nil, err
+		}
+	}
+
+	// Extract fallback references, as repeated or comma-separated fallback_ref parameters
+	for _, values := range [][]string{fragmentQuery["fallback_ref"], originalQuery["fallback_ref"]} {
+		for _, value := range values {
+			for _, ref := range strings.Split(value, ",") {
+				ref = strings.TrimSpace(ref)
+				if ref == "" || ref == gitURL.Reference || slices.Contains(gitURL.FallbackRefs, ref) {
+					continue
+				}
+				if len(ref) > maxRefLength {
+					return nil, errors.New("ARGUS_INVALID_CONFIG",
+						fmt.Sprintf("fallback reference too long: %d bytes (max %d)", len(ref), maxRefLength))
+				}
+				gitURL.FallbackRefs = append(gitURL.FallbackRefs, ref)
+			}
+		}
+	}
+	if len(gitURL.FallbackRefs) > maxFallbackRefs {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("too many fallback references: %d (max %d)", len(gitURL.FallbackRefs), maxFallbackRefs))
+	}
+
+	// Extract mirror repositories, as repeated mirror_url parameters. Mirrors are remote
+	// URLs validated like the primary repository URL.
+	for _, values := range [][]string{fragmentQuery["mirror_url"], originalQuery["mirror_url"]} {
+		for _, value := range values {
+			mirrorURL, err := validateSecureGitURL(strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+			mirror := g.remoteRepoURL(mirrorURL)
+			if mirror == transportURL(gitURL.RepoURL) || slices.Contains(gitURL.Mirrors, mirror) {
+				continue
+			}
+			gitURL.Mirrors = append(gitURL.Mirrors, mirror)
+		}
+	}
+	if len(gitURL.Mirrors) > maxMirrors {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("too many mirror repositories: %d (max %d)", len(gitURL.Mirrors), maxMirrors))
+	}
+
 	// Extract authentication information from fragment query or original query
 	var auth string
 	if auth = fragmentQuery.Get("auth"); auth == "" {
@@ -631,13 +1175,18 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		if len(parts) >= 2 {
 			gitURL.AuthType = parts[0]
 			switch gitURL.AuthType {
-			case "token":
+			case "token", "bearer", "gitea":
 				gitURL.AuthData["token"] = parts[1]
 			case "basic":
 				if len(parts) >= 3 {
 					gitURL.AuthData["username"] = parts[1]
 					gitURL.AuthData["password"] = parts[2]
 				}
+			case "header":
+				gitURL.AuthData["header_name"] = parts[1]
+				if len(parts) >= 3 {
+					gitURL.AuthData["header_value"] = parts[2]
+				}
 			case "key", "ssh":
 				gitURL.AuthData["keypath"] = parts[1]
 				if len(parts) >= 3 {
@@ -647,6 +1196,11 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		}
 	}
 
+	// Local repositories are read directly from disk; credentials would never be used
+	if local && (auth != "" || fragmentQuery.Has("ssh_key") || originalQuery.Has("ssh_key")) {
+		return nil, errors.New("ARGUS_INVALID_CONFIG", "authentication is not supported for local repositories")
+	}
+
 	// Handle ssh_key parameter (alternative to auth=key:path)
 	var sshKeyPath string
 	if sshKeyPath = fragmentQuery.Get("ssh_key"); sshKeyPath == "" {
@@ -658,16 +1212,32 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 		gitURL.AuthData["keypath"] = sshKeyPath
 
 		// SECURITY: Validate SSH key file permissions during parsing
-		if info, err := os.Stat(sshKeyPath); err != nil {
-			return nil, errors.New("ARGUS_AUTH_ERROR", "SSH key file not accessible")
-		} else if info.Mode().Perm() > 0o600 {
-			return nil, errors.New("ARGUS_SECURITY_ERROR", "SSH key file permissions too open (should be 0600 or less)")
+		if err := validateSecretFile(sshKeyPath, "SSH key file"); err != nil {
+			return nil, err
 		}
 	} else if fragmentQuery.Has("ssh_key") || originalQuery.Has("ssh_key") {
 		// Empty ssh_key parameter provided
 		return nil, errors.New("ARGUS_AUTH_ERROR", "SSH key path cannot be empty")
 	}
 
+	// The git daemon protocol cannot carry credentials; go-git rejects any it is given
+	daemon := isGitProtocolURL(repoURL)
+	if daemon && gitURL.AuthType != "" {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			"authentication is not supported for git:// repositories (use https:// or ssh://)")
+	}
+	if gitURL.AuthType == "" && !local && !daemon {
+		if err := applyDefaultAuth(gitURL, defaults); err != nil {
+			return nil, err
+		}
+	}
+
+	if gitURL.Blob != "" {
+		if err := validateBlobURL(gitURL, fragmentQuery, originalQuery); err != nil {
+			return nil, err
+		}
+	}
+
 	// Extract custom polling interval for watch
 	var interval string
 	if interval = fragmentQuery.Get("poll"); interval == "" {
@@ -675,77 +1245,289 @@ func (g *GitProvider) parseGitURL(configURL string) (*GitURL, error) {
 	}
 
 	if interval != "" {
-		if duration, err := time.ParseDuration(interval); err == nil {
-			if duration >= minPollInterval && duration <= maxPollInterval {
-				gitURL.PollInterval = duration
-			}
+		duration, err := g.parsePollInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		gitURL.PollInterval = duration
+	}
+
+	// Watches may skip delivering the configuration the caller already loaded
+	var watchInitial string
+	if watchInitial = fragmentQuery.Get("watch_initial"); watchInitial == "" {
+		watchInitial = originalQuery.Get("watch_initial")
+	}
+	if watchInitial != "" {
+		deliver, err := strconv.ParseBool(watchInitial)
+		if err != nil {
+			return nil, errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("invalid watch_initial value: %q (expected true or false)", watchInitial))
 		}
+		gitURL.SkipInitial = !deliver
 	}
 
 	return gitURL, nil
 }
 
-// Load loads configuration from a Git repository
-func (g *GitProvider) Load(ctx context.Context, configURL string) (map[string]interface{}, error) {
-	start := time.Now()
-	g.metrics.incrementLoadRequests()
-
-	defer func() {
-		g.metrics.addLoadTime(time.Since(start))
-	}()
+// parsePollInterval parses a poll parameter: a Go duration such as "90s" or "2m", or a
+// bare integer number of seconds. Intervals out of range are clamped to the nearest
+// bound, or rejected with WithStrictPoll.
+func (g *GitProvider) parsePollInterval(interval string) (time.Duration, error) {
+	duration, err := time.ParseDuration(interval)
+	if seconds, parseErr := strconv.ParseInt(interval, 10, 32); parseErr == nil {
+		duration, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil || duration <= 0 {
+		return 0, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("invalid poll interval: %q (expected a positive number of seconds or a duration such as 30s or 5m)", interval))
+	}
 
-	// Check if provider is closed
-	if atomic.LoadInt64(&g.closed) == 1 {
-		g.metrics.incrementFailedOperations()
-		return nil, errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	clamped := min(max(duration, minPollInterval), maxPollInterval)
+	if clamped != duration {
+		if g.options.strictPoll {
+			return 0, errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("poll interval %s out of range (%s to %s)", duration, minPollInterval, maxPollInterval))
+		}
+		g.logEvent(context.Background(), slog.LevelWarn, "poll interval out of range, clamped",
+			slog.Duration("requested", duration), slog.Duration("interval", clamped))
 	}
+	return clamped, nil
+}
 
-	// Increment operation count
-	if !g.incrementOperationCount() {
-		g.metrics.incrementFailedOperations()
-		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
-			fmt.Sprintf("maximum concurrent operations reached (%d)", maxConcurrentOperations))
+// remoteRepoURL builds the repository URL of a validated remote URL, preserving user
+// info for SSH and dropping the query string
+func (g *GitProvider) remoteRepoURL(parsedURL *url.URL) string {
+	if parsedURL.User != nil {
+		return g.applyGitSuffix(fmt.Sprintf("%s://%s@%s%s",
+			parsedURL.Scheme, parsedURL.User.Username(), parsedURL.Host, parsedURL.Path))
 	}
-	defer g.decrementOperationCount()
+	return g.applyGitSuffix(fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, parsedURL.Path))
+}
 
-	// Parse the Git URL
-	gitURL, err := g.parseGitURL(configURL)
-	if err != nil {
-		g.metrics.incrementFailedOperations()
-		g.classifyAndRecordError(err)
-		return nil, err
+// applyGitSuffix ensures the repository URL ends with ".git" unless suffixing is
+// disabled or the server's URL convention has no suffix (Azure DevOps, CodeCommit).
+func (g *GitProvider) applyGitSuffix(repoURL string) string {
+	// Trailing slashes would otherwise produce "repo.git/.git"
+	repoURL = strings.TrimRight(repoURL, "/")
+
+	if g.options.disableGitSuffix || strings.HasSuffix(repoURL, ".git") {
+		return repoURL
 	}
 
-	// Clone and read configuration
-	config, err := g.loadConfigFromRepo(ctx, gitURL)
-	if err != nil {
-		g.metrics.incrementFailedOperations()
-		g.classifyAndRecordError(err)
-		return nil, err
+	for _, marker := range noGitSuffixPathMarkers {
+		if strings.Contains(repoURL, marker) {
+			return repoURL
+		}
 	}
 
-	return config, nil
+	return repoURL + ".git"
 }
 
-// Watch starts watching for configuration changes in a Git repository
+// reservedQueryParams are the base URL query parameters interpreted by the provider;
+// any other parameter is passed through to the Git server.
+var reservedQueryParams = map[string]bool{
+	"file": true, "ref": true, "branch": true, "tag": true, "commit": true,
+	"auth": true, "token": true, "ssh_key": true, "poll": true, "watch_initial": true, "fallback_ref": true,
+	"mirror_url": true, "tag_constraint": true, "release": true, "blob": true, "format": true,
+}
+
+// checkQueryBounds rejects raw query strings with more than maxQueryParams parameters
+// in total or a parameter longer than maxQueryParamLength
+func checkQueryBounds(queries ...string) error {
+	params := 0
+	for _, query := range queries {
+		if query != "" {
+			params += strings.Count(query, "&") + 1
+		}
+	}
+	if params > maxQueryParams {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("too many URL query parameters: %d (max %d)", params, maxQueryParams))
+	}
+
+	for _, query := range queries {
+		for _, param := range strings.Split(query, "&") {
+			if len(param) > maxQueryParamLength {
+				// The parameter itself is not reported, since it may hold credentials
+				return errors.New("ARGUS_INVALID_CONFIG",
+					fmt.Sprintf("URL query parameter too long: %d bytes (max %d)", len(param), maxQueryParamLength))
+			}
+		}
+	}
+	return nil
+}
+
+// passThroughQuery extracts and validates the base URL query parameters that are not
+// interpreted by the provider. Only HTTP(S) transports can carry them.
+func passThroughQuery(parsedURL *url.URL) (url.Values, error) {
+	if parsedURL.Scheme != "https" {
+		return nil, nil // Other transports have no query string; parameters are ignored as before
+	}
+
+	passThrough := make(url.Values)
+	for key, values := range parsedURL.Query() {
+		if reservedQueryParams[key] {
+			continue
+		}
+
+		// SECURITY: Restrict keys to a conservative character set and values to printable text
+		if key == "" || strings.TrimLeft(key, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_.-") != "" {
+			return nil, errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("invalid query parameter name in git URL: %q", key))
+		}
+		for _, value := range values {
+			for _, r := range value {
+				if r < 32 || r == 0x7f {
+					return nil, errors.New("ARGUS_SECURITY_ERROR",
+						fmt.Sprintf("control character in git URL query parameter %s", key))
+				}
+			}
+		}
+
+		passThrough[key] = values
+	}
+
+	return passThrough, nil
+}
+
+// noGitSuffixPathMarkers identify repository URLs whose servers reject a ".git" suffix
+var noGitSuffixPathMarkers = []string{
+	"/_git/",     // Azure DevOps and TFS
+	"/v1/repos/", // AWS CodeCommit
+}
+
+// Load loads configuration from a Git repository
+func (g *GitProvider) Load(ctx context.Context, configURL string) (map[string]interface{}, error) {
+	result, err := g.LoadDetailed(ctx, configURL)
+	if err != nil {
+		return nil, err
+	}
+	return result.Config, nil
+}
+
+// LoadDetailed loads configuration from a Git repository and reports how it was obtained:
+// the detected file format, the commit the content was read from, and its size in bytes.
+func (g *GitProvider) LoadDetailed(ctx context.Context, configURL string) (result *LoadResult, err error) {
+	start := time.Now()
+	g.metrics.incrementLoadRequests()
+
+	var gitURL *GitURL
+	defer func() {
+		g.metrics.addLoadTime(time.Since(start))
+		g.audit(AuditOperationLoad, configURL, gitURL, result, err, time.Since(start))
+	}()
+
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		g.metrics.incrementFailedOperations()
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		g.metrics.incrementFailedOperations()
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	defer g.decrementOperationCount()
+
+	// Parse the Git URL
+	gitURL, err = g.parseRequestURL(ctx, configURL, true)
+	if err != nil {
+		g.metrics.incrementFailedOperations()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	// With WithPinResolvedRef, the reference is read at the commit it first resolved to
+	gitURL, pinKey := g.applyRefPin(gitURL)
+
+	// Clone and read configuration
+	result, err = g.loadConfigFromRepo(ctx, gitURL)
+	if err == nil {
+		err = g.checkRequiredKeys(result.Config)
+	}
+	if err != nil {
+		g.metrics.incrementFailedOperations()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	g.recordRefPin(pinKey, result)
+
+	return result, nil
+}
+
+// Warmup loads each configuration URL concurrently to populate the cache, e.g. at startup
+// so the first request is not slow. At most maxConcurrentOperations URLs are loaded at
+// once; loads running elsewhere count towards the same limit, so a URL may fail with
+// ARGUS_RESOURCE_LIMIT while the provider is busy. All URLs are attempted; the returned
+// slice holds the error for each URL at the same index, or nil when it loaded successfully.
+//
+// Example:
+//
+//	for i, err := range provider.Warmup(ctx, urls) {
+//	    if err != nil {
+//	        log.Printf("warmup of %s failed: %v", urls[i], err)
+//	    }
+//	}
+func (g *GitProvider) Warmup(ctx context.Context, urls []string) []error {
+	errs := make([]error, len(urls))
+
+	workers := min(len(urls), maxConcurrentOperations)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				_, errs[index] = g.LoadDetailed(ctx, urls[index])
+			}
+		}()
+	}
+
+	for index := range urls {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// Watch starts watching for configuration changes in a Git repository
 func (g *GitProvider) Watch(ctx context.Context, configURL string) (<-chan map[string]interface{}, error) {
+	return g.watch(ctx, configURL, nil)
+}
+
+// watch starts a watch whose invalid configurations are reported on errChan under
+// WatchDeliverError; errChan may be nil, and is closed when the watch ends otherwise
+func (g *GitProvider) watch(ctx context.Context, configURL string, errChan chan error) (<-chan map[string]interface{}, error) {
 	g.metrics.incrementWatchRequests()
 
 	// Check if provider is closed
 	if atomic.LoadInt64(&g.closed) == 1 {
 		g.metrics.incrementFailedOperations()
-		return nil, errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, err
 	}
 
 	// Check watch limit
 	if !g.incrementWatchCount() {
 		g.metrics.incrementFailedOperations()
-		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+		err := errors.New("ARGUS_RESOURCE_LIMIT",
 			fmt.Sprintf("maximum active watches reached (%d)", maxActiveWatches))
+		g.classifyAndRecordError(err)
+		return nil, err
 	}
 
 	// Parse the Git URL
-	gitURL, err := g.parseGitURL(configURL)
+	gitURL, err := g.parseRequestURL(ctx, configURL, true)
 	if err != nil {
 		g.decrementWatchCount()
 		g.metrics.incrementFailedOperations()
@@ -757,7 +1539,8 @@ func (g *GitProvider) Watch(ctx context.Context, configURL string) (<-chan map[s
 	configChan := make(chan map[string]interface{}, 1)
 
 	// Start watching in a goroutine
-	go g.startWatching(ctx, gitURL, configChan)
+	g.registerWatch(configURL, gitURL)
+	go g.startWatching(ctx, gitURL, configChan, errChan)
 
 	return configChan, nil
 }
@@ -768,10 +1551,46 @@ func (g *GitProvider) Validate(configURL string) error {
 	return err
 }
 
+// ParseURL parses and validates a configuration URL without contacting the repository,
+// reporting the resolved repository, file, reference and authentication settings.
+// Secret authentication values (tokens, passwords, header values, key passphrases)
+// are masked in the result.
+func (g *GitProvider) ParseURL(configURL string) (*ParsedConfig, error) {
+	gitURL, err := g.parseGitURL(configURL)
+	if err != nil {
+		return nil, err
+	}
+
+	authData := make(map[string]string, len(gitURL.AuthData))
+	for key, value := range gitURL.AuthData {
+		if secretAuthKeys[key] && value != "" {
+			value = maskedAuthValue
+		}
+		authData[key] = value
+	}
+
+	return &ParsedConfig{
+		RepoURL:       gitURL.RepoURL,
+		FilePath:      gitURL.FilePath,
+		Reference:     gitURL.Reference,
+		FallbackRefs:  append([]string(nil), gitURL.FallbackRefs...),
+		Mirrors:       append([]string(nil), gitURL.Mirrors...),
+		TagConstraint: gitURL.TagConstraint,
+		Release:       gitURL.Release,
+		Blob:          gitURL.Blob,
+		Pinned:        gitURL.isImmutable(),
+		Format:        resultFormat(gitURL.FilePath, gitURL.Format),
+		AuthType:      gitURL.AuthType,
+		AuthData:      authData,
+		PollInterval:  gitURL.PollInterval,
+		SkipInitial:   gitURL.SkipInitial,
+	}, nil
+}
+
 // HealthCheck performs a health check on the Git repository
 func (g *GitProvider) HealthCheck(ctx context.Context, configURL string) error {
 	// Parse the Git URL
-	gitURL, err := g.parseGitURL(configURL)
+	gitURL, err := g.parseRequestURL(ctx, configURL, true)
 	if err != nil {
 		return err
 	}
@@ -780,13 +1599,174 @@ func (g *GitProvider) HealthCheck(ctx context.Context, configURL string) error {
 	return g.checkRepositoryHealth(ctx, gitURL)
 }
 
-// Close cleanly shuts down the provider and releases resources
+// ListRefs lists the branches and tags of a repository with a single remote reference
+// listing, without cloning. Names are returned without their "refs/heads/" and
+// "refs/tags/" prefixes, sorted lexically. repoURL accepts the same base URL and
+// authentication parameters as configuration URLs; any file path is ignored.
+//
+// Example:
+//
+//	branches, tags, err := provider.ListRefs(ctx, "https://github.com/org/configs.git")
+//	if !slices.Contains(branches, userRef) && !slices.Contains(tags, userRef) {
+//	    return fmt.Errorf("unknown reference %q", userRef)
+//	}
+func (g *GitProvider) ListRefs(ctx context.Context, repoURL string) (branches, tags []string, err error) {
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, nil, err
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return nil, nil, err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err := g.parseRequestURL(ctx, repoURL, false)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, nil, err
+	}
+
+	var refs []*plumbing.Reference
+	err = g.tryMirrors(gitURL, func(gitURL *GitURL) error {
+		return g.retryOperation(ctx, func() error {
+			var err error
+			refs, err = g.listRemoteRefs(ctx, gitURL)
+			return err
+		}, "git ls-remote")
+	})
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, nil, err
+	}
+
+	branches, tags = make([]string, 0), make([]string, 0)
+	for _, ref := range refs {
+		name := ref.Name()
+		switch {
+		case name.IsBranch():
+			branches = append(branches, name.Short())
+		case name.IsTag() && !strings.HasSuffix(name.String(), "^{}"):
+			tags = append(tags, name.Short())
+		}
+	}
+	sort.Strings(branches)
+	sort.Strings(tags)
+
+	return branches, tags, nil
+}
+
+// ResolveCommit returns the full hash of the commit a URL's reference points at, with a
+// single remote reference listing and no clone, for change detection outside the
+// provider. The URL accepts the same forms and authentication parameters as
+// configuration URLs and is validated the same way; a file path is optional and ignored.
+// Without a reference the default branch is resolved, tag constraints resolve to
+// their highest matching tag, and full commit hashes resolve to themselves. Fallback
+// references are not tried. Revision expressions, releases and blobs fail with
+// ARGUS_INVALID_CONFIG, since they cannot be resolved without a clone.
+//
+// Example:
+//
+//	commit, err := provider.ResolveCommit(ctx, "https://github.com/org/configs.git?ref=main")
+func (g *GitProvider) ResolveCommit(ctx context.Context, configURL string) (commit string, err error) {
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err := g.parseRequestURL(ctx, configURL, false)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+	if gitURL.Release != "" || gitURL.Blob != "" || isRevisionExpression(gitURL.Reference) {
+		return "", errors.New("ARGUS_INVALID_CONFIG",
+			"revision expressions, releases and blobs cannot be resolved without cloning")
+	}
+	if isCommitHash(gitURL.Reference) {
+		return strings.ToLower(gitURL.Reference), nil
+	}
+
+	gitURL.FallbackRefs = nil
+	commit, err = g.getRemoteCommitHash(ctx, gitURL)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return "", err
+	}
+	return commit, nil
+}
+
+// ListConfigs lists the configuration files available in a repository at the given reference.
+//
+// Only paths that pass configuration path validation and have a registered format
+// decoder are returned, sorted lexically; files larger than the configuration size
+// limit are skipped. repoURL accepts the same base URL and authentication parameters
+// as configuration URLs (e.g. "https://github.com/org/configs.git?auth=token:xxx"),
+// and a non-empty ref overrides the reference given in the URL.
+func (g *GitProvider) ListConfigs(ctx context.Context, repoURL, ref string) ([]string, error) {
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err := g.parseRequestURL(ctx, repoURL, false)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	if ref != "" {
+		if err := gitURL.setReference(ref, false); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := g.listConfigsFromRepo(ctx, gitURL)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Close cleanly shuts down the provider and releases resources.
+// New operations are rejected and watches end at their next poll; operations already in
+// progress are given up to closeDrainTimeout to finish before their temporary
+// directories and the caches are released.
 func (g *GitProvider) Close() error {
 	// Check if already closed (idempotent operation)
 	if !atomic.CompareAndSwapInt64(&g.closed, 0, 1) {
 		return nil
 	}
 
+	// Wait for in-flight operations, which may still use temp dirs and caches
+	g.drainOperations(closeDrainTimeout)
+
 	// Clean up temporary directories
 	g.cleanupTempDirectories()
 
@@ -802,21 +1782,77 @@ func (g *GitProvider) Close() error {
 	return nil
 }
 
-// incrementOperationCount safely increments the operation counter
+// IsClosed reports whether the provider has been closed. Operations on a closed
+// provider fail with ARGUS_PROVIDER_CLOSED.
+func (g *GitProvider) IsClosed() bool {
+	return atomic.LoadInt64(&g.closed) == 1
+}
+
+// incrementOperationCount safely increments the operation counter.
+// It fails at the concurrency limit and once the provider is closed.
 func (g *GitProvider) incrementOperationCount() bool {
-	current := atomic.LoadInt64(&g.operationCount)
-	if current >= maxConcurrentOperations {
+	for {
+		current := atomic.LoadInt64(&g.operationCount)
+		if current >= maxConcurrentOperations {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&g.operationCount, current, current+1) {
+			break
+		}
+	}
+
+	if !g.enterOperation() {
+		atomic.AddInt64(&g.operationCount, -1)
 		return false
 	}
-	atomic.AddInt64(&g.operationCount, 1)
 	return true
 }
 
 // decrementOperationCount safely decrements the operation counter
 func (g *GitProvider) decrementOperationCount() {
+	g.exitOperation()
 	atomic.AddInt64(&g.operationCount, -1)
 }
 
+// operationLimitError explains why incrementOperationCount failed
+func (g *GitProvider) operationLimitError() error {
+	if atomic.LoadInt64(&g.closed) == 1 {
+		return errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	}
+	return errors.New("ARGUS_RESOURCE_LIMIT",
+		fmt.Sprintf("maximum concurrent operations reached (%d)", maxConcurrentOperations))
+}
+
+// enterOperation registers an in-flight operation unless the provider is closed.
+// Close sets the closed flag before draining, so an operation registered after
+// the drain started always observes the flag and backs out.
+func (g *GitProvider) enterOperation() bool {
+	atomic.AddInt64(&g.inFlight, 1)
+	if atomic.LoadInt64(&g.closed) == 1 {
+		g.exitOperation()
+		return false
+	}
+	return true
+}
+
+// exitOperation unregisters an in-flight operation
+func (g *GitProvider) exitOperation() {
+	atomic.AddInt64(&g.inFlight, -1)
+}
+
+// drainOperations waits until no operation is in flight or timeout elapses,
+// reporting whether all operations finished
+func (g *GitProvider) drainOperations(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&g.inFlight) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
 // incrementWatchCount safely increments the watch counter
 func (g *GitProvider) incrementWatchCount() bool {
 	current := atomic.LoadInt64(&g.watchCount)
@@ -832,119 +1868,702 @@ func (g *GitProvider) decrementWatchCount() {
 	atomic.AddInt64(&g.watchCount, -1)
 }
 
-// loadConfigFromRepo clones the repository and loads the configuration file with intelligent caching
-func (g *GitProvider) loadConfigFromRepo(ctx context.Context, gitURL *GitURL) (map[string]interface{}, error) {
-	// First, try to get the current commit hash for caching
-	commitHash, err := g.getRemoteCommitHash(ctx, gitURL)
-	if err != nil {
-		// If we can't get the commit hash, fall back to direct loading
-		return g.loadConfigFromRepoDirectly(ctx, gitURL)
+// loadConfigFromRepo loads the configuration at the URL's reference, trying the fallback
+// references in order when it cannot be loaded. The result records the serving reference.
+func (g *GitProvider) loadConfigFromRepo(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	result, err := g.loadConfigFromRefs(ctx, gitURL)
+	if g.options.staleFallback <= 0 {
+		return result, err
 	}
 
-	// Check if we have this configuration cached
-	if cachedConfig, found := g.configCache.get(gitURL, commitHash); found {
-		g.metrics.incrementCacheHits()
-		return cachedConfig, nil
+	if err == nil {
+		g.configCache.putLastGood(gitURL, result)
+		return result, nil
 	}
-	g.metrics.incrementCacheMisses()
 
-	// Load configuration directly
-	config, err := g.loadConfigFromRepoDirectly(ctx, gitURL)
-	if err != nil {
+	// Only an unreachable remote justifies serving an older configuration;
+	// missing files, bad credentials and parse errors are still reported
+	if !isRemoteUnavailableError(err) {
+		return nil, err
+	}
+	stale, found := g.configCache.getLastGood(gitURL, g.options.staleFallback)
+	if !found {
 		return nil, err
 	}
 
-	// Cache the loaded configuration
-	g.configCache.put(gitURL, commitHash, config)
-	g.metrics.incrementConfigsCached()
-
-	return config, nil
+	g.metrics.incrementStaleServes()
+	g.logEvent(ctx, slog.LevelWarn, "serving stale configuration",
+		append(logURLAttrs(gitURL), slog.Time("loaded_at", stale.LoadedAt), logErrorAttr(err))...)
+	return stale, nil
 }
 
-// loadConfigFromRepoDirectly performs the actual repository cloning and config loading
-func (g *GitProvider) loadConfigFromRepoDirectly(ctx context.Context, gitURL *GitURL) (map[string]interface{}, error) {
-	// Create temporary directory for clone
-	tempDir, err := g.createTempDirectory()
-	if err != nil {
-		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to create temporary directory")
+// loadConfigFromRefs loads the configuration at the reference, then at each fallback reference
+func (g *GitProvider) loadConfigFromRefs(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	if gitURL.Release != "" {
+		return g.loadReleaseAsset(ctx, gitURL)
 	}
-	defer g.removeTempDirectory(tempDir)
-
-	// Clone repository
-	repo, err := g.cloneRepository(ctx, gitURL, tempDir)
-	if err != nil {
-		return nil, err
+	if gitURL.Blob != "" {
+		return g.loadBlobConfig(ctx, gitURL)
 	}
 
-	// Read configuration file
-	config, err := g.readConfigFile(repo, gitURL.FilePath, gitURL.Reference)
-	if err != nil {
-		return nil, err
+	resolvedURL, err := g.resolveTagConstraint(ctx, gitURL)
+	var result *LoadResult
+	if err == nil {
+		result, err = g.loadConfigAtRef(ctx, resolvedURL)
+	}
+	if err == nil || len(gitURL.FallbackRefs) == 0 || !isReferenceNotFoundError(err) {
+		return result, err
 	}
 
-	return config, nil
-}
+	attempted := []string{gitURL.Reference}
+	for _, ref := range gitURL.FallbackRefs {
+		if ctx.Err() != nil {
+			break
+		}
 
-// cloneRepository clones a Git repository to a temporary directory with retry logic
-func (g *GitProvider) cloneRepository(ctx context.Context, gitURL *GitURL, tempDir string) (*git.Repository, error) {
-	var repo *git.Repository
+		fallbackURL := *gitURL
+		fallbackURL.Reference = ref
+		fallbackURL.TagConstraint = ""
+		fallbackURL.FallbackRefs = nil
 
-	err := g.retryOperation(ctx, func() error {
-		// Prepare clone options
-		cloneOptions := &git.CloneOptions{
-			URL:      gitURL.RepoURL,
-			Progress: nil, // No progress reporting for security
-			Depth:    1,   // Shallow clone for performance
+		attempted = append(attempted, ref)
+		result, err = g.loadConfigAtRef(ctx, &fallbackURL)
+		if err == nil {
+			return result, nil
 		}
 
-		// Set authentication if provided
-		if auth, err := g.getAuthentication(gitURL); err == nil && auth != nil {
-			cloneOptions.Auth = auth
+		// Only a missing reference moves on; a broken configuration must not be masked
+		if !isReferenceNotFoundError(err) {
+			return nil, err
 		}
+	}
 
-		// Set reference if specified
-		if gitURL.Reference != "" {
-			cloneOptions.ReferenceName = plumbing.ReferenceName("refs/heads/" + gitURL.Reference)
-			cloneOptions.SingleBranch = true
-		}
+	return nil, errors.Wrap(err, "ARGUS_GIT_ERROR",
+		fmt.Sprintf("failed to load configuration from any reference (tried: %s)", strings.Join(attempted, ", ")))
+}
 
-		// Add timeout to context
-		cloneCtx, cancel := context.WithTimeout(ctx, defaultGitTimeout)
-		defer cancel()
+// isReferenceNotFoundError reports whether err means the reference does not exist,
+// as opposed to the configuration at it failing to load. A commit reference that is
+// missing from the repository fails to be checked out as an object that is not found.
+func isReferenceNotFoundError(err error) bool {
+	for _, target := range []error{plumbing.ErrReferenceNotFound, git.NoMatchingRefSpecError{}, git.ErrBranchNotFound, plumbing.ErrObjectNotFound} {
+		if stderrors.Is(err, target) {
+			return true
+		}
+	}
+
+	// Reference lookups of ls-remote results have no sentinel error
+	return strings.Contains(errorChainText(err), "not found in remote repository")
+}
 
-		// Clone repository
+// loadConfigAtRef clones the repository and loads the configuration file with intelligent caching
+func (g *GitProvider) loadConfigAtRef(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	// Public files may be downloaded without a reference lookup or clone
+	if result, served, err := g.loadRawConfig(ctx, gitURL); served {
+		return result, err
+	}
+
+	// A commit-pinned reference is its own cache key, so no remote lookup is needed;
+	// revision expressions are cached under the commit of their base
+	commitHash := strings.ToLower(revisionBase(gitURL.Reference))
+	if !isCommitHash(commitHash) {
+		// First, try to get the current commit hash for caching
 		var err error
-		repo, err = git.PlainCloneContext(cloneCtx, tempDir, false, cloneOptions)
+		commitHash, err = g.getRemoteCommitHash(ctx, gitURL)
+		if errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			return nil, err // An ambiguous reference cannot be loaded directly either
+		}
 		if err != nil {
-			return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to clone repository")
+			// If we can't get the commit hash, fall back to direct loading
+			g.logEvent(ctx, slog.LevelWarn, "commit lookup failed, loading without cache",
+				append(logURLAttrs(gitURL), logErrorAttr(err))...)
+			return g.loadConfigFromRepoDirectly(ctx, gitURL)
 		}
+	}
 
-		return nil
-	}, "git clone")
+	// Check if we have this configuration cached
+	if cached, found := g.configCache.getResult(gitURL, commitHash); found {
+		g.metrics.incrementCacheHits()
+		g.logEvent(ctx, slog.LevelDebug, "configuration cache hit",
+			append(logURLAttrs(gitURL), slog.String("commit", commitHash))...)
+		cached.Reference = gitURL.Reference
+		cached.cacheHit = true
+		return cached, nil
+	}
+	g.metrics.incrementCacheMisses()
+	g.logEvent(ctx, slog.LevelDebug, "configuration cache miss",
+		append(logURLAttrs(gitURL), slog.String("commit", commitHash))...)
 
+	// Load configuration directly
+	result, err := g.loadConfigFromRepoDirectly(ctx, gitURL)
 	if err != nil {
 		return nil, err
 	}
 
+	// Cache under the commit the file was read from, which is newer than the
+	// resolved commit if the branch moved between the lookup and the clone. An
+	// ancestor is read from another commit than the base the lookup resolves.
+	if result.CommitHash != "" && !isRevisionExpression(gitURL.Reference) {
+		commitHash = result.CommitHash
+	}
+	g.configCache.putResult(gitURL, commitHash, result)
+	g.persistCacheEntry(gitURL, commitHash, result)
+	g.metrics.incrementConfigsCached()
+
+	return result, nil
+}
+
+// loadConfigFromRepoDirectly performs the actual repository cloning and config loading
+func (g *GitProvider) loadConfigFromRepoDirectly(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	// Create temporary directory for clone
+	tempDir, err := g.createTempDirectory()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to create temporary directory")
+	}
+	defer g.removeTempDirectory(tempDir)
+
+	// Clone repository; its handles are closed before the deferred removal
+	repo, err := g.cloneRepository(ctx, gitURL, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepository(repo)
+
+	// Commits that leave the file unchanged share the configuration parsed for its blob
+	blobURL, commitHash := g.treeBlobURL(repo, gitURL)
+	if blobURL != nil {
+		if cached, found := g.configCache.getBlob(blobURL); found {
+			g.metrics.incrementBlobCacheHits()
+			g.logEvent(ctx, slog.LevelDebug, "configuration blob cache hit",
+				append(logURLAttrs(gitURL), slog.String("blob", blobURL.Blob))...)
+			cached.CommitHash = commitHash
+			cached.Reference = gitURL.Reference
+			cached.Trailers = commitTrailers(repo, commitHash)
+			return cached, nil
+		}
+	}
+
+	// Read configuration file from the commit tree, or from a checkout if it cannot be
+	result, fromTree, err := g.readTreeConfig(repo, gitURL.FilePath, gitURL.Format, gitURL.Reference)
+	if err == nil && !fromTree {
+		result, err = g.readConfigFile(repo, gitURL.FilePath, gitURL.Format, gitURL.Reference)
+	}
+	if err == nil {
+		err = g.resolveResultIncludes(repo, gitURL.FilePath, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Reference = gitURL.Reference
+	result.Trailers = commitTrailers(repo, result.CommitHash)
+	if blobURL != nil && result.CommitHash == commitHash {
+		g.configCache.putBlob(blobURL, result)
+	}
+
+	return result, nil
+}
+
+// cloneRepository clones a Git repository to a temporary directory with retry logic
+func (g *GitProvider) cloneRepository(ctx context.Context, gitURL *GitURL, tempDir string) (*git.Repository, error) {
+	start := time.Now()
+	defer func() {
+		g.metrics.addCloneTime(time.Since(start))
+	}()
+
+	var repo *git.Repository
+	g.logEvent(ctx, slog.LevelDebug, "cloning repository",
+		append(logURLAttrs(gitURL), slog.Int("depth", g.options.effectiveCloneDepth()))...)
+
+	// Mirrors are tried in order while the previous repository is unreachable
+	err := g.tryMirrors(gitURL, func(gitURL *GitURL) error {
+		return g.retryOperation(ctx, func() error {
+			// Prepare clone options
+			cloneOptions := &git.CloneOptions{
+				URL:   transportURL(gitURL.RepoURL),
+				Depth: g.options.effectiveCloneDepth(), // Shallow clone for performance
+			}
+
+			// Raw server progress text is never exposed, only parsed events
+			if progress := g.newProgressWriter(gitURL.RepoURL); progress != nil {
+				cloneOptions.Progress = progress
+			}
+
+			// Set authentication if provided; credentials that cannot be loaded fail the clone
+			auth, err := g.getAuthentication(gitURL)
+			if err != nil {
+				return err
+			}
+			if auth != nil {
+				cloneOptions.Auth = auth
+			}
+			cloneOptions.CABundle, cloneOptions.InsecureSkipTLS, cloneOptions.ProxyOptions = g.transportOptions(gitURL.RepoURL)
+
+			// Configuration files are read from the commit tree, so loads skip the
+			// checkout; the worktree is only written if reading has to fall back to it
+			if gitURL.FilePath != "" {
+				cloneOptions.NoCheckout = true
+			}
+
+			// Set reference if specified. A commit may not be the tip of any branch, so
+			// commit references clone every branch and are deepened below when missing.
+			// Revision expressions clone their base and are deepened to the ancestor.
+			reference := revisionBase(gitURL.Reference)
+			qualifiedName, qualified := qualifiedRefName(reference)
+			switch {
+			case qualified:
+				cloneOptions.ReferenceName = qualifiedName
+				cloneOptions.SingleBranch = !g.options.allBranches
+			case reference != "" && !isCommitLike(reference):
+				cloneOptions.ReferenceName = plumbing.ReferenceName("refs/heads/" + reference)
+				cloneOptions.SingleBranch = !g.options.allBranches
+			}
+			if g.options.skipTags {
+				cloneOptions.Tags = git.NoTags
+			}
+
+			// Add timeout to context
+			cloneCtx, cancel := context.WithTimeout(ctx, g.options.effectiveCloneTimeout())
+			defer cancel()
+
+			// Clone repository. go-git clones only branches and tags by name, so other
+			// qualified references (e.g. refs/pull/123/head) are fetched explicitly.
+			if qualified && !qualifiedName.IsBranch() && !qualifiedName.IsTag() {
+				repo, err = fetchQualifiedRef(cloneCtx, tempDir, cloneOptions)
+			} else {
+				repo, err = git.PlainCloneContext(cloneCtx, tempDir, false, cloneOptions)
+			}
+
+			// A bare reference that is not a branch may be a tag
+			if stderrors.Is(err, git.NoMatchingRefSpecError{}) && cloneOptions.ReferenceName.IsBranch() && !qualified {
+				cloneOptions.ReferenceName = plumbing.NewTagReferenceName(reference)
+				repo, err = git.PlainCloneContext(cloneCtx, tempDir, false, cloneOptions)
+			}
+			if err != nil {
+				return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to clone repository")
+			}
+
+			return nil
+		}, "git clone")
+	})
+
+	if err != nil {
+		g.logEvent(ctx, slog.LevelWarn, "clone failed", append(logURLAttrs(gitURL), logErrorAttr(err))...)
+		return nil, err
+	}
+
+	if err := g.deepenToCommit(ctx, repo, gitURL); err != nil {
+		closeRepository(repo)
+		return nil, err
+	}
+	g.logEvent(ctx, slog.LevelDebug, "repository cloned",
+		append(logURLAttrs(gitURL), slog.Duration("duration", time.Since(start)))...)
+
 	return repo, nil
 }
 
-// readConfigFile reads and parses a configuration file from the repository
-func (g *GitProvider) readConfigFile(repo *git.Repository, filePath, reference string) (map[string]interface{}, error) {
+// fetchQualifiedRef initializes a repository in tempDir and fetches the single reference
+// cloneOptions names under the same name, leaving HEAD detached at its commit. Like a
+// clone made with NoCheckout, the worktree is not written; callers check the reference out.
+func fetchQualifiedRef(ctx context.Context, tempDir string, cloneOptions *git.CloneOptions) (*git.Repository, error) {
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cloneOptions.ReferenceName
+	err = func() error {
+		remote, err := repo.CreateRemote(&config.RemoteConfig{
+			Name:  git.DefaultRemoteName,
+			URLs:  []string{cloneOptions.URL},
+			Fetch: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", name, name))},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = remote.FetchContext(ctx, &git.FetchOptions{
+			Depth:           cloneOptions.Depth,
+			Auth:            cloneOptions.Auth,
+			Progress:        cloneOptions.Progress,
+			Tags:            git.NoTags,
+			CABundle:        cloneOptions.CABundle,
+			InsecureSkipTLS: cloneOptions.InsecureSkipTLS,
+			ProxyOptions:    cloneOptions.ProxyOptions,
+		})
+		if err != nil && !stderrors.Is(err, git.NoErrAlreadyUpToDate) {
+			return err
+		}
+
+		ref, err := repo.Reference(name, true)
+		if err != nil {
+			return err
+		}
+		return repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, ref.Hash()))
+	}()
+	if err != nil {
+		// Leave tempDir empty for the next attempt, as a failed clone does
+		closeRepository(repo)
+		_ = os.RemoveAll(filepath.Join(tempDir, git.GitDirName))
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// deepenToCommit fetches more history into a shallow clone until the commit the
+// reference names, or the ancestor a revision expression names, is present. The
+// clone is deepened by cloneDeepenFactor at a time and unshallowed past
+// maxCloneDeepenDepth; a commit that is still missing is reported when it is
+// checked out.
+func (g *GitProvider) deepenToCommit(ctx context.Context, repo *git.Repository, gitURL *GitURL) error {
+	present := func() bool { return hasCommit(repo, gitURL.Reference) }
+	if isRevisionExpression(gitURL.Reference) {
+		present = func() bool {
+			_, ok := resolveReferenceCommit(repo, gitURL.Reference)
+			return ok
+		}
+	} else if !isCommitLike(gitURL.Reference) {
+		return nil
+	}
+
+	depth := g.options.effectiveCloneDepth()
+	if depth == 0 || present() {
+		return nil
+	}
+
+	// The clone may have been made anonymously, so fetches fall back the same way
+	fetch := g.rejectHostCredential(g.anonymousFallback(func(target *GitURL) error {
+		fetchOptions := &git.FetchOptions{Depth: depth}
+		auth, err := g.getAuthentication(target)
+		if err != nil {
+			return err
+		}
+		if auth != nil {
+			fetchOptions.Auth = auth
+		}
+		fetchOptions.CABundle, fetchOptions.InsecureSkipTLS, fetchOptions.ProxyOptions = g.transportOptions(target.RepoURL)
+		if progress := g.newProgressWriter(target.RepoURL); progress != nil {
+			fetchOptions.Progress = progress
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, g.options.effectiveCloneTimeout())
+		defer cancel()
+		if err := repo.FetchContext(fetchCtx, fetchOptions); err != nil && !stderrors.Is(err, git.NoErrAlreadyUpToDate) {
+			return err
+		}
+		return nil
+	}))
+
+	for depth < unshallowDepth {
+		if depth > maxCloneDeepenDepth/cloneDeepenFactor {
+			depth = unshallowDepth
+		} else {
+			depth *= cloneDeepenFactor
+		}
+
+		if err := fetch(gitURL); err != nil {
+			return errors.Wrap(err, "ARGUS_GIT_ERROR",
+				fmt.Sprintf("failed to deepen clone to commit: %s", gitURL.Reference))
+		}
+
+		if present() {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// hasCommit reports whether the full or abbreviated commit hash resolves in the repository
+func hasCommit(repo *git.Repository, reference string) bool {
+	hash, err := repo.ResolveRevision(plumbing.Revision(reference))
+	if err != nil {
+		return false
+	}
+	_, err = repo.CommitObject(*hash)
+	return err == nil
+}
+
+// readTreeConfig reads and parses a configuration file straight from the commit tree of
+// the reference, without a worktree checkout. It reports false, without an error, when the
+// file has to be read from a checkout instead: for symlinks and for references that do
+// not resolve to a commit in the clone. A non-empty format overrides the file extension.
+func (g *GitProvider) readTreeConfig(repo *git.Repository, filePath string, format Format, reference string) (*LoadResult, bool, error) {
+	fileContent, commit, fromTree, err := readTreeFile(repo, filePath, reference, maxConfigFileSize,
+		func(targetPath string) error { return g.validateConfigFilePath(targetPath, format) })
+	if err != nil || !fromTree {
+		return nil, fromTree, err
+	}
+
+	// Parse configuration based on the format or file extension
+	config, err := g.parseConfigFileAs(filePath, format, fileContent)
+	if err != nil {
+		return nil, true, err
+	}
+
+	result := &LoadResult{
+		Config:     config,
+		Format:     resultFormat(filePath, format),
+		Size:       len(fileContent),
+		CommitHash: commit.Hash.String(),
+	}
+	if g.options.diskCacheDir != "" {
+		result.content = fileContent
+	}
+
+	return result, true, nil
+}
+
+// readTreeFile reads a file of at most maxSize bytes straight from the commit tree of
+// the reference. Symbolic links are followed within the tree, and validate checks the
+// path of their target. Like readTreeConfig, it reports false, without an error, when
+// the file cannot be read from the tree.
+func readTreeFile(repo *git.Repository, filePath, reference string, maxSize int64, validate func(string) error) ([]byte, *object.Commit, bool, error) {
+	commit, ok := resolveReferenceCommit(repo, reference)
+	if !ok {
+		return nil, nil, false, nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, false, nil
+	}
+
+	// Follow symbolic links within the tree; the target must be a valid path too
+	targetPath, err := resolveTreeSymlinks(tree, filePath)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	if targetPath != filePath {
+		if err := validate(targetPath); err != nil {
+			return nil, nil, true, err
+		}
+	}
+
+	entry, err := tree.FindEntry(targetPath)
+	if stderrors.Is(err, object.ErrEntryNotFound) || stderrors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, nil, true, configNotFoundError(filePath)
+	}
+	if err == nil && entry.Mode == filemode.Dir {
+		return nil, nil, true, directoryPathError(filePath)
+	}
+	if err != nil || !entry.Mode.IsFile() || entry.Mode == filemode.Symlink {
+		return nil, nil, false, nil
+	}
+
+	file, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, nil, false, nil
+	}
+
+	// Check file size limit before reading the blob
+	if file.Size > maxSize {
+		return nil, nil, true, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration file too large: %d bytes (max %d)", file.Size, maxSize))
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, nil, true, errors.Wrap(err, "ARGUS_IO_ERROR",
+			fmt.Sprintf("failed to read configuration file: %s", filePath))
+	}
+	defer func() { _ = reader.Close() }()
+
+	fileContent, err := io.ReadAll(io.LimitReader(reader, maxSize))
+	if err != nil {
+		return nil, nil, true, errors.Wrap(err, "ARGUS_IO_ERROR",
+			fmt.Sprintf("failed to read configuration file: %s", filePath))
+	}
+
+	return fileContent, commit, true, nil
+}
+
+// resolveTreeSymlinks resolves the symbolic links along filePath within the tree and returns
+// the path of its target. Components are resolved one at a time, so links to directories
+// are followed too; a path that does not exist is returned as far as it was resolved.
+//
+// SECURITY: Link targets are resolved relative to the link's directory and must stay
+// within the repository; absolute targets and targets above the root are rejected.
+func resolveTreeSymlinks(tree *object.Tree, filePath string) (string, error) {
+	remaining := strings.Split(path.Clean(filePath), "/")
+	resolved := ""
+	hops := 0
+
+	for len(remaining) > 0 {
+		candidate := path.Join(resolved, remaining[0])
+		remaining = remaining[1:]
+
+		entry, err := tree.FindEntry(candidate)
+		if err != nil || entry.Mode != filemode.Symlink {
+			resolved = candidate
+			if err != nil {
+				return path.Join(append([]string{resolved}, remaining...)...), nil
+			}
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return "", errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("too many levels of symbolic links: %s", filePath))
+		}
+
+		target, err := readSymlinkTarget(tree, entry)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			return "", errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("symbolic link %s points to absolute path outside repository", candidate))
+		}
+		joined := path.Join(resolved, target)
+		if joined == ".." || strings.HasPrefix(joined, "../") {
+			return "", errors.New("ARGUS_SECURITY_ERROR",
+				fmt.Sprintf("symbolic link %s points outside repository root", candidate))
+		}
+
+		// Restart from the root with the target in place of the link
+		resolved = ""
+		if joined != "." {
+			remaining = append(strings.Split(joined, "/"), remaining...)
+		}
+	}
+
+	return resolved, nil
+}
+
+// readSymlinkTarget reads the target path stored in the blob of a symbolic link entry
+func readSymlinkTarget(tree *object.Tree, entry *object.TreeEntry) (string, error) {
+	file, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return "", errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read symbolic link")
+	}
+	if file.Size > maxPathLength {
+		return "", errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("symbolic link target too long: %d bytes (max %d)", file.Size, maxPathLength))
+	}
+
+	target, err := file.Contents()
+	if err != nil {
+		return "", errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read symbolic link")
+	}
+	return target, nil
+}
+
+// configNotFoundError reports a configuration file that does not exist at the loaded commit.
+// Its cause satisfies os.IsNotExist, as for a file missing on disk.
+func configNotFoundError(filePath string) error {
+	return errors.Wrap(&os.PathError{Op: "open", Path: filePath, Err: os.ErrNotExist},
+		"ARGUS_CONFIG_NOT_FOUND", fmt.Sprintf("configuration file not found: %s", filePath))
+}
+
+// directoryPathError reports a configuration file path that names a directory
+func directoryPathError(filePath string) error {
+	return errors.New("ARGUS_INVALID_CONFIG",
+		fmt.Sprintf("configuration path is a directory, not a file: %s", filePath))
+}
+
+// headTreeHasPath reports whether the tree of the checked-out commit contains the exact path.
+// It reports true when the commit cannot be read, leaving the decision to the filesystem.
+func headTreeHasPath(repo *git.Repository, filePath string) bool {
+	head, err := repo.Head()
+	if err != nil {
+		return true
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return true
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return true
+	}
+
+	_, err = tree.FindEntry(filePath)
+	return !stderrors.Is(err, object.ErrEntryNotFound) && !stderrors.Is(err, object.ErrDirectoryNotFound)
+}
+
+// resolveReferenceCommit resolves a reference to a commit in the clone, trying it as a
+// branch, tag, remote-tracking branch and commit hash in the order checkoutReference does.
+// Revision expressions resolve their base that way and walk to the ancestor.
+func resolveReferenceCommit(repo *git.Repository, reference string) (*object.Commit, bool) {
+	if isRevisionExpression(reference) {
+		base, steps, err := parseRevisionExpression(reference)
+		if err != nil {
+			return nil, false
+		}
+		commit, ok := resolveReferenceCommit(repo, base)
+		if !ok {
+			return nil, false
+		}
+		commit, err = walkRevision(commit, steps)
+		return commit, err == nil
+	}
+
+	var hash plumbing.Hash
+	switch {
+	case reference == "" || reference == "main" || reference == "master":
+		// The clone's HEAD is the cloned branch
+		head, err := repo.Head()
+		if err != nil {
+			return nil, false
+		}
+		hash = head.Hash()
+	case isCommitHash(reference):
+		hash = plumbing.NewHash(reference)
+	default:
+		candidates := []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(reference),
+			plumbing.NewTagReferenceName(reference),
+			plumbing.NewRemoteReferenceName("origin", reference),
+		}
+		if name, ok := qualifiedRefName(reference); ok {
+			candidates = []plumbing.ReferenceName{name}
+		}
+		for _, name := range candidates {
+			if ref, err := repo.Reference(name, true); err == nil {
+				hash = ref.Hash()
+				break
+			}
+		}
+		if hash.IsZero() && isCommitLike(reference) {
+			if resolved, err := repo.ResolveRevision(plumbing.Revision(reference)); err == nil {
+				hash = *resolved
+			}
+		}
+		if hash.IsZero() {
+			return nil, false
+		}
+	}
+
+	// Annotated tags point at a tag object rather than the commit
+	if tag, err := repo.TagObject(hash); err == nil {
+		commit, err := tag.Commit()
+		return commit, err == nil
+	}
+	commit, err := repo.CommitObject(hash)
+	return commit, err == nil
+}
+
+// readConfigFile checks the reference out and reads and parses a configuration file from disk
+func (g *GitProvider) readConfigFile(repo *git.Repository, filePath string, format Format, reference string) (*LoadResult, error) {
 	// Get worktree
 	worktree, err := repo.Worktree()
 	if err != nil {
 		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to get repository worktree")
 	}
 
-	// Checkout specific reference if needed
-	if reference != "" && reference != "main" && reference != "master" {
-		err = g.checkoutReference(worktree, reference)
-		if err != nil {
-			return nil, err
-		}
+	// Loads clone without a checkout, so the reference is checked out here
+	if err := g.checkoutWorktree(repo, worktree, filePath, reference); err != nil {
+		return nil, err
 	}
 
+	return g.readWorktreeConfig(repo, worktree, filePath, format)
+}
+
+// readWorktreeConfig reads and parses a configuration file from the checked-out worktree.
+// A non-empty format overrides the file extension.
+func (g *GitProvider) readWorktreeConfig(repo *git.Repository, worktree *git.Worktree, filePath string, format Format) (*LoadResult, error) {
 	// Read file from worktree with secure path validation
 	rootPath := worktree.Filesystem.Root()
 	fullPath := filepath.Join(rootPath, filePath)
@@ -955,76 +2574,358 @@ func (g *GitProvider) readConfigFile(repo *git.Repository, filePath, reference s
 		return nil, errors.Wrap(err, "ARGUS_SECURITY_ERROR", "failed to resolve absolute path")
 	}
 
-	cleanRoot, err := filepath.Abs(rootPath)
+	cleanRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_SECURITY_ERROR", "failed to resolve repository root path")
+	}
+
+	if !strings.HasPrefix(cleanPath, cleanRoot+string(filepath.Separator)) {
+		return nil, errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("path traversal detected: %s is outside repository root", filePath))
+	}
+
+	// Git paths are case-sensitive even on case-insensitive filesystems, so the exact
+	// path must exist in the checked-out commit, not just match a file on disk
+	if !headTreeHasPath(repo, filePath) {
+		return nil, configNotFoundError(filePath)
+	}
+
+	// SECURITY: Re-validate the final target of symbolic links against the real root
+	realPath, err := filepath.EvalSymlinks(cleanPath)
+	if os.IsNotExist(err) {
+		return nil, configNotFoundError(filePath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("failed to resolve symbolic links of %s", filePath))
+	}
+	realRoot, err := filepath.EvalSymlinks(cleanRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_SECURITY_ERROR", "failed to resolve repository root path")
+	}
+	relPath, err := filepath.Rel(realRoot, realPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return nil, errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("symbolic link %s points outside repository root", filePath))
+	}
+	if relPath == ".git" || strings.HasPrefix(relPath, ".git"+string(filepath.Separator)) {
+		return nil, errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("symbolic link %s points into the Git directory", filePath))
+	}
+
+	// A directory would fail to read with a platform-dependent error
+	if info, err := os.Stat(realPath); err == nil && info.IsDir() {
+		return nil, directoryPathError(filePath)
+	}
+
+	// #nosec G304 - Path and symbolic link target are validated above to prevent directory traversal
+	fileContent, err := os.ReadFile(realPath)
+	if os.IsNotExist(err) {
+		return nil, configNotFoundError(filePath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR",
+			fmt.Sprintf("failed to read configuration file: %s", filePath))
+	}
+
+	// Check file size limit
+	if len(fileContent) > maxConfigFileSize {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration file too large: %d bytes (max %d)", len(fileContent), maxConfigFileSize))
+	}
+
+	// Parse configuration based on the format or file extension
+	config, err := g.parseConfigFileAs(filePath, format, fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoadResult{
+		Config: config,
+		Format: resultFormat(filePath, format),
+		Size:   len(fileContent),
+	}
+	if g.options.diskCacheDir != "" {
+		result.content = fileContent
+	}
+
+	// Record the commit the worktree was read from
+	if head, err := repo.Head(); err == nil {
+		result.CommitHash = head.Hash().String()
+	}
+
+	return result, nil
+}
+
+// sparseCheckoutDir returns the sparse checkout pattern covering a configuration file:
+// its directory with a trailing slash, or the file itself at the repository root
+func sparseCheckoutDir(filePath string) string {
+	dir := path.Dir(filePath)
+	if dir == "." {
+		return filePath
+	}
+	return dir + "/"
+}
+
+// checkoutWorktree checks out the reference in a clone made without a worktree. With
+// sparse checkout enabled only the directory of filePath is written; go-git's sparse
+// checkout does not handle every repository, so a failed one falls back to a full checkout.
+func (g *GitProvider) checkoutWorktree(repo *git.Repository, worktree *git.Worktree, filePath, reference string) error {
+	checkout := func(sparseDirs []string) error {
+		if reference != "" && reference != "main" && reference != "master" {
+			return g.checkoutReference(repo, worktree, reference, sparseDirs)
+		}
+
+		// The clone left HEAD at the cloned branch without checking it out
+		head, err := repo.Head()
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to resolve repository HEAD")
+		}
+		resetOptions := &git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}
+		if sparseDirs == nil {
+			err = worktree.Reset(resetOptions)
+		} else {
+			err = worktree.ResetSparsely(resetOptions, sparseDirs)
+		}
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to checkout repository HEAD")
+		}
+		return nil
+	}
+
+	if g.options.sparseCheckout {
+		if err := checkout([]string{sparseCheckoutDir(filePath)}); err == nil {
+			return nil
+		}
+	}
+	return checkout(nil)
+}
+
+// listConfigsFromRepo clones the repository and lists loadable configuration files at the reference
+func (g *GitProvider) listConfigsFromRepo(ctx context.Context, gitURL *GitURL) ([]string, error) {
+	gitURL, err := g.resolveTagConstraint(ctx, gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := g.createTempDirectory()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to create temporary directory")
+	}
+	defer g.removeTempDirectory(tempDir)
+
+	repo, err := g.cloneRepository(ctx, gitURL, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepository(repo)
+
+	// Move HEAD to the requested reference the same way configuration loads do
+	if reference := gitURL.Reference; reference != "" && reference != "main" && reference != "master" {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to get repository worktree")
+		}
+		if err := g.checkoutReference(repo, worktree, reference, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to resolve repository HEAD")
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read HEAD commit")
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to read repository tree")
+	}
+	defer files.Close()
+
+	var configs []string
+	err = files.ForEach(func(file *object.File) error {
+		if _, ok := lookupFormat(file.Name); !ok || file.Size > maxConfigFileSize {
+			return nil
+		}
+		// SECURITY: Only report paths that a configuration URL would accept
+		if validateConfigPath(file.Name, g.allowedExtensions(), g.sensitivePaths()) != nil {
+			return nil
+		}
+
+		if len(configs) >= maxListedConfigs {
+			return errors.New("ARGUS_RESOURCE_LIMIT",
+				fmt.Sprintf("too many configuration files in repository (max %d)", maxListedConfigs))
+		}
+		configs = append(configs, file.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(configs)
+	return configs, nil
+}
+
+// ParseConfig decodes configuration content as a loaded file at filePath would be
+// decoded: as format, or by the extension of filePath when format is empty, with
+// decompression, byte order mark handling, the provider's decoding options and the
+// same size and nesting limits. It is useful for checking content before committing
+// it, and for providers serving configurations from elsewhere, such as test doubles.
+func (g *GitProvider) ParseConfig(filePath string, format Format, content []byte) (map[string]interface{}, error) {
+	if len(content) > maxConfigFileSize {
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration file too large: %d bytes (max %d)", len(content), maxConfigFileSize))
+	}
+	return g.parseConfigFileAs(filePath, format, content)
+}
+
+// parseConfigFile parses configuration content with the decoder registered for its extension
+func (g *GitProvider) parseConfigFile(filePath string, content []byte) (map[string]interface{}, error) {
+	return g.parseConfigFileAs(filePath, "", content)
+}
+
+// parseConfigFileAs parses configuration content as format, or with the decoder
+// registered for its extension when format is empty
+func (g *GitProvider) parseConfigFileAs(filePath string, format Format, content []byte) (map[string]interface{}, error) {
+	start := time.Now()
+	defer func() {
+		g.metrics.addParseTime(time.Since(start))
+	}()
+
+	innerPath, compressed := uncompressedPath(filePath)
+	decoder, ok := lookupFormat(filePath)
+	if format != "" {
+		decoder, ok = lookupFormatName(string(format))
+	}
+	if !ok {
+		return nil, errors.New("ARGUS_UNSUPPORTED_FORMAT",
+			fmt.Sprintf("unsupported configuration file format: %s (supported: %s)",
+				strings.ToLower(filepath.Ext(innerPath)), strings.Join(registeredExtensions(), ", ")))
+	}
+
+	// Compressed files are decoded as their inner format
+	if compressed {
+		var err error
+		if content, err = decompressConfig(content); err != nil {
+			return nil, err
+		}
+	}
+
+	// Editors may prefix UTF-8 with a byte order mark or save UTF-16, which decoders reject
+	content, err := decodeTextEncoding(content)
 	if err != nil {
-		return nil, errors.Wrap(err, "ARGUS_SECURITY_ERROR", "failed to resolve repository root path")
+		return nil, err
 	}
 
-	if !strings.HasPrefix(cleanPath, cleanRoot+string(filepath.Separator)) {
-		return nil, errors.New("ARGUS_SECURITY_ERROR",
-			fmt.Sprintf("path traversal detected: %s is outside repository root", filePath))
+	// An intentionally blank file is an empty configuration in every built-in format
+	if decoder.builtin != nil && len(bytes.TrimSpace(content)) == 0 {
+		return make(map[string]interface{}), nil
 	}
 
-	// #nosec G304 - Path is validated above to prevent directory traversal
-	fileContent, err := os.ReadFile(cleanPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "ARGUS_IO_ERROR",
-			fmt.Sprintf("failed to read configuration file: %s", filePath))
+	// Built-in decoders read text; registered decoders may accept binary formats
+	if decoder.builtin != nil {
+		if err := checkTextContent(content); err != nil {
+			g.logEvent(context.Background(), slog.LevelWarn, "configuration parse failed",
+				slog.String("file", filePath), logErrorAttr(err))
+			return nil, err
+		}
 	}
 
-	// Check file size limit
-	if len(fileContent) > maxConfigFileSize {
-		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
-			fmt.Sprintf("configuration file too large: %d bytes (max %d)", len(fileContent), maxConfigFileSize))
+	config, err := decoder.decodeWith(content, &g.options)
+	if err != nil && !errors.HasCode(err, "ARGUS_PARSE_ERROR") && !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+		err = errors.Wrap(err, "ARGUS_PARSE_ERROR",
+			fmt.Sprintf("failed to parse %s configuration", strings.ToUpper(string(decoder.format))))
 	}
 
-	// Parse configuration based on file extension
-	config, err := g.parseConfigFile(filePath, fileContent)
+	// SECURITY: Bound nesting for every decoder, since merging and diffing recurse over it
+	if err == nil {
+		err = checkConfigDepth(config, 0)
+	}
 	if err != nil {
+		g.logEvent(context.Background(), slog.LevelWarn, "configuration parse failed",
+			slog.String("file", filePath), logErrorAttr(err))
 		return nil, err
 	}
+	g.logEvent(context.Background(), slog.LevelDebug, "configuration parsed",
+		slog.String("file", filePath), slog.String("format", string(decoder.format)), slog.Int("bytes", len(content)))
+
+	// Content without values (e.g. comments only) decodes to a nil map in some formats
+	if config == nil {
+		config = make(map[string]interface{})
+	}
 
 	return config, nil
 }
 
-// parseConfigFile parses configuration content based on file extension
-func (g *GitProvider) parseConfigFile(filePath string, content []byte) (map[string]interface{}, error) {
-	var config map[string]interface{}
+// formatForPath determines the configuration format from a file extension
+func formatForPath(filePath string) Format {
+	decoder, _ := lookupFormat(filePath)
+	return decoder.format
+}
 
-	// Determine format from file extension
-	ext := strings.ToLower(filepath.Ext(filePath))
+// resultFormat returns the explicit format of a file, or the format of its extension
+func resultFormat(filePath string, format Format) Format {
+	if format != "" {
+		return format
+	}
+	return formatForPath(filePath)
+}
 
-	switch ext {
-	case ".json":
-		err := json.Unmarshal(content, &config)
+// checkoutReference checks out a specific Git reference (branch, tag, commit).
+// With sparseDirs, only files under those directories are written to the worktree.
+func (g *GitProvider) checkoutReference(repo *git.Repository, worktree *git.Worktree, reference string, sparseDirs []string) error {
+	// Full commit hashes cannot be branch or tag names worth trying first
+	if isCommitHash(reference) {
+		err := worktree.Checkout(&git.CheckoutOptions{
+			Hash:                      plumbing.NewHash(reference),
+			SparseCheckoutDirectories: sparseDirs,
+		})
 		if err != nil {
-			return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "failed to parse JSON configuration")
+			return errors.Wrap(err, "ARGUS_GIT_ERROR",
+				fmt.Sprintf("failed to checkout commit: %s", reference))
 		}
-	case ".yaml", ".yml":
-		// Use proper YAML parsing
-		err := yaml.Unmarshal(content, &config)
-		if err != nil {
-			return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "failed to parse YAML configuration")
+		return nil
+	}
+
+	// A revision expression is checked out at the ancestor it resolves to
+	if isRevisionExpression(reference) {
+		commit, ok := resolveReferenceCommit(repo, reference)
+		if !ok {
+			return errors.New("ARGUS_GIT_ERROR",
+				fmt.Sprintf("failed to resolve revision: %s", reference))
 		}
-	case ".toml":
-		// Use TOML parsing
-		err := toml.Unmarshal(content, &config)
+		err := worktree.Checkout(&git.CheckoutOptions{
+			Hash:                      commit.Hash,
+			SparseCheckoutDirectories: sparseDirs,
+		})
 		if err != nil {
-			return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "failed to parse TOML configuration")
+			return errors.Wrap(err, "ARGUS_GIT_ERROR",
+				fmt.Sprintf("failed to checkout revision: %s", reference))
 		}
-	default:
-		return nil, errors.New("ARGUS_UNSUPPORTED_FORMAT",
-			fmt.Sprintf("unsupported configuration file format: %s (supported: .json, .yaml, .yml, .toml)", ext))
+		return nil
 	}
 
-	return config, nil
-}
+	// A fully qualified reference is checked out as named
+	if name, ok := qualifiedRefName(reference); ok {
+		err := worktree.Checkout(&git.CheckoutOptions{
+			Branch:                    name,
+			SparseCheckoutDirectories: sparseDirs,
+		})
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_GIT_ERROR",
+				fmt.Sprintf("failed to checkout reference: %s", reference))
+		}
+		return nil
+	}
 
-// checkoutReference checks out a specific Git reference (branch, tag, commit)
-func (g *GitProvider) checkoutReference(worktree *git.Worktree, reference string) error {
 	// Try as branch name first
 	err := worktree.Checkout(&git.CheckoutOptions{
-		Branch: plumbing.ReferenceName("refs/heads/" + reference),
+		Branch:                    plumbing.ReferenceName("refs/heads/" + reference),
+		SparseCheckoutDirectories: sparseDirs,
 	})
 	if err == nil {
 		return nil
@@ -1032,20 +2933,29 @@ func (g *GitProvider) checkoutReference(worktree *git.Worktree, reference string
 
 	// Try as tag
 	err = worktree.Checkout(&git.CheckoutOptions{
-		Branch: plumbing.ReferenceName("refs/tags/" + reference),
+		Branch:                    plumbing.ReferenceName("refs/tags/" + reference),
+		SparseCheckoutDirectories: sparseDirs,
 	})
 	if err == nil {
 		return nil
 	}
 
-	// Try as commit hash
-	if len(reference) >= 7 { // Minimum viable commit hash length
-		hash := plumbing.NewHash(reference)
-		err = worktree.Checkout(&git.CheckoutOptions{
-			Hash: hash,
-		})
-		if err == nil {
-			return nil
+	// Try as a branch that was cloned as a remote-tracking branch only
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Branch:                    plumbing.NewRemoteReferenceName("origin", reference),
+		SparseCheckoutDirectories: sparseDirs,
+	})
+	if err == nil {
+		return nil
+	}
+
+	// Try as abbreviated commit hash
+	if isCommitLike(reference) {
+		if hash, err := repo.ResolveRevision(plumbing.Revision(reference)); err == nil {
+			err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash, SparseCheckoutDirectories: sparseDirs})
+			if err == nil {
+				return nil
+			}
 		}
 	}
 
@@ -1055,18 +2965,30 @@ func (g *GitProvider) checkoutReference(worktree *git.Worktree, reference string
 
 // getAuthentication creates authentication object based on GitURL auth data
 func (g *GitProvider) getAuthentication(gitURL *GitURL) (transport.AuthMethod, error) {
+	// The git daemon protocol is anonymous: go-git rejects any authentication for it,
+	// so git:// mirrors of an authenticated repository are fetched without credentials
+	if isGitProtocolURL(gitURL.RepoURL) {
+		return nil, nil
+	}
+
+	gitURL = g.withCredential(gitURL)
 	if gitURL.AuthType == "" {
-		return nil, nil // No authentication
+		return g.withHTTPRoute(gitURL, g.withHTTPHeaders(gitURL, nil)), nil // No authentication beyond custom headers
 	}
 
-	// Check cache first
+	// Check cache first; request-scoped overrides bypass the shared cache
 	cacheKey := fmt.Sprintf("%s:%s", gitURL.AuthType, gitURL.RepoURL)
-	g.authCacheMutex.RLock()
-	if auth, exists := g.authCache[cacheKey]; exists {
+	if gitURL.credentialID != "" {
+		cacheKey += "#" + gitURL.credentialID
+	}
+	if !gitURL.authOverride {
+		g.authCacheMutex.RLock()
+		if auth, exists := g.authCache[cacheKey]; exists {
+			g.authCacheMutex.RUnlock()
+			return auth, nil
+		}
 		g.authCacheMutex.RUnlock()
-		return auth, nil
 	}
-	g.authCacheMutex.RUnlock()
 
 	var auth transport.AuthMethod
 	var err error
@@ -1074,11 +2996,34 @@ func (g *GitProvider) getAuthentication(gitURL *GitURL) (transport.AuthMethod, e
 	switch gitURL.AuthType {
 	case "token":
 		if token, exists := gitURL.AuthData["token"]; exists {
+			// Bitbucket Cloud access tokens authenticate with a fixed username
+			username := "token"
+			if isBitbucketCloudHost(gitURL.RepoURL) {
+				username = bitbucketTokenUsername
+			}
 			auth = &http.BasicAuth{
-				Username: "token",
+				Username: username,
 				Password: token,
 			}
 		}
+	case "bearer":
+		// OAuth bearer tokens are sent as "Authorization: Bearer <token>"
+		if token := gitURL.AuthData["token"]; token != "" {
+			auth = &http.TokenAuth{Token: token}
+		}
+	case "gitea":
+		// Gitea and Forgejo access tokens are sent as "Authorization: token <token>",
+		// which both accept for Git over HTTP as well as for their APIs
+		if token := gitURL.AuthData["token"]; token != "" {
+			auth = &headerAuth{headers: map[string]string{"Authorization": "token " + token}}
+		}
+	case "header":
+		if name := gitURL.AuthData["header_name"]; name != "" {
+			if err := validateHTTPHeader(name, gitURL.AuthData["header_value"]); err != nil {
+				return nil, err
+			}
+			auth = &headerAuth{headers: map[string]string{name: gitURL.AuthData["header_value"]}}
+		}
 	case "basic":
 		username := gitURL.AuthData["username"]
 		password := gitURL.AuthData["password"]
@@ -1092,16 +3037,21 @@ func (g *GitProvider) getAuthentication(gitURL *GitURL) (transport.AuthMethod, e
 		keyPath := gitURL.AuthData["keypath"]
 		if keyPath != "" {
 			// Validate SSH key file permissions for security
-			if info, err := os.Stat(keyPath); err != nil {
-				return nil, errors.New("ARGUS_AUTH_ERROR", "SSH key file not accessible")
-			} else if info.Mode().Perm() > 0o600 {
-				return nil, errors.New("ARGUS_SECURITY_ERROR", "SSH key file permissions too open (should be 0600 or less)")
+			if err := validateSecretFile(keyPath, "SSH key file"); err != nil {
+				return nil, err
 			}
 
+			// A passphrase in the URL takes precedence over the configured passphrase source
 			passphrase := gitURL.AuthData["passphrase"]
-			auth, err = ssh.NewPublicKeysFromFile("git", keyPath, passphrase)
+			if passphrase == "" && g.options.sshPassphrase != nil {
+				if passphrase, err = g.options.sshPassphrase(keyPath); err != nil {
+					return nil, errors.Wrap(redactSecret(err, passphrase), "ARGUS_AUTH_ERROR", "failed to obtain SSH key passphrase")
+				}
+			}
+
+			auth, err = ssh.NewPublicKeysFromFile(g.sshUser(gitURL.RepoURL), keyPath, passphrase)
 			if err != nil {
-				return nil, errors.Wrap(err, "ARGUS_AUTH_ERROR", "failed to load SSH key")
+				return nil, errors.Wrap(redactSecret(err, passphrase), "ARGUS_AUTH_ERROR", "failed to load SSH key")
 			}
 		}
 	default:
@@ -1109,30 +3059,330 @@ func (g *GitProvider) getAuthentication(gitURL *GitURL) (transport.AuthMethod, e
 			fmt.Sprintf("unsupported authentication type: %s", gitURL.AuthType))
 	}
 
+	auth = g.withHTTPRoute(gitURL, g.withHTTPHeaders(gitURL, auth))
+
 	// Cache the authentication object
-	if auth != nil {
+	if auth != nil && !gitURL.authOverride {
 		g.authCacheMutex.Lock()
-		g.authCache[cacheKey] = auth
+		if g.authCache != nil { // Released by Close
+			g.authCache[cacheKey] = auth
+		}
 		g.authCacheMutex.Unlock()
 	}
 
 	return auth, nil
 }
 
+// redactSecret returns err with every occurrence of secret masked, so that a
+// passphrase echoed by a key parser or passphrase source never reaches callers or logs
+func redactSecret(err error, secret string) error {
+	if secret == "" || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return stderrors.New(strings.ReplaceAll(err.Error(), secret, maskedAuthValue))
+}
+
+// sshUser returns the SSH username of a repository: the URL's user info
+// ("ssh://deploy@host/repo.git" or "deploy@host:repo.git"), else the WithSSHUser
+// username, else "git"
+func (g *GitProvider) sshUser(repoURL string) string {
+	if parsedURL, err := url.Parse(repoURL); err == nil && parsedURL.Scheme != "" {
+		if parsedURL.User != nil && parsedURL.User.Username() != "" {
+			return parsedURL.User.Username()
+		}
+	} else if at := strings.Index(repoURL, "@"); at > 0 && at < strings.Index(repoURL, ":") {
+		return repoURL[:at] // SCP-like syntax
+	}
+
+	return cmp.Or(g.options.sshUser, defaultSSHUser)
+}
+
+// headerAuth is an HTTP AuthMethod that injects arbitrary request headers and
+// clone URL query parameters, optionally on top of another HTTP authentication method.
+type headerAuth struct {
+	headers map[string]string
+	query   url.Values
+	inner   http.AuthMethod
+}
+
+// SetAuth applies the wrapped authentication, custom headers, and query parameters to the request
+func (a *headerAuth) SetAuth(r *gohttp.Request) {
+	if a == nil {
+		return
+	}
+	if a.inner != nil {
+		a.inner.SetAuth(r)
+	}
+	for name, value := range a.headers {
+		r.Header.Set(name, value)
+	}
+	if len(a.query) > 0 {
+		query := r.URL.Query()
+		for key, values := range a.query {
+			if !query.Has(key) { // Never override Git protocol parameters such as "service"
+				query[key] = values
+			}
+		}
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
+// Name returns the name of the authentication method
+func (a *headerAuth) Name() string {
+	return "http-header-auth"
+}
+
+// String returns a description of the method with header values redacted
+func (a *headerAuth) String() string {
+	names := make([]string, 0, len(a.headers))
+	for name := range a.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	redacted := make([]string, 0, len(names))
+	for _, name := range names {
+		redacted = append(redacted, name+": *******")
+	}
+
+	description := fmt.Sprintf("%s - %s", a.Name(), strings.Join(redacted, ", "))
+	if a.inner != nil {
+		description += " + " + a.inner.String()
+	}
+	return description
+}
+
+// withHTTPHeaders wraps an HTTP authentication method with the provider-level custom headers
+// and the pass-through query parameters of the repository URL.
+// SSH transports cannot carry headers, so non-HTTP repositories are returned unchanged.
+func (g *GitProvider) withHTTPHeaders(gitURL *GitURL, auth transport.AuthMethod) transport.AuthMethod {
+	if !isHTTPRepoURL(gitURL.RepoURL) {
+		return auth
+	}
+
+	query := repoURLQuery(gitURL.RepoURL)
+	if len(g.options.httpHeaders) == 0 && len(query) == 0 {
+		return auth
+	}
+
+	wrapped := &headerAuth{headers: g.options.httpHeaders, query: query}
+	switch inner := auth.(type) {
+	case nil:
+	case *headerAuth:
+		// Per-URL headers take precedence over provider-level headers
+		merged := make(map[string]string, len(g.options.httpHeaders)+len(inner.headers))
+		for name, value := range g.options.httpHeaders {
+			merged[name] = value
+		}
+		for name, value := range inner.headers {
+			merged[name] = value
+		}
+		wrapped.headers = merged
+		wrapped.inner = inner.inner
+	case http.AuthMethod:
+		wrapped.inner = inner
+	default:
+		return auth
+	}
+
+	return wrapped
+}
+
+// transportURL returns the repository URL handed to go-git. go-git appends protocol
+// paths after the raw query, so pass-through query parameters are stripped here and
+// re-applied to each HTTP request by headerAuth instead.
+func transportURL(repoURL string) string {
+	if qPos := strings.Index(repoURL, "?"); qPos != -1 {
+		return repoURL[:qPos]
+	}
+	return repoURL
+}
+
+// repoURLQuery returns the pass-through query parameters of a repository URL
+func repoURLQuery(repoURL string) url.Values {
+	qPos := strings.Index(repoURL, "?")
+	if qPos == -1 {
+		return nil
+	}
+	query, err := url.ParseQuery(repoURL[qPos+1:])
+	if err != nil {
+		return nil
+	}
+	return query
+}
+
+// isHTTPRepoURL reports whether a repository URL uses an HTTP-based transport
+func isHTTPRepoURL(repoURL string) bool {
+	lower := strings.ToLower(repoURL)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// isGitProtocolURL reports whether a repository URL uses the git daemon protocol,
+// which is read-only and has no authentication
+func isGitProtocolURL(repoURL string) bool {
+	return strings.HasPrefix(strings.ToLower(repoURL), "git://")
+}
+
+// validateHTTPHeader rejects header names and values that could split or smuggle requests
+func validateHTTPHeader(name, value string) error {
+	if name == "" {
+		return errors.New("ARGUS_INVALID_CONFIG", "HTTP header name cannot be empty")
+	}
+
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("invalid character in HTTP header name: %q", name))
+		}
+	}
+
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("HTTP header %s contains forbidden control characters", name))
+	}
+
+	return nil
+}
+
+// isBitbucketCloudHost reports whether a repository URL points to Bitbucket Cloud
+func isBitbucketCloudHost(repoURL string) bool {
+	host := repoHostname(repoURL)
+	return host == "bitbucket.org" || strings.HasSuffix(host, ".bitbucket.org")
+}
+
+// repoHostname extracts the lowercase host name (without port) from a repository URL
+func repoHostname(repoURL string) string {
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
 // startWatching starts polling for repository changes
-func (g *GitProvider) startWatching(ctx context.Context, gitURL *GitURL, configChan chan<- map[string]interface{}) {
+func (g *GitProvider) startWatching(ctx context.Context, gitURL *GitURL, configChan chan<- map[string]interface{}, errChan chan<- error) {
 	defer close(configChan)
+	if errChan != nil {
+		defer close(errChan)
+	}
 	defer g.decrementWatchCount()
+	defer g.unregisterWatch(gitURL)
 
 	ticker := time.NewTicker(gitURL.PollInterval)
 	defer ticker.Stop()
 
-	// Load initial configuration
-	config, err := g.loadConfigFromRepo(ctx, gitURL)
-	if err == nil {
+	// Content at a pinned commit or of a blob can never change. Whether the watch is
+	// pinned is decided by the load that served it, which may be of a fallback branch.
+	// Immutable URLs are polled by loading them, as ls-remote cannot resolve commits.
+	immutable := gitURL.isImmutable()
+	servedPinned := func(result *LoadResult) bool {
+		return !result.Stale && (gitURL.Blob != "" || isCommitHash(revisionBase(result.Reference)))
+	}
+
+	// A reload of the commit last delivered is no change, as when a missing reference
+	// is served by the same fallback commit on every poll
+	var deliveredCommit, deliveredRef string
+	redelivery := func(result *LoadResult) bool {
+		return result.CommitHash != "" && result.CommitHash == deliveredCommit && result.Reference == deliveredRef
+	}
+	delivering := func(result *LoadResult) {
+		deliveredCommit, deliveredRef = result.CommitHash, result.Reference
+	}
+
+	// Deliveries whose content differs from the previous one are reported to the change hook
+	hooks := g.startChangeHooks(gitURL)
+	defer hooks.stop()
+	var delivered map[string]interface{}
+	var deliveredHash string
+	track := func(result *LoadResult) {
+		if hooks == nil {
+			return
+		}
+		hash := ConfigHash(result.Config)
+		if delivered != nil && hash != deliveredHash {
+			hooks.notify(delivered, result)
+		}
+		delivered, deliveredHash = result.Config, hash
+	}
+
+	// With watch_initial=false the first configuration loaded is not delivered; later
+	// loads are delivered only when their content differs from the last one seen
+	awaitingBaseline := gitURL.SkipInitial
+	var seenHash string
+	unchanged := func(result *LoadResult) bool {
+		if !gitURL.SkipInitial {
+			return false
+		}
+		hash := ConfigHash(result.Config)
+		if awaitingBaseline || hash == seenHash {
+			awaitingBaseline = false
+			seenHash = hash
+			track(result)
+			return true
+		}
+		seenHash = hash
+		return false
+	}
+
+	// Invalid configurations are reported once each, until a load succeeds again;
+	// failed reports whether the watch is to continue
+	var lastInvalid string
+	failed := func(err error) bool {
+		if err == nil {
+			lastInvalid = ""
+			return true
+		}
+		if !isInvalidConfigError(err) || err.Error() == lastInvalid {
+			return true
+		}
+		lastInvalid = err.Error()
+		if !g.reportInvalidConfig(ctx, gitURL, err, errChan) {
+			return true
+		}
+
+		empty := &LoadResult{Config: make(map[string]interface{})}
 		select {
-		case configChan <- config:
+		case configChan <- empty.Config:
+			awaitingBaseline, seenHash = false, ConfigHash(empty.Config)
+			delivering(empty)
+			track(empty)
+			return true
 		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// Load initial configuration
+	start := time.Now()
+	result, err := g.watchLoad(ctx, gitURL)
+	elapsed := time.Since(start)
+	if errors.HasCode(err, "ARGUS_PROVIDER_CLOSED") {
+		return
+	}
+	g.recordWatchLoad(gitURL, err)
+	if !failed(err) {
+		return
+	}
+	if err == nil {
+		// Record the commit that was loaded, so the first poll does not reload it
+		if result.CommitHash != "" {
+			g.updateRepoCache(gitURL.RepoURL, result.CommitHash)
+		}
+
+		if !unchanged(result) {
+			select {
+			case configChan <- result.Config:
+				g.audit(AuditOperationWatch, "", gitURL, result, nil, elapsed)
+				delivering(result)
+				track(result)
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if servedPinned(result) {
+			// Nothing to poll for: stay idle until the watch is cancelled
+			<-ctx.Done()
 			return
 		}
 	}
@@ -1141,12 +3391,43 @@ func (g *GitProvider) startWatching(ctx context.Context, gitURL *GitURL, configC
 	for {
 		select {
 		case <-ticker.C:
-			if g.hasRepositoryChanged(ctx, gitURL) {
-				newConfig, err := g.loadConfigFromRepo(ctx, gitURL)
+			// A pinned watch only gets here while its initial load keeps failing, or
+			// when a fallback branch served it
+			if atomic.LoadInt64(&g.closed) == 1 {
+				return
+			}
+			if immutable || g.hasRepositoryChanged(ctx, gitURL) {
+				start := time.Now()
+				newResult, err := g.watchLoad(ctx, gitURL)
+				elapsed := time.Since(start)
+				if errors.HasCode(err, "ARGUS_PROVIDER_CLOSED") {
+					return
+				}
+				g.recordWatchLoad(gitURL, err)
+				if !failed(err) {
+					return
+				}
+
+				// During an outage stale fallback serves the last-known-good
+				// configuration, which is no change to deliver
+				if err == nil && (newResult.Stale || redelivery(newResult)) {
+					continue
+				}
 				if err == nil {
-					select {
-					case configChan <- newConfig:
-					case <-ctx.Done():
+					if !unchanged(newResult) {
+						g.logEvent(ctx, slog.LevelInfo, "watched configuration reloaded",
+							append(logURLAttrs(gitURL), slog.String("commit", newResult.CommitHash))...)
+						select {
+						case configChan <- newResult.Config:
+							g.audit(AuditOperationWatch, "", gitURL, newResult, nil, elapsed)
+							delivering(newResult)
+							track(newResult)
+						case <-ctx.Done():
+							return
+						}
+					}
+					if servedPinned(newResult) {
+						<-ctx.Done()
 						return
 					}
 				}
@@ -1157,12 +3438,34 @@ func (g *GitProvider) startWatching(ctx context.Context, gitURL *GitURL, configC
 	}
 }
 
+// watchLoad loads the configuration for a watch as an in-flight operation.
+// Once the provider is closed it fails with ARGUS_PROVIDER_CLOSED, ending the watch.
+func (g *GitProvider) watchLoad(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	if !g.enterOperation() {
+		return nil, errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+	}
+	defer g.exitOperation()
+
+	result, err := g.loadConfigFromRepo(ctx, gitURL)
+	if err == nil {
+		err = g.checkRequiredKeys(result.Config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // hasRepositoryChanged checks if the repository has new commits using git ls-remote
 func (g *GitProvider) hasRepositoryChanged(ctx context.Context, gitURL *GitURL) bool {
 	// Create context with timeout to prevent hanging
-	lsCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	lsCtx, cancel := context.WithTimeout(ctx, g.options.effectiveLsRemoteTimeout())
 	defer cancel()
 
+	if gitURL.Release != "" {
+		return g.hasReleaseAssetChanged(lsCtx, gitURL)
+	}
+
 	// Get current commit hash for the reference
 	currentCommit, err := g.getRemoteCommitHash(lsCtx, gitURL)
 	if err != nil {
@@ -1185,73 +3488,239 @@ func (g *GitProvider) hasRepositoryChanged(ctx context.Context, gitURL *GitURL)
 	return false
 }
 
-// getRemoteCommitHash uses git ls-remote to get the latest commit hash for a reference with retry
-func (g *GitProvider) getRemoteCommitHash(ctx context.Context, gitURL *GitURL) (string, error) {
-	var commitHash string
+// tryMirrors runs operation against the repository, then against each of its mirrors in
+// order for as long as the previous repository is unreachable. A mirror that succeeds is
+// recorded in the metrics.
+func (g *GitProvider) tryMirrors(gitURL *GitURL, operation func(target *GitURL) error) error {
+	operation = g.rejectHostCredential(g.anonymousFallback(operation))
+	err := operation(gitURL)
 
-	err := g.retryOperation(ctx, func() error {
-		// Use go-git's Remote to list references without cloning
-		// This is much more efficient than full clone for checking changes
+	for _, mirror := range gitURL.Mirrors {
+		if err == nil || !isRemoteUnavailableError(err) {
+			break
+		}
 
-		// Create a remote reference pointing to the repository
-		storage := memory.NewStorage()
-		remote := git.NewRemote(storage, &config.RemoteConfig{
-			Name: "origin",
-			URLs: []string{gitURL.RepoURL},
-		})
+		mirrorURL := *gitURL
+		mirrorURL.RepoURL = mirror
+		mirrorURL.Mirrors = nil
+		g.logEvent(context.Background(), slog.LevelWarn, "repository unreachable, trying mirror",
+			slog.String("repo", redactLogText(gitURL.RepoURL)), slog.String("mirror", redactLogText(mirror)),
+			logErrorAttr(err))
 
-		// Set authentication if available
-		var auth transport.AuthMethod
-		if authMethod, err := g.getAuthentication(gitURL); err == nil && authMethod != nil {
-			auth = authMethod
+		if err = operation(&mirrorURL); err == nil {
+			g.metrics.recordMirrorServe(mirror)
 		}
+	}
 
-		// List remote references (equivalent to git ls-remote)
-		refs, err := remote.ListContext(ctx, &git.ListOptions{
-			Auth: auth,
-		})
-		if err != nil {
-			return errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to list remote references")
+	return err
+}
+
+// anonymousFallback wraps a Git operation so that, with WithAnonymousFallback, an HTTP(S)
+// attempt rejected for its credentials is repeated without them. The anonymous attempt's
+// error is discarded, so a repository that also rejects anonymous access reports the
+// same error as without the fallback.
+func (g *GitProvider) anonymousFallback(operation func(target *GitURL) error) func(target *GitURL) error {
+	if !g.options.anonFallback {
+		return operation
+	}
+
+	return func(target *GitURL) error {
+		err := operation(target)
+		if err == nil || g.withCredential(target).AuthType == "" || !isHTTPRepoURL(target.RepoURL) || !isCredentialError(err) {
+			return err
+		}
+
+		anonymous := *target
+		anonymous.AuthType = ""
+		anonymous.AuthData = make(map[string]string)
+		anonymous.anonymous = true
+		if operation(&anonymous) != nil {
+			return err
 		}
 
-		// Find the commit hash for our target reference
-		targetRef := fmt.Sprintf("refs/heads/%s", gitURL.Reference)
+		g.logEvent(context.Background(), slog.LevelWarn, "credentials rejected, repository accessed anonymously",
+			append(logURLAttrs(target), logErrorAttr(err))...)
+		return nil
+	}
+}
+
+// isCredentialError reports whether a Git server rejected the credentials of a request
+func isCredentialError(err error) bool {
+	return stderrors.Is(err, transport.ErrAuthenticationRequired) || stderrors.Is(err, transport.ErrAuthorizationFailed)
+}
+
+// listRemoteRefs lists the references of a repository without cloning (git ls-remote)
+func (g *GitProvider) listRemoteRefs(ctx context.Context, gitURL *GitURL) ([]*plumbing.Reference, error) {
+	g.metrics.incrementRemoteRefLookups()
+
+	// Use go-git's Remote to list references without cloning
+	// This is much more efficient than full clone for checking changes
+
+	// Create a remote reference pointing to the repository
+	storage := memory.NewStorage()
+	remote := git.NewRemote(storage, &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{transportURL(gitURL.RepoURL)},
+	})
+
+	// Set authentication if available
+	auth, err := g.getAuthentication(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// List remote references (equivalent to git ls-remote)
+	listOptions := &git.ListOptions{
+		Auth: auth,
+	}
+	listOptions.CABundle, listOptions.InsecureSkipTLS, listOptions.ProxyOptions = g.transportOptions(gitURL.RepoURL)
+
+	// Add timeout to context; each attempt of a retried lookup gets its own
+	lsCtx, cancel := context.WithTimeout(ctx, g.options.effectiveLsRemoteTimeout())
+	defer cancel()
+	refs, err := remote.ListContext(lsCtx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to list remote references")
+	}
+
+	return refs, nil
+}
+
+// getRemoteCommitHash uses git ls-remote to get the latest commit hash for a reference with retry
+func (g *GitProvider) getRemoteCommitHash(ctx context.Context, gitURL *GitURL) (string, error) {
+	var commitHash string
+
+	// Ancestors are not listed remotely; a revision expression resolves its base
+	gitURL = revisionBaseURL(gitURL)
+
+	// Mirrors are tried in order while the previous repository is unreachable
+	err := g.tryMirrors(gitURL, func(gitURL *GitURL) error {
+		return g.retryOperation(ctx, func() error {
+			refs, err := g.listRemoteRefs(ctx, gitURL)
+			if err != nil {
+				return err
+			}
 
-		// Also check for tags if it's not a branch
-		targetTagRef := fmt.Sprintf("refs/tags/%s", gitURL.Reference)
+			// A tag constraint resolves to the commit of the highest matching tag
+			if gitURL.TagConstraint != "" {
+				_, commitHash, err = matchTagConstraint(refs, gitURL.TagConstraint)
+				return err
+			}
 
-		for _, ref := range refs {
-			refName := ref.Name().String()
-			if refName == targetRef || refName == targetTagRef {
-				commitHash = ref.Hash().String()
+			// A fully qualified reference only matches itself
+			if name, ok := qualifiedRefName(gitURL.Reference); ok {
+				var found bool
+				if commitHash, found = findRemoteRef(refs, name); !found {
+					return errors.New("ARGUS_GIT_ERROR",
+						fmt.Sprintf("reference %s not found in remote repository", gitURL.Reference))
+				}
 				return nil
 			}
-		}
 
-		// If no exact match, try HEAD for default branch
-		for _, ref := range refs {
-			if ref.Name().String() == "HEAD" {
-				commitHash = ref.Hash().String()
+			// A bare name is resolved as a branch, then as a tag
+			branchHash, isBranch := findRemoteRef(refs, plumbing.NewBranchReferenceName(gitURL.Reference))
+			tagHash, isTag := findRemoteRef(refs, plumbing.NewTagReferenceName(gitURL.Reference))
+			if isBranch && isTag && g.options.strictRefs {
+				return errors.New("ARGUS_INVALID_CONFIG",
+					fmt.Sprintf("reference %s is ambiguous: it names both a branch and a tag (use refs/heads/%s or refs/tags/%s)",
+						gitURL.Reference, gitURL.Reference, gitURL.Reference))
+			}
+			if isBranch || isTag {
+				commitHash = cmp.Or(branchHash, tagHash)
 				return nil
 			}
-		}
 
-		return errors.New("ARGUS_GIT_ERROR",
-			fmt.Sprintf("reference %s not found in remote repository", gitURL.Reference))
-	}, "git ls-remote")
+			// Only the default branch resolves to HEAD. An abbreviated commit hash has
+			// no remote reference, and the default branch commit would be the wrong
+			// cache key for it or for a missing name.
+			if gitURL.Reference != "" && gitURL.Reference != "HEAD" {
+				return errors.New("ARGUS_GIT_ERROR",
+					fmt.Sprintf("reference %s not found in remote repository", gitURL.Reference))
+			}
+			for _, ref := range refs {
+				if ref.Name().String() == "HEAD" {
+					commitHash = ref.Hash().String()
+					return nil
+				}
+			}
+
+			return errors.New("ARGUS_GIT_ERROR",
+				fmt.Sprintf("reference %s not found in remote repository", gitURL.Reference))
+		}, "git ls-remote")
+	})
 
 	if err != nil {
 		return "", err
 	}
 
+	g.logEvent(ctx, slog.LevelDebug, "reference resolved",
+		append(logURLAttrs(gitURL), slog.String("commit", commitHash))...)
 	return commitHash, nil
 }
 
+// qualifiedRefName returns the reference name of a fully qualified reference, e.g.
+// refs/tags/main or refs/pull/123/head. Qualified references are used verbatim, while
+// bare names are tried as a branch and then as a tag.
+func qualifiedRefName(reference string) (plumbing.ReferenceName, bool) {
+	name := plumbing.ReferenceName(reference)
+	if strings.HasPrefix(reference, "refs/") && name.Validate() == nil {
+		return name, true
+	}
+	return "", false
+}
+
+// findRemoteRef returns the commit a remote reference points at. Annotated tags are also
+// listed peeled to the commit they tag, which is the commit a clone of the tag reads from.
+func findRemoteRef(refs []*plumbing.Reference, name plumbing.ReferenceName) (string, bool) {
+	var hash, peeledHash string
+	for _, ref := range refs {
+		switch ref.Name() {
+		case name:
+			hash = ref.Hash().String()
+		case name + "^{}":
+			peeledHash = ref.Hash().String()
+		}
+	}
+	return cmp.Or(peeledHash, hash), hash != "" || peeledHash != ""
+}
+
+// isCommitHash reports whether a reference is a full 40-character commit hash.
+// Abbreviated hashes are ambiguous and may collide with branch names, so they are
+// not treated as pinned.
+func isCommitHash(reference string) bool {
+	if len(reference) != 40 {
+		return false
+	}
+	for i := 0; i < len(reference); i++ {
+		if !isHexDigit(reference[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCommitLike reports whether a reference may be a full or abbreviated commit hash,
+// i.e. it consists of at least 7 hexadecimal digits
+func isCommitLike(reference string) bool {
+	if len(reference) < 7 || len(reference) > 40 {
+		return false
+	}
+	for i := 0; i < len(reference); i++ {
+		if !isHexDigit(reference[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // updateRepoCache updates the repository cache with new commit information
 func (g *GitProvider) updateRepoCache(repoURL, commitHash string) {
 	g.repoCacheMutex.Lock()
 	defer g.repoCacheMutex.Unlock()
 
+	if g.repoCache == nil { // Released by Close
+		return
+	}
 	g.repoCache[repoURL] = &repoMetadata{
 		LastCommit: commitHash,
 		LastCheck:  time.Now(),
@@ -1261,19 +3730,45 @@ func (g *GitProvider) updateRepoCache(repoURL, commitHash string) {
 // newConfigCache creates a new configuration cache with specified parameters
 func newConfigCache(maxSize int, ttl time.Duration) *configCache {
 	return &configCache{
-		entries: make(map[string]*configCacheEntry),
-		maxSize: maxSize,
-		ttl:     ttl,
+		entries:  make(map[string]*configCacheEntry),
+		lastGood: make(map[string]*configCacheEntry),
+		blobs:    make(map[string]*configCacheEntry),
+		maxSize:  maxSize,
+		ttl:      ttl,
 	}
 }
 
-// getCacheKey generates a unique cache key for a Git URL and commit hash
+// getCacheKey generates a unique cache key for a Git URL and commit hash.
+// The reference is part of the key because a missing reference resolves to the
+// remote HEAD commit, which must not be served from another reference's entry.
 func (c *configCache) getCacheKey(gitURL *GitURL, commitHash string) string {
-	return fmt.Sprintf("%s:%s:%s", gitURL.RepoURL, gitURL.FilePath, commitHash)
+	key := fmt.Sprintf("%s:%s:%s:%s", gitURL.RepoURL, gitURL.FilePath, gitURL.Reference, commitHash)
+	if gitURL.Blob != "" {
+		key = fmt.Sprintf("%s:blob:%s", gitURL.RepoURL, gitURL.Blob)
+	}
+	if gitURL.Format != "" {
+		key += ":format=" + string(gitURL.Format)
+	}
+	if gitURL.cacheScope != "" {
+		key += ":" + gitURL.cacheScope
+	}
+	if gitURL.requestScope != "" {
+		key += ":scope=" + gitURL.requestScope
+	}
+	return key
 }
 
 // get retrieves a configuration from the cache if it exists and is still valid
 func (c *configCache) get(gitURL *GitURL, commitHash string) (map[string]interface{}, bool) {
+	result, found := c.getResult(gitURL, commitHash)
+	if !found {
+		return nil, false
+	}
+	return result.Config, true
+}
+
+// getResult retrieves a cached load result if it exists and is still valid
+func (c *configCache) getResult(gitURL *GitURL, commitHash string) (*LoadResult, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -1284,7 +3779,7 @@ func (c *configCache) get(gitURL *GitURL, commitHash string) (map[string]interfa
 	}
 
 	// Check if cache entry has expired
-	if time.Since(entry.CachedAt) > c.ttl {
+	if !entry.Pinned && time.Since(entry.CachedAt) > c.ttl {
 		return nil, false
 	}
 
@@ -1292,11 +3787,22 @@ func (c *configCache) get(gitURL *GitURL, commitHash string) (map[string]interfa
 	atomic.AddInt64(&entry.AccessCount, 1)
 
 	// Return a copy to prevent modification of cached data
-	return c.copyConfig(entry.Config), true
+	return &LoadResult{
+		Config:     c.copyConfig(entry.Config),
+		Format:     entry.Format,
+		CommitHash: entry.CommitHash,
+		Size:       entry.Size,
+		Trailers:   entry.Trailers.clone(),
+	}, true
 }
 
 // put stores a configuration in the cache
 func (c *configCache) put(gitURL *GitURL, commitHash string, config map[string]interface{}) {
+	c.putResult(gitURL, commitHash, &LoadResult{Config: config, CommitHash: commitHash})
+}
+
+// putResult stores a load result in the cache
+func (c *configCache) putResult(gitURL *GitURL, commitHash string, result *LoadResult) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -1307,13 +3813,120 @@ func (c *configCache) put(gitURL *GitURL, commitHash string, config map[string]i
 		c.evictLRU()
 	}
 
+	// The commit used for the cache key is authoritative for the cached content,
+	// except for ancestors, which are cached under the commit of their base
+	resultCommit := result.CommitHash
+	if commitHash != "" && (resultCommit == "" || !isRevisionExpression(gitURL.Reference)) {
+		resultCommit = commitHash
+	}
+
 	// Store a copy to prevent modification of cached data
 	c.entries[key] = &configCacheEntry{
-		Config:      c.copyConfig(config),
-		CommitHash:  commitHash,
+		Config:      c.copyConfig(result.Config),
+		Format:      result.Format,
+		Size:        result.Size,
+		CommitHash:  resultCommit,
+		Trailers:    result.Trailers.clone(),
 		CachedAt:    time.Now(),
 		AccessCount: 1,
+		Pinned:      gitURL.isImmutable(),
+	}
+}
+
+// putLastGood records a successful load as the last-known-good configuration
+func (c *configCache) putLastGood(gitURL *GitURL, result *LoadResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := c.getCacheKey(gitURL, "")
+
+	// Replace the oldest entry when full
+	if _, exists := c.lastGood[key]; !exists && len(c.lastGood) >= c.maxSize {
+		var oldestKey string
+		var oldestTime time.Time
+		for k, entry := range c.lastGood {
+			if oldestKey == "" || entry.CachedAt.Before(oldestTime) {
+				oldestKey, oldestTime = k, entry.CachedAt
+			}
+		}
+		delete(c.lastGood, oldestKey)
+	}
+
+	c.lastGood[key] = &configCacheEntry{
+		Config:     c.copyConfig(result.Config),
+		Format:     result.Format,
+		Size:       result.Size,
+		CommitHash: result.CommitHash,
+		Trailers:   result.Trailers.clone(),
+		CachedAt:   time.Now(),
+	}
+}
+
+// getLastGood returns the last-known-good configuration if it was loaded within maxAge.
+// The result is marked as stale.
+func (c *configCache) getLastGood(gitURL *GitURL, maxAge time.Duration) (*LoadResult, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.lastGood[c.getCacheKey(gitURL, "")]
+	if !exists || time.Since(entry.CachedAt) > maxAge {
+		return nil, false
+	}
+
+	return &LoadResult{
+		Config:     c.copyConfig(entry.Config),
+		Format:     entry.Format,
+		CommitHash: entry.CommitHash,
+		Reference:  gitURL.Reference,
+		Size:       entry.Size,
+		Trailers:   entry.Trailers.clone(),
+		Stale:      true,
+		LoadedAt:   entry.CachedAt,
+	}, true
+}
+
+// putBlob records the configuration parsed from the blob a blob URL names
+func (c *configCache) putBlob(blobURL *GitURL, result *LoadResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := c.getCacheKey(blobURL, "")
+
+	// Replace the oldest entry when full
+	if _, exists := c.blobs[key]; !exists && len(c.blobs) >= c.maxSize {
+		var oldestKey string
+		var oldestTime time.Time
+		for k, entry := range c.blobs {
+			if oldestKey == "" || entry.CachedAt.Before(oldestTime) {
+				oldestKey, oldestTime = k, entry.CachedAt
+			}
+		}
+		delete(c.blobs, oldestKey)
+	}
+
+	c.blobs[key] = &configCacheEntry{
+		Config:   c.copyConfig(result.Config),
+		Format:   result.Format,
+		Size:     result.Size,
+		CachedAt: time.Now(),
+	}
+}
+
+// getBlob returns the configuration parsed from the blob a blob URL names
+func (c *configCache) getBlob(blobURL *GitURL) (*LoadResult, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.blobs[c.getCacheKey(blobURL, "")]
+	if !exists {
+		return nil, false
 	}
+
+	return &LoadResult{
+		Config: c.copyConfig(entry.Config),
+		Format: entry.Format,
+		Size:   entry.Size,
+	}, true
 }
 
 // evictLRU removes the least recently used cache entry
@@ -1353,15 +3966,42 @@ func (c *configCache) copyConfig(config map[string]interface{}) map[string]inter
 
 	copy := make(map[string]interface{}, len(config))
 	for k, v := range config {
-		// For simple types, direct assignment is fine
-		// For complex types, we'd need deeper copying, but for config data this is usually sufficient
-		copy[k] = v
+		copy[k] = copyConfigValue(v)
 	}
 	return copy
 }
 
+// copyConfigValue deep-copies the objects and arrays of a decoded configuration value.
+// Other values are kept as they are, so decoder types such as time.Time, int64 or TOML
+// local dates survive caching unchanged.
+func copyConfigValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copy := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copy[key] = copyConfigValue(item)
+		}
+		return copy
+	case map[interface{}]interface{}:
+		copy := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			copy[key] = copyConfigValue(item)
+		}
+		return copy
+	case []interface{}:
+		copy := make([]interface{}, len(v))
+		for i, item := range v {
+			copy[i] = copyConfigValue(item)
+		}
+		return copy
+	case []byte:
+		return append([]byte(nil), v...)
+	}
+	return value
+}
+
 // stats returns cache statistics for monitoring
-func (c *configCache) stats() map[string]interface{} {
+func (c *configCache) stats() ConfigCacheStats {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -1379,19 +4019,24 @@ func (c *configCache) stats() map[string]interface{} {
 		}
 	}
 
-	return map[string]interface{}{
-		"entries":      len(c.entries),
-		"max_size":     c.maxSize,
-		"total_access": totalAccess,
-		"oldest_entry": oldestEntry,
-		"newest_entry": newestEntry,
-		"ttl_seconds":  c.ttl.Seconds(),
+	return ConfigCacheStats{
+		Entries:     len(c.entries),
+		MaxSize:     c.maxSize,
+		TotalAccess: totalAccess,
+		OldestEntry: oldestEntry,
+		NewestEntry: newestEntry,
+		TTL:         c.ttl,
 	}
 }
 
 // retryOperation performs an operation with exponential backoff retry logic
+//
+// Retries stop when the attempts are used up, when the retry budget would be exceeded
+// by waiting for the next attempt, or when the next attempt would start after the
+// context deadline, whichever comes first.
 func (g *GitProvider) retryOperation(ctx context.Context, operation func() error, operationName string) error {
 	var lastErr error
+	start := time.Now()
 
 	for attempt := 0; attempt <= g.retryConfig.maxRetries; attempt++ {
 		// Perform the operation
@@ -1413,11 +4058,22 @@ func (g *GitProvider) retryOperation(ctx context.Context, operation func() error
 			return err // Don't retry non-retryable errors
 		}
 
-		g.metrics.incrementRetryAttempts()
-
 		// Calculate delay with exponential backoff
 		delay := g.calculateRetryDelay(attempt)
 
+		// Don't start an attempt that would begin past the retry budget or context deadline
+		if g.retryBudgetExceeded(ctx, start, delay) {
+			g.metrics.incrementRetryBudgetStops()
+			g.metrics.incrementRetryExhausted()
+			return errors.Wrap(lastErr, "ARGUS_RETRY_EXHAUSTED",
+				fmt.Sprintf("%s retry budget exhausted after %d attempts", operationName, attempt+1))
+		}
+
+		g.metrics.incrementRetryAttempts()
+		g.logEvent(ctx, slog.LevelWarn, "retrying git operation",
+			slog.String("operation", operationName), slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay), logErrorAttr(err))
+
 		// Wait for the delay or until context cancellation
 		select {
 		case <-time.After(delay):
@@ -1428,39 +4084,118 @@ func (g *GitProvider) retryOperation(ctx context.Context, operation func() error
 		}
 	}
 
+	g.metrics.incrementRetryAttemptStops()
+	g.metrics.incrementRetryExhausted()
 	return errors.Wrap(lastErr, "ARGUS_RETRY_EXHAUSTED",
 		fmt.Sprintf("%s failed after %d attempts", operationName, g.retryConfig.maxRetries+1))
 }
 
-// isRetryableError determines if an error is worth retrying
+// classifyRetryableError classifies errors by type. known is false when the error
+// matches no known type and must be classified by its text.
+func classifyRetryableError(err error) (retryable, known bool) {
+	// Configuration errors and blocked redirects fail the same way on every attempt
+	if errors.HasCode(err, "ARGUS_INVALID_CONFIG") || errors.HasCode(err, "ARGUS_SECURITY_ERROR") ||
+		errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+		return false, true
+	}
+
+	for _, target := range nonRetryableErrors {
+		if stderrors.Is(err, target) {
+			return false, true
+		}
+	}
+
+	// Certificate problems are configuration errors, even though they surface as url.Error
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	if stderrors.As(err, &certErr) || stderrors.As(err, &unknownAuthority) || stderrors.As(err, &hostnameErr) {
+		return false, true
+	}
+
+	// go-git wraps client errors without Unwrap support, so inspect them explicitly
+	var permanent *plumbing.PermanentError
+	if stderrors.As(err, &permanent) {
+		return false, true
+	}
+	var unexpected *plumbing.UnexpectedError
+	if stderrors.As(err, &unexpected) {
+		var httpErr *http.Err
+		if stderrors.As(unexpected.Err, &httpErr) && httpErr.Response != nil {
+			status := httpErr.StatusCode()
+			return status == gohttp.StatusRequestTimeout || status == gohttp.StatusTooManyRequests ||
+				status >= gohttp.StatusInternalServerError, true
+		}
+	}
+
+	// Timeouts, refused or reset connections and DNS failures
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return true, true
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true, true
+	}
+
+	return false, false
+}
+
+// errorChainText joins the messages of every error in the chain
+func errorChainText(err error) string {
+	var messages []string
+	for ; err != nil; err = stderrors.Unwrap(err) {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, ": ")
+}
+
+// retryBudgetExceeded reports whether an attempt started after waiting delay would
+// begin after the retry budget (measured from start) or the context deadline
+func (g *GitProvider) retryBudgetExceeded(ctx context.Context, start time.Time, delay time.Duration) bool {
+	next := time.Now().Add(delay)
+
+	if g.retryConfig.budget > 0 && next.Sub(start) > g.retryConfig.budget {
+		return true
+	}
+	if deadline, ok := ctx.Deadline(); ok && next.After(deadline) {
+		return true
+	}
+	return false
+}
+
+// nonRetryableErrors are sentinel errors that retrying cannot fix
+var nonRetryableErrors = []error{
+	transport.ErrAuthenticationRequired,
+	transport.ErrAuthorizationFailed,
+	transport.ErrRepositoryNotFound,
+	transport.ErrEmptyRemoteRepository,
+	transport.ErrInvalidAuthMethod,
+	plumbing.ErrObjectNotFound,
+	plumbing.ErrReferenceNotFound,
+	git.ErrRepositoryNotExists,
+	git.ErrBranchNotFound,
+	git.ErrTagNotFound,
+	git.NoMatchingRefSpecError{},
+	context.Canceled,
+}
+
+// isRetryableError determines if an error is worth retrying.
+// Known go-git, transport and network error types are classified first; substring
+// matching on the error text is only a fallback for errors without a known type.
 func (g *GitProvider) isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
-	errStr := strings.ToLower(err.Error())
+	if retryable, known := classifyRetryableError(err); known {
+		return retryable
+	}
 
-	// Network-related errors that are usually temporary
-	retryablePatterns := []string{
-		"connection refused",
-		"connection reset",
-		"connection timeout",
-		"network is unreachable",
-		"timeout",
-		"temporary failure",
-		"service unavailable",
-		"bad gateway",
-		"gateway timeout",
-		"too many requests",
-		"rate limit",
-		"dns",
-		"no such host",
-	}
-
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(errStr, pattern) {
-			return true
-		}
+	// go-errors messages omit their causes, so match against the whole chain
+	errStr := strings.ToLower(errorChainText(err))
+
+	if hasTransientErrorPattern(errStr) {
+		return true
 	}
 
 	// Non-retryable errors (authentication, not found, etc.)
@@ -1485,6 +4220,47 @@ func (g *GitProvider) isRetryableError(err error) bool {
 	return true
 }
 
+// transientErrorPatterns are message fragments of network-related errors that are usually temporary
+var transientErrorPatterns = []string{
+	"connection refused",
+	"connection reset",
+	"connection timeout",
+	"network is unreachable",
+	"timeout",
+	"temporary failure",
+	"service unavailable",
+	"bad gateway",
+	"gateway timeout",
+	"too many requests",
+	"rate limit",
+	"dns",
+	"no such host",
+}
+
+// hasTransientErrorPattern reports whether a lowercase error message matches a transient error
+func hasTransientErrorPattern(errStr string) bool {
+	for _, pattern := range transientErrorPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRemoteUnavailableError reports whether err means the remote could not be reached.
+// Unlike isRetryableError, unknown errors are not assumed to be transient.
+func isRemoteUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if retryable, known := classifyRetryableError(err); known {
+		return retryable
+	}
+
+	return hasTransientErrorPattern(strings.ToLower(errorChainText(err)))
+}
+
 // calculateRetryDelay calculates the delay for a retry attempt using exponential backoff
 func (g *GitProvider) calculateRetryDelay(attempt int) time.Duration {
 	// Calculate exponential backoff: baseDelay * (backoffFactor ^ attempt)
@@ -1521,6 +4297,10 @@ func (m *gitProviderMetrics) incrementCacheMisses() {
 	atomic.AddInt64(&m.cacheMisses, 1)
 }
 
+func (m *gitProviderMetrics) incrementBlobCacheHits() {
+	atomic.AddInt64(&m.blobCacheHits, 1)
+}
+
 func (m *gitProviderMetrics) incrementRetryAttempts() {
 	atomic.AddInt64(&m.retryAttempts, 1)
 }
@@ -1533,6 +4313,14 @@ func (m *gitProviderMetrics) addLoadTime(duration time.Duration) {
 	atomic.AddInt64(&m.totalLoadTime, int64(duration))
 }
 
+func (m *gitProviderMetrics) addCloneTime(duration time.Duration) {
+	atomic.AddInt64(&m.totalCloneTime, int64(duration))
+}
+
+func (m *gitProviderMetrics) addParseTime(duration time.Duration) {
+	atomic.AddInt64(&m.totalParseTime, int64(duration))
+}
+
 func (m *gitProviderMetrics) incrementTempDirsCreated() {
 	atomic.AddInt64(&m.tempDirsCreated, 1)
 }
@@ -1557,53 +4345,283 @@ func (m *gitProviderMetrics) incrementGitErrors() {
 	atomic.AddInt64(&m.gitErrors, 1)
 }
 
-// GetMetrics returns current metrics as a map for monitoring systems
-func (g *GitProvider) GetMetrics() map[string]interface{} {
-	m := g.metrics
+func (m *gitProviderMetrics) incrementRemoteRefLookups() {
+	atomic.AddInt64(&m.remoteRefLookups, 1)
+}
 
-	// Calculate derived metrics
-	totalRequests := atomic.LoadInt64(&m.loadRequests) + atomic.LoadInt64(&m.watchRequests)
-	cacheHitRate := float64(0)
-	if totalCacheAttempts := atomic.LoadInt64(&m.cacheHits) + atomic.LoadInt64(&m.cacheMisses); totalCacheAttempts > 0 {
-		cacheHitRate = float64(atomic.LoadInt64(&m.cacheHits)) / float64(totalCacheAttempts) * 100
+func (m *gitProviderMetrics) incrementRetryBudgetStops() {
+	atomic.AddInt64(&m.retryBudgetStops, 1)
+}
+
+func (m *gitProviderMetrics) incrementRetryAttemptStops() {
+	atomic.AddInt64(&m.retryAttemptStops, 1)
+}
+
+func (m *gitProviderMetrics) incrementRetryExhausted() {
+	atomic.AddInt64(&m.retryExhausted, 1)
+}
+
+func (m *gitProviderMetrics) incrementResourceLimitHits() {
+	atomic.AddInt64(&m.resourceLimitHits, 1)
+}
+
+func (m *gitProviderMetrics) incrementProviderClosedRejections() {
+	atomic.AddInt64(&m.providerClosedRejections, 1)
+}
+
+func (m *gitProviderMetrics) incrementStaleServes() {
+	atomic.AddInt64(&m.staleServes, 1)
+}
+
+func (m *gitProviderMetrics) incrementSkippedFiles() {
+	atomic.AddInt64(&m.skippedFiles, 1)
+}
+
+func (m *gitProviderMetrics) incrementInvalidWatchLoads() {
+	atomic.AddInt64(&m.invalidWatchLoads, 1)
+}
+
+func (m *gitProviderMetrics) recordMirrorServe(mirror string) {
+	m.mirrorMutex.Lock()
+	defer m.mirrorMutex.Unlock()
+
+	if m.mirrorServes == nil {
+		m.mirrorServes = make(map[string]int64)
 	}
+	m.mirrorServes[mirror]++
+}
 
-	avgLoadTime := time.Duration(0)
-	if loadRequests := atomic.LoadInt64(&m.loadRequests); loadRequests > 0 {
-		avgLoadTime = time.Duration(atomic.LoadInt64(&m.totalLoadTime) / loadRequests)
+// mirrorServesSnapshot returns a copy of the per-mirror counters
+func (m *gitProviderMetrics) mirrorServesSnapshot() map[string]int64 {
+	m.mirrorMutex.Lock()
+	defer m.mirrorMutex.Unlock()
+
+	snapshot := make(map[string]int64, len(m.mirrorServes))
+	for mirror, count := range m.mirrorServes {
+		snapshot[mirror] = count
 	}
+	return snapshot
+}
+
+// GetMetrics returns current metrics as a map for monitoring systems.
+// It is kept for compatibility; Metrics returns the same values as a typed struct.
+func (g *GitProvider) GetMetrics() map[string]interface{} {
+	m := g.Metrics()
 
 	return map[string]interface{}{
 		// Request metrics
-		"load_requests":  atomic.LoadInt64(&m.loadRequests),
-		"watch_requests": atomic.LoadInt64(&m.watchRequests),
-		"total_requests": totalRequests,
+		"load_requests":  m.LoadRequests,
+		"watch_requests": m.WatchRequests,
+		"total_requests": m.TotalRequests,
 
 		// Cache metrics
-		"cache_hits":     atomic.LoadInt64(&m.cacheHits),
-		"cache_misses":   atomic.LoadInt64(&m.cacheMisses),
-		"cache_hit_rate": cacheHitRate,
-		"configs_cached": atomic.LoadInt64(&m.configsCached),
+		"cache_hits":      m.CacheHits,
+		"cache_misses":    m.CacheMisses,
+		"cache_hit_rate":  m.CacheHitRate,
+		"blob_cache_hits": m.BlobCacheHits,
+		"configs_cached":  m.ConfigsCached,
 
 		// Performance metrics
-		"retry_attempts":      atomic.LoadInt64(&m.retryAttempts),
-		"failed_operations":   atomic.LoadInt64(&m.failedOperations),
-		"avg_load_time_ms":    float64(avgLoadTime.Nanoseconds()) / 1000000,
-		"total_clone_time_ms": float64(atomic.LoadInt64(&m.totalCloneTime)) / 1000000,
-		"total_parse_time_ms": float64(atomic.LoadInt64(&m.totalParseTime)) / 1000000,
+		"retry_attempts":      m.RetryAttempts,
+		"retry_budget_stops":  m.RetryBudgetStops,
+		"retry_attempt_stops": m.RetryAttemptStops,
+		"failed_operations":   m.FailedOperations,
+		"avg_load_time_ms":    float64(m.AvgLoadTime.Nanoseconds()) / 1000000,
+		"total_clone_time_ms": float64(m.TotalCloneTime.Nanoseconds()) / 1000000,
+		"total_parse_time_ms": float64(m.TotalParseTime.Nanoseconds()) / 1000000,
 
 		// Resource metrics
-		"temp_dirs_created": atomic.LoadInt64(&m.tempDirsCreated),
+		"temp_dirs_created": m.TempDirsCreated,
 
 		// Error metrics
-		"network_errors": atomic.LoadInt64(&m.networkErrors),
-		"auth_errors":    atomic.LoadInt64(&m.authErrors),
-		"parse_errors":   atomic.LoadInt64(&m.parseErrors),
-		"git_errors":     atomic.LoadInt64(&m.gitErrors),
+		"network_errors": m.NetworkErrors,
+		"auth_errors":    m.AuthErrors,
+		"parse_errors":   m.ParseErrors,
+		"git_errors":     m.GitErrors,
+
+		// Failure mode metrics
+		"retry_exhausted":            m.RetryExhausted,
+		"resource_limit_hits":        m.ResourceLimitHits,
+		"provider_closed_rejections": m.ProviderClosedRejections,
+		"stale_serves":               m.StaleServes,
+		"skipped_files":              m.SkippedFiles,
+		"invalid_watch_loads":        m.InvalidWatchLoads,
+		"mirror_serves":              m.MirrorServes,
+
+		// Remote traffic metrics
+		"remote_ref_lookups": m.RemoteRefLookups,
 
 		// Configuration cache metrics
-		"config_cache": g.configCache.stats(),
+		"config_cache": map[string]interface{}{
+			"entries":      m.ConfigCache.Entries,
+			"max_size":     m.ConfigCache.MaxSize,
+			"total_access": m.ConfigCache.TotalAccess,
+			"oldest_entry": m.ConfigCache.OldestEntry,
+			"newest_entry": m.ConfigCache.NewestEntry,
+			"ttl_seconds":  m.ConfigCache.TTL.Seconds(),
+		},
+
+		// Internal cache metrics
+		"repo_cache": map[string]interface{}{
+			"entries": m.RepoCacheEntries,
+		},
+		"auth_cache": map[string]interface{}{
+			"entries": m.AuthCacheEntries,
+		},
+	}
+}
+
+// Metrics is a point-in-time snapshot of the provider metrics.
+// Counters are cumulative since the provider was created or last reset.
+type Metrics struct {
+	// Request counters
+	LoadRequests  int64 // Total Load() calls
+	WatchRequests int64 // Total Watch() calls
+	TotalRequests int64 // Load() and Watch() calls combined
+
+	// Cache counters
+	CacheHits     int64   // Cache hits
+	CacheMisses   int64   // Cache misses
+	CacheHitRate  float64 // Cache hits as a percentage of cache lookups
+	BlobCacheHits int64   // Cache misses whose file blob was already parsed at another commit
+	ConfigsCached int64   // Total configurations cached
+
+	// Operation counters
+	RetryAttempts     int64 // Total retry attempts
+	RetryBudgetStops  int64 // Retries stopped by the retry budget or context deadline
+	RetryAttemptStops int64 // Retries stopped by the maximum attempt count
+	FailedOperations  int64 // Failed operations
+	TempDirsCreated   int64 // Total temporary directories created
+	RemoteRefLookups  int64 // Remote reference listings (git ls-remote)
+
+	// Timing totals
+	TotalLoadTime  time.Duration // Total time spent in Load operations
+	TotalCloneTime time.Duration // Total time spent cloning
+	TotalParseTime time.Duration // Total time spent parsing configs
+	AvgLoadTime    time.Duration // Average time per Load operation
+
+	// Error counters by type
+	NetworkErrors int64 // Network-related errors
+	AuthErrors    int64 // Authentication errors
+	ParseErrors   int64 // Configuration parsing errors
+	GitErrors     int64 // Git operation errors
+
+	// Failure mode counters
+	RetryExhausted           int64 // Operations that failed after exhausting their retries
+	ResourceLimitHits        int64 // Requests rejected by a resource limit
+	ProviderClosedRejections int64 // Requests rejected because the provider is closed
+	StaleServes              int64 // Stale configurations served because the remote was unreachable
+	SkippedFiles             int64 // Files left out of tolerant multi-file loads because they failed (see WithMergeTolerant)
+	InvalidWatchLoads        int64 // Watch loads of configurations that failed to parse or validate (see WithWatchErrorPolicy)
+
+	// MirrorServes counts the Git operations served by each mirror repository URL
+	// because the primary repository was unreachable
+	MirrorServes map[string]int64
+
+	// Configuration cache state
+	ConfigCache ConfigCacheStats
+
+	// Internal cache sizes; neither cache expires entries, so both grow with the
+	// number of distinct repositories and credentials used
+	RepoCacheEntries int // Repositories and release assets tracked for change detection
+	AuthCacheEntries int // Cached authentication objects
+}
+
+// ConfigCacheStats describes the current contents of the configuration cache
+type ConfigCacheStats struct {
+	Entries     int           // Number of cached configurations
+	MaxSize     int           // Maximum number of cache entries
+	TotalAccess int64         // Accesses across all cached entries
+	OldestEntry time.Time     // When the oldest entry was cached
+	NewestEntry time.Time     // When the newest entry was cached
+	TTL         time.Duration // Cache time-to-live
+}
+
+// Metrics returns the current metrics as a typed struct.
+// Each counter is read atomically, but the snapshot as a whole is not taken under a lock.
+//
+// Example:
+//
+//	m := provider.Metrics()
+//	fmt.Printf("cache hit rate: %.1f%%, retries exhausted: %d\n", m.CacheHitRate, m.RetryExhausted)
+func (g *GitProvider) Metrics() Metrics {
+	m := g.metrics
+
+	snapshot := Metrics{
+		LoadRequests:             atomic.LoadInt64(&m.loadRequests),
+		WatchRequests:            atomic.LoadInt64(&m.watchRequests),
+		CacheHits:                atomic.LoadInt64(&m.cacheHits),
+		CacheMisses:              atomic.LoadInt64(&m.cacheMisses),
+		BlobCacheHits:            atomic.LoadInt64(&m.blobCacheHits),
+		ConfigsCached:            atomic.LoadInt64(&m.configsCached),
+		RetryAttempts:            atomic.LoadInt64(&m.retryAttempts),
+		RetryBudgetStops:         atomic.LoadInt64(&m.retryBudgetStops),
+		RetryAttemptStops:        atomic.LoadInt64(&m.retryAttemptStops),
+		FailedOperations:         atomic.LoadInt64(&m.failedOperations),
+		TempDirsCreated:          atomic.LoadInt64(&m.tempDirsCreated),
+		RemoteRefLookups:         atomic.LoadInt64(&m.remoteRefLookups),
+		TotalLoadTime:            time.Duration(atomic.LoadInt64(&m.totalLoadTime)),
+		TotalCloneTime:           time.Duration(atomic.LoadInt64(&m.totalCloneTime)),
+		TotalParseTime:           time.Duration(atomic.LoadInt64(&m.totalParseTime)),
+		NetworkErrors:            atomic.LoadInt64(&m.networkErrors),
+		AuthErrors:               atomic.LoadInt64(&m.authErrors),
+		ParseErrors:              atomic.LoadInt64(&m.parseErrors),
+		GitErrors:                atomic.LoadInt64(&m.gitErrors),
+		RetryExhausted:           atomic.LoadInt64(&m.retryExhausted),
+		ResourceLimitHits:        atomic.LoadInt64(&m.resourceLimitHits),
+		ProviderClosedRejections: atomic.LoadInt64(&m.providerClosedRejections),
+		StaleServes:              atomic.LoadInt64(&m.staleServes),
+		SkippedFiles:             atomic.LoadInt64(&m.skippedFiles),
+		InvalidWatchLoads:        atomic.LoadInt64(&m.invalidWatchLoads),
+		MirrorServes:             m.mirrorServesSnapshot(),
+		ConfigCache:              g.configCache.stats(),
+	}
+
+	g.repoCacheMutex.RLock()
+	snapshot.RepoCacheEntries = len(g.repoCache)
+	g.repoCacheMutex.RUnlock()
+	g.authCacheMutex.RLock()
+	snapshot.AuthCacheEntries = len(g.authCache)
+	g.authCacheMutex.RUnlock()
+
+	// Calculate derived metrics
+	snapshot.TotalRequests = snapshot.LoadRequests + snapshot.WatchRequests
+	if totalCacheAttempts := snapshot.CacheHits + snapshot.CacheMisses; totalCacheAttempts > 0 {
+		snapshot.CacheHitRate = float64(snapshot.CacheHits) / float64(totalCacheAttempts) * 100
+	}
+	if snapshot.LoadRequests > 0 {
+		snapshot.AvgLoadTime = snapshot.TotalLoadTime / time.Duration(snapshot.LoadRequests)
+	}
+
+	return snapshot
+}
+
+// SnapshotMetrics returns the current metrics as a typed struct; it is equivalent to Metrics
+func (g *GitProvider) SnapshotMetrics() Metrics {
+	return g.Metrics()
+}
+
+// ResetMetrics zeroes all counters, e.g. to report rates over a fixed interval.
+// Each counter is reset atomically; operations running concurrently may be counted
+// in either interval. Cached configurations are not affected.
+func (g *GitProvider) ResetMetrics() {
+	m := g.metrics
+
+	for _, counter := range []*int64{
+		&m.loadRequests, &m.watchRequests, &m.cacheHits, &m.cacheMisses, &m.blobCacheHits, &m.configsCached,
+		&m.retryAttempts, &m.retryBudgetStops, &m.retryAttemptStops, &m.failedOperations,
+		&m.tempDirsCreated, &m.remoteRefLookups,
+		&m.totalLoadTime, &m.totalCloneTime, &m.totalParseTime,
+		&m.networkErrors, &m.authErrors, &m.parseErrors, &m.gitErrors,
+		&m.retryExhausted, &m.resourceLimitHits, &m.providerClosedRejections, &m.staleServes,
+		&m.skippedFiles, &m.invalidWatchLoads,
+	} {
+		atomic.StoreInt64(counter, 0)
 	}
+
+	m.mirrorMutex.Lock()
+	m.mirrorServes = nil
+	m.mirrorMutex.Unlock()
 }
 
 // classifyAndRecordError classifies an error and records the appropriate metric
@@ -1612,6 +4630,16 @@ func (g *GitProvider) classifyAndRecordError(err error) {
 		return
 	}
 
+	// Rejections are not operation failures, so they are counted apart from the error buckets
+	switch {
+	case errors.HasCode(err, "ARGUS_PROVIDER_CLOSED"):
+		g.metrics.incrementProviderClosedRejections()
+		return
+	case errors.HasCode(err, "ARGUS_RESOURCE_LIMIT"):
+		g.metrics.incrementResourceLimitHits()
+		return
+	}
+
 	errStr := strings.ToLower(err.Error())
 
 	// Classify error types and record metrics
@@ -1634,22 +4662,31 @@ func (g *GitProvider) classifyAndRecordError(err error) {
 func (g *GitProvider) checkRepositoryHealth(ctx context.Context, gitURL *GitURL) error {
 	// Create a memory-based clone for health check (no disk I/O)
 	cloneOptions := &git.CloneOptions{
-		URL:      gitURL.RepoURL,
+		URL:      transportURL(gitURL.RepoURL),
 		Progress: nil,
 		Depth:    1,
 	}
 
 	// Set authentication if provided
-	if auth, err := g.getAuthentication(gitURL); err == nil && auth != nil {
+	auth, err := g.getAuthentication(gitURL)
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_HEALTH_CHECK_FAILED", "repository credentials not usable")
+	}
+	if auth != nil {
 		cloneOptions.Auth = auth
 	}
+	cloneOptions.CABundle, cloneOptions.InsecureSkipTLS, cloneOptions.ProxyOptions = g.transportOptions(gitURL.RepoURL)
 
-	// Add timeout to context
-	healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	// The timeout bounds the whole check: transient failures are retried like those
+	// of Load, but no attempt starts past the deadline
+	healthCtx, cancel := context.WithTimeout(ctx, g.options.effectiveHealthCheckTimeout())
 	defer cancel()
 
 	// Try to clone into memory
-	_, err := git.CloneContext(healthCtx, memory.NewStorage(), nil, cloneOptions)
+	err = g.retryOperation(healthCtx, func() error {
+		_, err := git.CloneContext(healthCtx, memory.NewStorage(), nil, cloneOptions)
+		return err
+	}, "git health check")
 	if err != nil {
 		return errors.Wrap(err, "ARGUS_HEALTH_CHECK_FAILED", "repository not accessible")
 	}
@@ -1657,9 +4694,9 @@ func (g *GitProvider) checkRepositoryHealth(ctx context.Context, gitURL *GitURL)
 	return nil
 }
 
-// createTempDirectory creates a temporary directory for Git operations
+// createTempDirectory creates a temporary directory for Git operations under the configured temp base
 func (g *GitProvider) createTempDirectory() (string, error) {
-	tempDir, err := os.MkdirTemp("", "argus-git-*")
+	tempDir, err := os.MkdirTemp(g.options.tempDir, "argus-git-*")
 	if err != nil {
 		return "", err
 	}
@@ -1675,7 +4712,7 @@ func (g *GitProvider) createTempDirectory() (string, error) {
 
 // removeTempDirectory removes a specific temporary directory
 func (g *GitProvider) removeTempDirectory(tempDir string) {
-	_ = os.RemoveAll(tempDir) // Intentionally ignore error as cleanup is best-effort
+	g.removeTempTree(tempDir)
 
 	g.tempDirMutex.Lock()
 	for i, dir := range g.tempDirs {
@@ -1693,17 +4730,102 @@ func (g *GitProvider) cleanupTempDirectories() {
 	defer g.tempDirMutex.Unlock()
 
 	for _, dir := range g.tempDirs {
-		_ = os.RemoveAll(dir) // Intentionally ignore errors during cleanup
+		g.removeTempTree(dir)
 	}
 	g.tempDirs = nil
 }
 
+// removeTempTree removes a clone directory. Cleanup is best-effort: on Windows, where
+// files in use cannot be deleted, removal is retried with backoff a bounded number of
+// times, and a final failure is only logged; the directory is left to the stale sweep.
+func (g *GitProvider) removeTempTree(dir string) {
+	attempts := 1
+	if runtime.GOOS == "windows" {
+		attempts = tempDirRemoveAttempts
+	}
+
+	err := os.RemoveAll(dir)
+	for attempt := 1; err != nil && attempt < attempts; attempt++ {
+		time.Sleep(tempDirRemoveBaseDelay << (attempt - 1))
+		err = os.RemoveAll(dir)
+	}
+	if err != nil {
+		g.logEvent(context.Background(), slog.LevelDebug, "temporary directory cleanup failed",
+			slog.String("dir", dir), slog.Int("attempts", attempts), logErrorAttr(err))
+	}
+}
+
+// closeRepository releases the file handles a cloned repository's storage holds open,
+// so its directory can be removed; Windows cannot delete files that are still open
+func closeRepository(repo *git.Repository) {
+	if closer, ok := repo.Storer.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// sweepStaleTempDirectories removes argus-git-* directories in the temp base that were
+// not modified within maxAge, returning how many were removed. A clone is bounded by
+// the retry and Git timeouts, so older directories belong to no live operation.
+func (g *GitProvider) sweepStaleTempDirectories(maxAge time.Duration) int {
+	base := g.options.tempDir
+	if base == "" {
+		base = os.TempDir()
+	}
+
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		// SECURITY: Only remove real directories named like ours, never symlink targets
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "argus-git-") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+
+		if os.RemoveAll(filepath.Join(base, entry.Name())) == nil {
+			removed++
+		}
+	}
+
+	return removed
+}
+
 // GetProvider returns a new instance of the Git provider
 //
 // This function is called by Argus during the provider registration process.
 // It returns a fresh instance of the provider that Argus will register
 // and use for handling git:// URLs.
 func GetProvider() RemoteConfigProvider {
+	return newGitProvider()
+}
+
+// Fetch loads the configuration at configURL once, for scripts and init containers
+// that need a single configuration without managing a provider. It creates a
+// provider with opts, loads the URL and closes the provider before returning, so
+// its temporary directories are removed whether or not the load succeeded.
+//
+// Example:
+//
+//	config, err := git.Fetch(ctx, "https://github.com/org/configs.git#app.yaml?ref=production")
+func Fetch(ctx context.Context, configURL string, opts ...Option) (map[string]interface{}, error) {
+	provider, err := NewProvider(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = provider.Close() }()
+
+	return provider.Load(ctx, configURL)
+}
+
+// newGitProvider creates a provider instance with default settings
+func newGitProvider() *GitProvider {
 	return &GitProvider{
 		authCache:   make(map[string]transport.AuthMethod),
 		repoCache:   make(map[string]*repoMetadata),
@@ -1711,5 +4833,7 @@ func GetProvider() RemoteConfigProvider {
 		configCache: newConfigCache(100, 10*time.Minute), // Cache up to 100 configs for 10 minutes
 		retryConfig: defaultRetryConfig(),
 		metrics:     newGitProviderMetrics(),
+
+		releaseClient: newReleaseClient(),
 	}
 }
@@ -0,0 +1,1048 @@
+// options.go: Functional options for the Git provider
+//
+// Options configure provider-wide behavior that cannot (or should not) be
+// expressed in individual configuration URLs, such as secrets that must not
+// appear in URLs or logs.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	gohttp "net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/agilira/go-errors"
+)
+
+// Option configures optional behavior of a GitProvider created with NewProvider.
+type Option func(*GitProvider) error
+
+// providerOptions holds the optional behavior configured through Option values.
+// The zero value selects the default behavior for every field.
+type providerOptions struct {
+	httpHeaders      map[string]string // Extra headers sent on every HTTP(S) Git request
+	disableGitSuffix bool              // Use repository URLs verbatim without appending ".git"
+	strictDecoding   bool              // Reject duplicate keys in JSON objects
+	jsonUseNumber    bool              // Decode JSON numbers as json.Number instead of float64
+	rootKey          string            // Key that wraps non-object configuration roots; empty rejects them
+	yamlDocumentsKey string            // Key collecting the documents of multi-document YAML files; empty rejects them
+	allowLocalRepos  bool              // Accept file:// URLs and absolute paths as repository URLs
+	staleFallback    time.Duration     // Maximum age of a last-known-good config served on outages; zero disables
+	diskCacheDir     string            // Directory the configuration cache is persisted to; empty disables
+	cloneDepth       int               // History depth of clones; zero selects depth 1, negative the full history
+	cloneTimeout     time.Duration     // Timeout of each clone and fetch attempt; zero selects 60s
+	lsRemoteTimeout  time.Duration     // Timeout of each remote reference listing; zero selects 15s
+	healthTimeout    time.Duration     // Timeout of health check clones; zero selects 10s
+	sparseCheckout   bool              // Check out only the configuration file's directory on loads
+	allBranches      bool              // Clone every branch rather than only the reference's
+	rawFastPath      bool              // Try raw file downloads of public GitHub and GitLab files before cloning
+	skipTags         bool              // Fetch no tags on clones beyond the cloned reference
+	tempDir          string            // Base directory of temporary clones; empty selects the system temp dir
+	tempSweepOnStart bool              // Remove stale clone directories left by earlier processes on creation
+	mergeTolerant    bool              // Skip files that fail to parse in multi-file loads
+	strictRefs       bool              // Reject bare references naming both a branch and a tag
+	strictPoll       bool              // Reject poll intervals out of range instead of clamping them
+	pinResolvedRef   bool              // Load references at the commit their first load resolved to
+	includes         bool              // Resolve include directives against files of the same commit
+	maxIncludeDepth  int               // Maximum nesting of included files; zero selects the default of 10
+	mergeMissing     bool              // Also skip missing files in multi-file loads (with mergeTolerant)
+	gitEnvironment   bool              // Read transport settings from git CLI environment variables
+	sshUser          string            // SSH username of repository URLs without one; empty selects "git"
+	anonFallback     bool              // Repeat HTTP(S) operations rejected for their credentials anonymously
+
+	sshPassphrase func(keyPath string) (string, error) // Source of SSH key passphrases absent from the URL
+
+	caBundle        []byte // PEM certificates trusted for HTTPS in addition to the system pool
+	proxyURL        string // Proxy of HTTP(S) Git and releases API requests; empty uses the environment
+	insecureSkipTLS bool   // Skip HTTPS certificate verification (GIT_SSL_NO_VERIFY only)
+
+	httpClient *gohttp.Client                                                    // Client of HTTP(S) Git and releases API requests; nil uses the default
+	dialer     func(ctx context.Context, network, addr string) (net.Conn, error) // Dialer of HTTP(S) connections; nil dials directly
+
+	format            Format   // Format of every configuration file regardless of its extension; empty detects it
+	allowedExtensions []string // Replaces the default allowed config file extensions when non-nil
+	extraExtensions   []string // Extensions allowed in addition to the defaults
+	sensitivePaths    []string // Replaces the default sensitive path segment patterns when non-nil
+	requiredKeys      []string // Dotted key paths every loaded configuration must contain
+
+	progressReporter func(ProgressEvent) // Receives parsed clone progress; nil disables progress
+	logger           *slog.Logger        // Receives diagnostic events; nil disables logging
+	auditSink        func(AuditRecord)   // Receives one record per configuration load; nil disables auditing
+	changeHook       func(ChangeEvent)   // Receives configuration changes delivered by watches; nil disables it
+	watchErrorPolicy string              // Handling of invalid watched configurations; empty keeps the last good one
+}
+
+// NewProvider creates a Git provider with the given options applied.
+//
+// Unlike GetProvider, NewProvider returns the concrete *GitProvider type and
+// reports invalid options as errors instead of silently ignoring them.
+func NewProvider(opts ...Option) (*GitProvider, error) {
+	g := newGitProvider()
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the environment after all options are applied, so explicit options take precedence
+	if g.options.gitEnvironment {
+		if err := g.applyGitEnvironment(os.LookupEnv); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.configureGitTransport(); err != nil {
+		return nil, err
+	}
+	if err := g.configureReleaseTransport(); err != nil {
+		return nil, err
+	}
+
+	// Sweep after all options are applied, so the final temp base is swept
+	if g.options.tempSweepOnStart {
+		g.sweepStaleTempDirectories(staleTempDirAge)
+	}
+
+	// Hydrate after all options are applied, so entries decode with the final decoding options
+	if g.options.diskCacheDir != "" {
+		if err := g.hydrateDiskCache(); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// WithHTTPHeader adds a header that is sent on every HTTP(S) request to Git servers.
+//
+// This supports gateways that authenticate with arbitrary headers instead of HTTP
+// Basic auth, e.g. WithHTTPHeader("Authorization", "Bearer <jwt>"). The header is
+// combined with any authentication from the URL; header values are redacted from
+// the authentication method's string form and never reported in metrics.
+// The option has no effect on SSH repositories.
+func WithHTTPHeader(name, value string) Option {
+	return func(g *GitProvider) error {
+		if err := validateHTTPHeader(name, value); err != nil {
+			return err
+		}
+
+		if g.options.httpHeaders == nil {
+			g.options.httpHeaders = make(map[string]string)
+		}
+		g.options.httpHeaders[name] = value
+		return nil
+	}
+}
+
+// WithAutoGitSuffix controls whether ".git" is appended to repository URLs that lack it.
+//
+// Suffixing is enabled by default, except for URL conventions known to reject it
+// (Azure DevOps "/_git/" and AWS CodeCommit "/v1/repos/" paths). Disable it for
+// self-hosted servers that only serve repositories without the suffix.
+func WithAutoGitSuffix(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.disableGitSuffix = !enabled
+		return nil
+	}
+}
+
+// WithStrictDecoding rejects JSON objects that repeat a key, which encoding/json
+// otherwise accepts by keeping the last value, with ARGUS_PARSE_ERROR.
+//
+// It only affects duplicate JSON keys: JSON trailing data, duplicate keys in YAML
+// and TOML, and YAML files with several documents are rejected in every mode.
+// Decoders installed with RegisterFormat are not affected.
+func WithStrictDecoding(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.strictDecoding = enabled
+		return nil
+	}
+}
+
+// WithStrictYAML is the former name of WithStrictDecoding.
+//
+// Deprecated: Use WithStrictDecoding, which behaves identically; despite its name,
+// this option only ever affected duplicate JSON keys.
+func WithStrictYAML(enabled bool) Option {
+	return WithStrictDecoding(enabled)
+}
+
+// WithJSONUseNumber decodes JSON numbers as json.Number instead of float64.
+//
+// By default JSON numbers become float64, which cannot represent integers beyond
+// 2^53 exactly (e.g. 64-bit snowflake IDs). With this option every JSON number is
+// a json.Number holding the original literal; use its Int64, Float64 or String
+// methods to convert it. Code that type-asserts numbers to float64 must be
+// updated when enabling the option. YAML and TOML are unaffected: they already
+// decode integers as int and int64 respectively.
+func WithJSONUseNumber(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.jsonUseNumber = enabled
+		return nil
+	}
+}
+
+// WithRootKey wraps configuration files whose root is not an object under key.
+//
+// Configurations are maps, so by default a JSON or YAML file whose root is an
+// array or scalar (e.g. a list of feature flags) fails with ARGUS_PARSE_ERROR.
+// With WithRootKey("items"), the file ["a", "b"] loads as {"items": ["a", "b"]}.
+// Object roots are never wrapped. TOML roots are always tables.
+func WithRootKey(key string) Option {
+	return func(g *GitProvider) error {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "root key cannot be empty")
+		}
+		if strings.ContainsAny(key, "\x00\r\n") {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid root key: %q", key))
+		}
+
+		g.options.rootKey = key
+		return nil
+	}
+}
+
+// WithYAMLDocumentsKey collects the documents of multi-document YAML files into a list under key.
+//
+// YAML files may hold several "---"-separated documents (e.g. a list of resources).
+// By default such files fail with ARGUS_PARSE_ERROR instead of silently loading only
+// the first document. With WithYAMLDocumentsKey("documents"), the file
+// "name: a\n---\nname: b\n" loads as {"documents": [{"name": "a"}, {"name": "b"}]}.
+// Files with a single document are decoded as usual, and empty documents are ignored.
+func WithYAMLDocumentsKey(key string) Option {
+	return func(g *GitProvider) error {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "YAML documents key cannot be empty")
+		}
+		if strings.ContainsAny(key, "\x00\r\n") {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid YAML documents key: %q", key))
+		}
+
+		g.options.yamlDocumentsKey = key
+		return nil
+	}
+}
+
+// WithAllowedExtensions restricts configuration files to the given extensions,
+// replacing the default list (every registered format plus .hcl, .ini and
+// .properties). For example, WithAllowedExtensions(".json") rejects YAML and TOML
+// URLs with ARGUS_INVALID_CONFIG. Extensions may be given with or without the
+// leading dot and are matched case-insensitively.
+func WithAllowedExtensions(exts ...string) Option {
+	return func(g *GitProvider) error {
+		if len(exts) == 0 {
+			return errors.New("ARGUS_INVALID_CONFIG", "allowed extensions cannot be empty")
+		}
+
+		normalized, err := normalizeExtensions(exts)
+		if err != nil {
+			return err
+		}
+
+		g.options.allowedExtensions = normalized
+		return nil
+	}
+}
+
+// WithAdditionalExtensions allows configuration files with the given extensions in
+// addition to the default list. Loading such a file still requires a decoder
+// registered with RegisterFormat.
+func WithAdditionalExtensions(exts ...string) Option {
+	return func(g *GitProvider) error {
+		normalized, err := normalizeExtensions(exts)
+		if err != nil {
+			return err
+		}
+
+		g.options.extraExtensions = append(g.options.extraExtensions, normalized...)
+		return nil
+	}
+}
+
+// WithFormat parses every configuration file as format ("json", "yaml", "toml" or a
+// format registered with RegisterFormat) regardless of its extension, for files whose
+// extension does not match their content. Any extension is then accepted, while path
+// traversal and sensitive file checks still apply. The format URL parameter, e.g.
+// "#settings.txt?format=json", does the same for a single URL and takes precedence.
+func WithFormat(format string) Option {
+	return func(g *GitProvider) error {
+		parsed, err := parseFormatName(format)
+		if err != nil {
+			return err
+		}
+
+		g.options.format = parsed
+		return nil
+	}
+}
+
+// WithSensitivePaths replaces the path segment patterns rejected in configuration file
+// paths with ARGUS_SECURITY_ERROR. Patterns use path.Match syntax and are matched
+// case-insensitively against each directory and file name of the path, so ".ssh"
+// blocks "deploy/.ssh/app.json" but not ".sshconfig/app.json". To extend the defaults
+// rather than replace them, pass append(git.DefaultSensitivePaths(), patterns...).
+// ".git" segments are always rejected.
+//
+// Example:
+//
+//	// Also keep Kubernetes credentials out of reach
+//	git.WithSensitivePaths(append(git.DefaultSensitivePaths(), ".kube", "kubeconfig*")...)
+func WithSensitivePaths(patterns ...string) Option {
+	return func(g *GitProvider) error {
+		if len(patterns) == 0 {
+			return errors.New("ARGUS_INVALID_CONFIG", "sensitive paths cannot be empty")
+		}
+
+		normalized := make([]string, 0, len(patterns))
+		for _, pattern := range patterns {
+			if pattern == "" || strings.Contains(pattern, "/") {
+				return errors.New("ARGUS_INVALID_CONFIG",
+					fmt.Sprintf("invalid sensitive path pattern %q (expected a single path segment)", pattern))
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errors.Wrap(err, "ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid sensitive path pattern %q", pattern))
+			}
+			normalized = append(normalized, strings.ToLower(pattern))
+		}
+
+		g.options.sensitivePaths = normalized
+		return nil
+	}
+}
+
+// DefaultSensitivePaths returns the path segment patterns rejected in configuration
+// file paths by default, for extending with WithSensitivePaths
+func DefaultSensitivePaths() []string {
+	return slices.Clone(defaultSensitivePaths)
+}
+
+// sensitivePaths returns the path segment patterns rejected by this provider
+func (g *GitProvider) sensitivePaths() []string {
+	if g.options.sensitivePaths != nil {
+		return g.options.sensitivePaths
+	}
+	return defaultSensitivePaths
+}
+
+// normalizeExtensions normalizes a list of file extensions for path validation
+func normalizeExtensions(exts []string) ([]string, error) {
+	normalized := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		n, err := normalizeFormatExtension(ext)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// allowedExtensions returns the configuration file extensions accepted by this provider
+func (g *GitProvider) allowedExtensions() []string {
+	if g.options.allowedExtensions != nil {
+		return g.options.allowedExtensions
+	}
+	return append(defaultConfigExtensions(), g.options.extraExtensions...)
+}
+
+// extensionsFor returns the extensions accepted for files parsed as format:
+// the allowed extensions, or nil (any extension) when the format is explicit
+func (g *GitProvider) extensionsFor(format Format) []string {
+	if format != "" {
+		return nil
+	}
+	return g.allowedExtensions()
+}
+
+// WithRetryBudget bounds the total time an operation may spend including retries.
+//
+// Retries stop once waiting for the next attempt would exceed the budget, even if
+// attempts remain, which gives a predictable worst-case latency. The budget limits
+// when attempts start; each attempt is still bounded by its operation timeout
+// (see WithCloneTimeout and WithLsRemoteTimeout).
+// Context deadlines are honored the same way. A zero budget (the default) leaves
+// retries bounded only by the attempt count and the context.
+func WithRetryBudget(budget time.Duration) Option {
+	return func(g *GitProvider) error {
+		if budget < 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("retry budget cannot be negative: %s", budget))
+		}
+
+		g.retryConfig.budget = budget
+		return nil
+	}
+}
+
+// WithCloneTimeout bounds each clone and fetch attempt, including the fetches that
+// deepen a shallow clone, to timeout (60s by default). Each retry gets its own
+// timeout; WithRetryBudget bounds them together. A context with an earlier deadline
+// still ends the operation at that deadline.
+func WithCloneTimeout(timeout time.Duration) Option {
+	return func(g *GitProvider) error {
+		if timeout <= 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("clone timeout must be positive: %s", timeout))
+		}
+
+		g.options.cloneTimeout = timeout
+		return nil
+	}
+}
+
+// WithLsRemoteTimeout bounds each listing of remote references (git ls-remote), which
+// loads use to look up the commit of a reference and watches use to detect changes,
+// to timeout (15s by default). A context with an earlier deadline still applies.
+func WithLsRemoteTimeout(timeout time.Duration) Option {
+	return func(g *GitProvider) error {
+		if timeout <= 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("ls-remote timeout must be positive: %s", timeout))
+		}
+
+		g.options.lsRemoteTimeout = timeout
+		return nil
+	}
+}
+
+// WithHealthCheckTimeout bounds the clone HealthCheck makes of a repository to
+// timeout (10s by default). A context with an earlier deadline still applies.
+func WithHealthCheckTimeout(timeout time.Duration) Option {
+	return func(g *GitProvider) error {
+		if timeout <= 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("health check timeout must be positive: %s", timeout))
+		}
+
+		g.options.healthTimeout = timeout
+		return nil
+	}
+}
+
+// effectiveCloneTimeout returns the configured clone timeout or the default
+func (o *providerOptions) effectiveCloneTimeout() time.Duration {
+	return cmp.Or(o.cloneTimeout, defaultGitTimeout)
+}
+
+// effectiveLsRemoteTimeout returns the configured ls-remote timeout or the default
+func (o *providerOptions) effectiveLsRemoteTimeout() time.Duration {
+	return cmp.Or(o.lsRemoteTimeout, defaultLsRemoteTimeout)
+}
+
+// effectiveHealthCheckTimeout returns the configured health check timeout or the default
+func (o *providerOptions) effectiveHealthCheckTimeout() time.Duration {
+	return cmp.Or(o.healthTimeout, defaultHealthCheckTimeout)
+}
+
+// WithAllowLocalRepos permits repositories on the local filesystem.
+//
+// SECURITY: This is disabled by default because it lets configuration URLs read
+// any Git repository the process can access. Only enable it when configuration
+// URLs are trusted, e.g. for air-gapped mirrors or tests. When enabled, file://
+// URLs and absolute paths are accepted as repository URLs, e.g.
+// "file:///srv/git/configs.git#app.json?ref=main" or "/srv/git/configs.git#app.json".
+// Bare and non-bare repositories are supported; authentication parameters are
+// rejected since local repositories are read directly.
+func WithAllowLocalRepos(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.allowLocalRepos = enabled
+		return nil
+	}
+}
+
+// WithSparseCheckout writes only the directory of the configuration file to disk.
+//
+// Loads read configuration files straight from the commit tree and only check out
+// a worktree when that is not possible, e.g. for symlinked files. Such a checkout
+// normally writes the whole tree of the reference, which dominates disk usage and
+// time for large monorepos. With sparse checkout enabled, it writes only the files
+// under the configuration file's directory (or only the file itself for files at
+// the repository root). If go-git cannot check the directory out sparsely, the
+// load falls back to a full checkout. Symlinked configuration files whose targets
+// lie outside the directory cannot be read with sparse checkout.
+func WithSparseCheckout(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.sparseCheckout = enabled
+		return nil
+	}
+}
+
+// WithRawFastPath makes loads of unauthenticated GitHub and GitLab URLs download the
+// file from the platform's raw file endpoint (raw.githubusercontent.com, or
+// /-/raw/ on GitLab) instead of cloning, falling back to the clone if the download
+// fails. Downloaded configurations have no commit hash and are not cached, and may
+// lag behind the reference by the platform's CDN cache time. With WithPinResolvedRef,
+// references are cloned until they are pinned, as downloads cannot resolve a commit.
+func WithRawFastPath(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.rawFastPath = enabled
+		return nil
+	}
+}
+
+// WithSingleBranch controls whether clones of a branch or tag reference fetch only
+// that reference (the default) or every branch of the repository. Cloning every
+// branch costs more transfer but leaves the other branches resolvable in the
+// clone, e.g. for callers inspecting several references of the same checkout.
+// Commit references always clone every branch.
+func WithSingleBranch(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.allBranches = !enabled
+		return nil
+	}
+}
+
+// WithFetchTags controls whether clones fetch every tag of the repository (the
+// default), so that tags resolve within a clone of any reference, or none beyond
+// the cloned reference, as with git clone --no-tags. Skipping tags saves transfer
+// on repositories with many tags on commits outside the cloned history.
+func WithFetchTags(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.skipTags = !enabled
+		return nil
+	}
+}
+
+// WithCloneDepth sets how many commits of history clones fetch; zero clones the full history.
+//
+// Clones are shallow with a depth of 1 by default, which is the fastest way to read
+// a branch or tag tip. When a full or abbreviated commit hash is requested that is
+// not within depth, the clone is deepened on demand (first to depth*8, and so on,
+// then to the full history), so a larger depth only saves round trips for
+// workloads that regularly load commits behind the tip.
+func WithCloneDepth(depth int) Option {
+	return func(g *GitProvider) error {
+		if depth < 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("clone depth must not be negative: %d", depth))
+		}
+
+		if depth == 0 {
+			depth = -1
+		}
+		g.options.cloneDepth = depth
+		return nil
+	}
+}
+
+// effectiveCloneDepth returns the configured clone depth in go-git terms, where zero is the full history
+func (o *providerOptions) effectiveCloneDepth() int {
+	switch {
+	case o.cloneDepth == 0:
+		return defaultCloneDepth
+	case o.cloneDepth < 0:
+		return 0
+	default:
+		return o.cloneDepth
+	}
+}
+
+// WithStaleFallback serves the last-known-good configuration when the remote is unreachable.
+//
+// When a load fails with a transient error (network failures, timeouts, 5xx and
+// 429 responses), the most recent successful load of the same repository, file
+// and reference is returned instead, provided it is no older than maxAge. The
+// result has LoadResult.Stale set, and the stale_serves metric is incremented.
+// Last-known-good entries are kept independently of the cache TTL. Other errors,
+// such as missing files, authentication or parse failures, are always returned.
+func WithStaleFallback(maxAge time.Duration) Option {
+	return func(g *GitProvider) error {
+		if maxAge <= 0 {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("stale fallback max age must be positive: %s", maxAge))
+		}
+
+		g.options.staleFallback = maxAge
+		return nil
+	}
+}
+
+// WithMergeTolerant skips files that fail to parse in LoadFiles instead of failing
+// the whole load, so one malformed file does not take down the configuration of every
+// other file. Skipped files are left out of the result, logged as warnings, counted
+// in the skipped_files metric and audited as failures. Missing files still fail the
+// load unless WithMergeTolerantMissing is also enabled; other errors (authentication,
+// security checks, clone failures) always do. The default is strict.
+func WithMergeTolerant(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.mergeTolerant = enabled
+		return nil
+	}
+}
+
+// WithMergeTolerantMissing makes tolerant LoadFiles calls (see WithMergeTolerant) skip
+// files that do not exist at the reference too, e.g. optional override files.
+func WithMergeTolerantMissing(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.mergeMissing = enabled
+		return nil
+	}
+}
+
+// skipsFileError reports whether a tolerant multi-file load skips a file failing with err
+func (o *providerOptions) skipsFileError(err error) bool {
+	if !o.mergeTolerant {
+		return false
+	}
+	if errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+		return o.mergeMissing
+	}
+	return errors.HasCode(err, "ARGUS_PARSE_ERROR") || errors.HasCode(err, "ARGUS_UNSUPPORTED_FORMAT") ||
+		errors.HasCode(err, "ARGUS_RESOURCE_LIMIT")
+}
+
+// WithStrictRefs rejects references that are ambiguous between a branch and a tag.
+//
+// A bare reference such as "main" is resolved as a branch first and as a tag only
+// when no such branch exists, so a tag named like a branch is ignored. Fully
+// qualified references (ref=refs/heads/main or ref=refs/tags/main) always name
+// exactly one reference. With WithStrictRefs(true), a bare name matching both a
+// branch and a tag fails with ARGUS_INVALID_CONFIG instead of selecting the branch.
+func WithStrictRefs(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.strictRefs = enabled
+		return nil
+	}
+}
+
+// WithStrictPoll rejects poll URL parameters outside the supported range.
+//
+// A poll interval below the 5 second minimum or above the 10 minute maximum is
+// clamped to the nearest bound by default, with a warning logged, so poll=1s
+// polls every 5 seconds. With WithStrictPoll(true) such URLs fail with
+// ARGUS_INVALID_CONFIG instead. Intervals are durations such as 30s or 2m, or bare
+// numbers of seconds; unparseable intervals are always rejected.
+func WithStrictPoll(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.strictPoll = enabled
+		return nil
+	}
+}
+
+// WithPinResolvedRef pins each reference to the commit its first Load or LoadDetailed
+// resolved to, so later loads of the repository and reference read that commit even
+// after the reference moved, until Repin moves the pin. Watches still follow their
+// reference, and releases and blobs are not pinned.
+func WithPinResolvedRef(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.pinResolvedRef = enabled
+		return nil
+	}
+}
+
+// WithIncludes resolves include directives in configuration files: a "$include" key
+// naming a file or a list of files of the same repository and commit, or the YAML
+// !include tag. Included files are merged in order beneath the keys next to the
+// directive, and may include others. Paths are relative to the including file and
+// validated like configuration paths; cycles, absolute paths and chains deeper than
+// WithMaxIncludeDepth fail with ARGUS_SECURITY_ERROR. Disabled by default, so "$include" keys
+// and !include tags are loaded as plain values.
+func WithIncludes(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.includes = enabled
+		return nil
+	}
+}
+
+// WithMaxIncludeDepth sets how deeply included files may include others (default 10):
+// with depth 1 a configuration may include files, but those may not include any.
+// Deeper chains fail with ARGUS_SECURITY_ERROR, so runaway composition is bounded
+// even where cycle detection does not apply. Depths below 1 fail with
+// ARGUS_INVALID_CONFIG.
+func WithMaxIncludeDepth(depth int) Option {
+	return func(g *GitProvider) error {
+		if depth < 1 {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("max include depth must be at least 1, got %d", depth))
+		}
+		g.options.maxIncludeDepth = depth
+		return nil
+	}
+}
+
+// effectiveMaxIncludeDepth returns the include depth limit, applying the default
+func (o *providerOptions) effectiveMaxIncludeDepth() int {
+	if o.maxIncludeDepth == 0 {
+		return defaultMaxIncludeDepth
+	}
+	return o.maxIncludeDepth
+}
+
+// WithRequiredKeys fails single-file loads and watch updates whose configuration lacks
+// any of the given keys with ARGUS_SCHEMA_ERROR listing the missing ones. Keys are
+// dotted paths into nested objects, such as "database.host", whose segments may also
+// index arrays ("servers.0.url"); a key holding null counts as missing. The check runs
+// after includes are resolved, so included keys satisfy it. Empty keys or segments
+// fail with ARGUS_INVALID_CONFIG.
+//
+// Example:
+//
+//	git.WithRequiredKeys("database.host", "database.port", "service")
+func WithRequiredKeys(keys ...string) Option {
+	return func(g *GitProvider) error {
+		for _, key := range keys {
+			if key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") || strings.Contains(key, "..") {
+				return errors.New("ARGUS_INVALID_CONFIG",
+					fmt.Sprintf("invalid required key %q (expected a dotted key path)", key))
+			}
+		}
+		g.options.requiredKeys = append([]string(nil), keys...)
+		return nil
+	}
+}
+
+// WithAnonymousFallback repeats Git operations on HTTP(S) repositories without credentials
+// when the server rejects the URL's credentials (HTTP 401 or 403), so public repositories
+// keep loading while an expired or revoked token is being rotated. A warning is logged
+// whenever the anonymous attempt succeeds. If it fails as well, the original
+// authentication error is returned unchanged, so the fallback does not reveal whether
+// a repository exists or is private.
+func WithAnonymousFallback(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.anonFallback = enabled
+		return nil
+	}
+}
+
+// WithDiskCache persists the configuration cache to dir and hydrates it on creation.
+//
+// Every configuration cached after a clone is also written to dir, keyed by
+// repository, file, reference and commit, and a provider created later with the
+// same directory starts with those entries cached, so restarts do not re-clone
+// every repository. Entries are verified against a checksum and discarded once
+// older than the cache TTL, except for commit-pinned references.
+//
+// SECURITY: Cached files contain configuration content in plain form. The directory
+// is created with 0700 permissions and should not be shared with other users.
+func WithDiskCache(dir string) Option {
+	return func(g *GitProvider) error {
+		if strings.TrimSpace(dir) == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "disk cache directory cannot be empty")
+		}
+
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return errors.Wrap(err, "ARGUS_IO_ERROR",
+				fmt.Sprintf("failed to create disk cache directory: %s", dir))
+		}
+
+		g.options.diskCacheDir = dir
+		return nil
+	}
+}
+
+// WithTempDir creates temporary clones under dir instead of the system temp directory.
+//
+// Use it where $TMPDIR is small or read-only, e.g. in hardened containers, to
+// point clones at a writable volume sized for the repositories being loaded.
+// The directory must already exist and be writable; it is checked when the
+// provider is created. Clones are created as argus-git-* subdirectories, and
+// cleanup only ever removes those subdirectories.
+func WithTempDir(dir string) Option {
+	return func(g *GitProvider) error {
+		if strings.TrimSpace(dir) == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "temp directory cannot be empty")
+		}
+
+		info, err := os.Stat(dir)
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_IO_ERROR",
+				fmt.Sprintf("temp directory is not accessible: %s", dir))
+		}
+		if !info.IsDir() {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("temp directory is not a directory: %s", dir))
+		}
+
+		// Probe writability the same way clones will use the directory
+		probe, err := os.MkdirTemp(dir, "argus-git-probe-*")
+		if err != nil {
+			return errors.Wrap(err, "ARGUS_IO_ERROR",
+				fmt.Sprintf("temp directory is not writable: %s", dir))
+		}
+		_ = os.Remove(probe)
+
+		g.options.tempDir = dir
+		return nil
+	}
+}
+
+// WithTempSweepOnStart removes clone directories orphaned by earlier processes.
+//
+// Temporary clones are tracked in memory only, so a process killed mid-clone
+// leaks its argus-git-* directories. When enabled, NewProvider removes such
+// directories from the temp base (see WithTempDir) that were last modified more
+// than an hour ago. Clones never live that long, so directories in use by other
+// live providers sharing the temp base are left alone. The sweep is best-effort.
+func WithTempSweepOnStart(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.tempSweepOnStart = enabled
+		return nil
+	}
+}
+
+// WithProgressReporter reports the progress of clones and fetches to report.
+//
+// Git servers send progress as free-form text; it is parsed into ProgressEvent
+// values (stage and object counts) and anything unrecognized is dropped, so the
+// reporter never sees raw server output. Events are delivered synchronously from
+// the transfer, possibly for several repositories at once, so report must be
+// fast and safe for concurrent use. Without a reporter, servers are asked not to
+// send progress at all.
+//
+// Example:
+//
+//	provider, err := git.NewProvider(git.WithProgressReporter(func(e git.ProgressEvent) {
+//	    log.Printf("%s: %s %d/%d", e.RepoURL, e.Stage, e.Current, e.Total)
+//	}))
+func WithProgressReporter(report func(ProgressEvent)) Option {
+	return func(g *GitProvider) error {
+		if report == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "progress reporter cannot be nil")
+		}
+
+		g.options.progressReporter = report
+		return nil
+	}
+}
+
+// WithLogger logs diagnostic events to logger: references resolved, cache hits and
+// misses, clone start and end, retries, mirror fallbacks, stale serves, parse
+// results and watch changes. Routine events are logged at debug level, degraded
+// operation (retries, fallbacks, failures) at warn level and watch changes at
+// info level, so the logger's level selects the detail.
+//
+// SECURITY: Repository URLs and error messages are redacted before they are
+// logged; tokens, passwords and header values never reach the logger.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	provider, err := git.NewProvider(git.WithLogger(logger))
+func WithLogger(logger *slog.Logger) Option {
+	return func(g *GitProvider) error {
+		if logger == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "logger cannot be nil")
+		}
+
+		g.options.logger = logger
+		return nil
+	}
+}
+
+// WithAuditSink sends an AuditRecord to sink for every configuration load: each
+// Load and LoadDetailed call, each file of a LoadFiles call and each configuration
+// delivered by a watch. Records are emitted for cache hits (with CacheHit set) and
+// for failed loads alike, so the sink sees exactly one record per load; watches
+// record their deliveries, not failed polls. Records carry redacted repository
+// URLs and errors, never credentials, and are safe to ship to an audit store.
+//
+// The sink is called synchronously when the load completes, possibly from several
+// goroutines at once, so it must be fast and safe for concurrent use.
+//
+// Example:
+//
+//	provider, err := git.NewProvider(git.WithAuditSink(func(r git.AuditRecord) {
+//	    auditLog.Write(r.Time, r.RepoURL, r.FilePath, r.CommitHash, r.Outcome)
+//	}))
+func WithAuditSink(sink func(AuditRecord)) Option {
+	return func(g *GitProvider) error {
+		if sink == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "audit sink cannot be nil")
+		}
+
+		g.options.auditSink = sink
+		return nil
+	}
+}
+
+// WithChangeHook calls hook for every configuration change a watch delivers, with the
+// configuration before and after the change and the commit it was read from. The hook
+// runs on a goroutine of its own per watch, in delivery order, so it may be slow
+// without stalling polling; hooks of different watches may run concurrently. The
+// configurations are the maps delivered on the watch channel and must not be modified.
+//
+// Example:
+//
+//	provider, err := git.NewProvider(git.WithChangeHook(func(e git.ChangeEvent) {
+//	    log.Printf("%s changed at %s: %v -> %v", e.FilePath, e.CommitHash, e.Old["replicas"], e.New["replicas"])
+//	}))
+func WithChangeHook(hook func(ChangeEvent)) Option {
+	return func(g *GitProvider) error {
+		if hook == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "change hook cannot be nil")
+		}
+
+		g.options.changeHook = hook
+		return nil
+	}
+}
+
+// WithWatchErrorPolicy selects what a watch delivers when the watched configuration
+// becomes invalid, i.e. fails to parse or lacks a required key (see WithRequiredKeys):
+//
+//   - WatchKeepLastGood (the default): nothing, so the last good configuration stays current
+//   - WatchDeliverError: the error, on the Errors channel of handles returned by StartWatch;
+//     watches started with Watch have no such channel and keep the last good configuration
+//   - WatchDeliverEmpty: an empty configuration
+//
+// Every policy logs a warning and counts the load in the invalid_watch_loads metric.
+// Unknown policies fail with ARGUS_INVALID_CONFIG.
+func WithWatchErrorPolicy(policy string) Option {
+	return func(g *GitProvider) error {
+		switch policy {
+		case WatchKeepLastGood, WatchDeliverError, WatchDeliverEmpty:
+		default:
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("unknown watch error policy: %q (expected %s, %s or %s)",
+				policy, WatchKeepLastGood, WatchDeliverError, WatchDeliverEmpty))
+		}
+
+		g.options.watchErrorPolicy = policy
+		return nil
+	}
+}
+
+// effectiveWatchErrorPolicy returns the configured watch error policy or the default
+func (o *providerOptions) effectiveWatchErrorPolicy() string {
+	return cmp.Or(o.watchErrorPolicy, WatchKeepLastGood)
+}
+
+// WithCABundle trusts the PEM certificates in bundle for HTTPS Git servers and
+// releases APIs, in addition to the system certificate pool, e.g. for servers
+// with certificates issued by a corporate CA. It takes precedence over
+// GIT_SSL_CAINFO with WithGitEnvironment.
+func WithCABundle(bundle []byte) Option {
+	return func(g *GitProvider) error {
+		if err := validateCABundle(bundle); err != nil {
+			return err
+		}
+
+		g.options.caBundle = append([]byte(nil), bundle...)
+		return nil
+	}
+}
+
+// WithHTTPProxy sends HTTP(S) Git and releases API requests through the proxy at
+// proxyURL ("http://", "https://" or "socks5://"; a bare host:port is an HTTP
+// proxy). Without it, the standard https_proxy and no_proxy variables apply. It
+// takes precedence over http.proxy with WithGitEnvironment. SSH repositories are
+// not proxied.
+func WithHTTPProxy(proxyURL string) Option {
+	return func(g *GitProvider) error {
+		normalized, err := normalizeProxyURL(proxyURL)
+		if err != nil {
+			return err
+		}
+
+		g.options.proxyURL = normalized
+		return nil
+	}
+}
+
+// WithGitEnvironment reads the git CLI's transport environment variables when the
+// provider is created, so it behaves like git in the same environment: the
+// GIT_SSL_CAINFO CA bundle, GIT_SSL_NO_VERIFY, and the http.proxy, http.sslCAInfo
+// and http.sslVerify settings of GIT_CONFIG_COUNT/GIT_CONFIG_KEY_<n>/GIT_CONFIG_VALUE_<n>.
+// Explicit options take precedence. See gitenv.go for the supported subset.
+//
+// SECURITY: GIT_SSL_NO_VERIFY disables certificate verification for every HTTPS
+// repository, as it does for git; the environment is therefore only read on
+// request.
+func WithGitEnvironment(enabled bool) Option {
+	return func(g *GitProvider) error {
+		g.options.gitEnvironment = enabled
+		return nil
+	}
+}
+
+// WithSSHUser sets the SSH username for repository URLs that do not name one, for
+// servers and gateways that reject the conventional "git" user (e.g. "gitolite").
+// A username in the URL itself, as in "ssh://deploy@host/repo.git", takes precedence.
+func WithSSHUser(user string) Option {
+	return func(g *GitProvider) error {
+		if user == "" || strings.ContainsAny(user, "@:/ ") || strings.IndexFunc(user, unicode.IsControl) != -1 {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid SSH username: %q", user))
+		}
+
+		g.options.sshUser = user
+		return nil
+	}
+}
+
+// WithSSHPassphraseEnv reads the passphrase of encrypted SSH keys from the environment
+// variable name, so it never has to appear in configuration URLs. The variable is
+// read whenever a key is loaded; an unset variable fails authentication with
+// ARGUS_AUTH_ERROR. A passphrase in the URL (auth=key:path:passphrase) takes precedence.
+func WithSSHPassphraseEnv(name string) Option {
+	return func(g *GitProvider) error {
+		if name == "" || strings.ContainsAny(name, "= \x00") {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid environment variable name: %q", name))
+		}
+
+		g.options.sshPassphrase = func(string) (string, error) {
+			passphrase, ok := os.LookupEnv(name)
+			if !ok {
+				return "", errors.New("ARGUS_AUTH_ERROR", fmt.Sprintf("SSH passphrase environment variable %s is not set", name))
+			}
+			return passphrase, nil
+		}
+		return nil
+	}
+}
+
+// WithSSHPassphraseFile reads the passphrase of encrypted SSH keys from the file at
+// path, e.g. a mounted secret. Like SSH keys, the file must not be accessible to
+// other users (0600 or less); a trailing newline is ignored. The file is read
+// whenever a key is loaded, so rotated secrets are picked up. A passphrase in the
+// URL takes precedence.
+func WithSSHPassphraseFile(path string) Option {
+	return func(g *GitProvider) error {
+		if path == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "SSH passphrase file path cannot be empty")
+		}
+
+		g.options.sshPassphrase = func(string) (string, error) {
+			if err := validateSecretFile(path, "SSH passphrase file"); err != nil {
+				return "", err
+			}
+
+			// #nosec G304 - Path is configured by the application, not taken from URLs
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return "", errors.Wrap(err, "ARGUS_IO_ERROR", "failed to read SSH passphrase file")
+			}
+			return strings.TrimRight(string(content), "\r\n"), nil
+		}
+		return nil
+	}
+}
+
+// WithSSHPassphraseFunc obtains the passphrase of encrypted SSH keys from fn, called
+// with the key path whenever a key without a URL passphrase is loaded, e.g. to query
+// a secret manager or prompt interactively. Keys are cached after loading, so fn is
+// not called on every operation. The passphrase of an unencrypted key is ignored.
+// fn may be called concurrently and must not return the passphrase in its errors.
+func WithSSHPassphraseFunc(fn func(keyPath string) (string, error)) Option {
+	return func(g *GitProvider) error {
+		if fn == nil {
+			return errors.New("ARGUS_INVALID_CONFIG", "SSH passphrase function cannot be nil")
+		}
+
+		g.options.sshPassphrase = fn
+		return nil
+	}
+}
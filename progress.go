@@ -0,0 +1,121 @@
+// progress.go: Structured clone progress reporting
+//
+// Git servers report clone progress as free-form sideband text. With
+// WithProgressReporter, that text is parsed into ProgressEvent values; only
+// recognized progress lines are reported and the raw server text never is.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+// Progress stages reported by Git servers
+const (
+	ProgressEnumerating = "enumerating" // Objects are being enumerated
+	ProgressCounting    = "counting"    // Objects are being counted
+	ProgressCompressing = "compressing" // Objects are being compressed
+	ProgressTotal       = "total"       // The pack has been sent; Current and Total are the object count
+)
+
+// maxProgressLine bounds buffered sideband text without a line terminator
+const maxProgressLine = 256
+
+// ProgressEvent is a parsed progress update of a clone or fetch
+type ProgressEvent struct {
+	RepoURL string // Repository being transferred
+	Stage   string // One of the Progress* stages
+	Current int64  // Objects processed so far in the stage
+	Total   int64  // Objects in the stage; zero when the server does not report it
+	Done    bool   // The stage is complete
+}
+
+var (
+	// progressStagePattern matches lines like "Counting objects:  50% (2/4)" and
+	// "Enumerating objects: 12, done."
+	progressStagePattern = regexp.MustCompile(
+		`^(Enumerating|Counting|Compressing) objects: +(?:\d{1,3}% \((\d{1,12})/(\d{1,12})\)|(\d{1,12}))(, done\.)?$`)
+
+	// progressTotalPattern matches the final "Total 12 (delta 3), reused ..." line
+	progressTotalPattern = regexp.MustCompile(`^Total (\d{1,12}) \(delta \d{1,12}\)`)
+
+	progressStages = map[string]string{
+		"Enumerating": ProgressEnumerating,
+		"Counting":    ProgressCounting,
+		"Compressing": ProgressCompressing,
+	}
+)
+
+// progressWriter receives the sideband progress stream of a transfer and reports parsed events
+type progressWriter struct {
+	repoURL string
+	report  func(ProgressEvent)
+	buffer  []byte
+}
+
+// newProgressWriter returns the progress writer for a transfer, or nil without a reporter
+func (g *GitProvider) newProgressWriter(repoURL string) *progressWriter {
+	if g.options.progressReporter == nil {
+		return nil
+	}
+	return &progressWriter{repoURL: repoURL, report: g.options.progressReporter}
+}
+
+// Write splits the stream into lines terminated by '\r' or '\n' and reports recognized ones
+func (w *progressWriter) Write(p []byte) (int, error) {
+	data := append(w.buffer, p...)
+	for {
+		end := bytes.IndexAny(data, "\r\n")
+		if end < 0 {
+			break
+		}
+		w.reportLine(data[:end])
+		data = data[end+1:]
+	}
+
+	// SECURITY: Drop runaway lines instead of buffering arbitrary server output
+	if len(data) > maxProgressLine {
+		data = nil
+	}
+	w.buffer = append(w.buffer[:0], data...)
+
+	return len(p), nil
+}
+
+// reportLine reports a single progress line if it is a recognized progress update
+func (w *progressWriter) reportLine(line []byte) {
+	line = bytes.TrimPrefix(bytes.TrimSpace(line), []byte("remote: "))
+
+	if match := progressStagePattern.FindSubmatch(line); match != nil {
+		event := ProgressEvent{
+			RepoURL: w.repoURL,
+			Stage:   progressStages[string(match[1])],
+			Done:    len(match[5]) > 0,
+		}
+		if len(match[4]) > 0 {
+			event.Current = parseProgressCount(match[4])
+		} else {
+			event.Current = parseProgressCount(match[2])
+			event.Total = parseProgressCount(match[3])
+		}
+		w.report(event)
+		return
+	}
+
+	if match := progressTotalPattern.FindSubmatch(line); match != nil {
+		total := parseProgressCount(match[1])
+		w.report(ProgressEvent{RepoURL: w.repoURL, Stage: ProgressTotal, Current: total, Total: total, Done: true})
+	}
+}
+
+// parseProgressCount parses an object count matched by a progress pattern
+func parseProgressCount(digits []byte) int64 {
+	count, _ := strconv.ParseInt(string(digits), 10, 64)
+	return count
+}
@@ -0,0 +1,101 @@
+// progress_test.go
+//
+// Clone progress reporting tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestProgressWriter tests parsing sideband progress text into events
+func TestProgressWriter(t *testing.T) {
+	var events []ProgressEvent
+	writer := &progressWriter{repoURL: "https://github.com/org/configs.git", report: func(event ProgressEvent) {
+		events = append(events, event)
+	}}
+
+	// Progress arrives in arbitrary chunks, with '\r' separating in-place updates
+	stream := "Enumerating objects: 12, done.\n" +
+		"Counting objects:  50% (6/12)\rCounting objects: 100% (12/12), done.\n" +
+		"remote: Compressing objects: 100% (8/8), done.\n" +
+		"\x1b[31mTotal 12 (delta 2)\x1b[0m\n" + // Escape sequences are not progress
+		"Visit https://attacker.example.com to continue\n" +
+		strings.Repeat("x", 2*maxProgressLine) +
+		"\nTotal 12 (delta 2), reused 0 (delta 0), pack-reused 0\n"
+	for len(stream) > 0 {
+		n := min(7, len(stream))
+		if _, err := writer.Write([]byte(stream[:n])); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		stream = stream[n:]
+	}
+
+	url := "https://github.com/org/configs.git"
+	expected := []ProgressEvent{
+		{RepoURL: url, Stage: ProgressEnumerating, Current: 12, Done: true},
+		{RepoURL: url, Stage: ProgressCounting, Current: 6, Total: 12},
+		{RepoURL: url, Stage: ProgressCounting, Current: 12, Total: 12, Done: true},
+		{RepoURL: url, Stage: ProgressCompressing, Current: 8, Total: 8, Done: true},
+		{RepoURL: url, Stage: ProgressTotal, Current: 12, Total: 12, Done: true},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Unexpected events:\n got %+v\nwant %+v", events, expected)
+	}
+	if len(writer.buffer) != 0 {
+		t.Errorf("Expected no buffered text after the final line, got %q", writer.buffer)
+	}
+}
+
+// TestWithProgressReporter tests that the reporter receives events during a local clone
+func TestWithProgressReporter(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("configs/service-%d.json", i)] = fmt.Sprintf(`{"id": %d}`, i)
+	}
+	repo := newTestRepository(t, files)
+
+	var mutex sync.Mutex
+	var events []ProgressEvent
+	provider, err := NewProvider(WithProgressReporter(func(event ProgressEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+	}))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := provider.loadConfigFromRepo(ctx, repo.gitURL("configs/service-0.json", "main")); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(events) == 0 {
+		t.Fatal("Expected progress events during the clone")
+	}
+	last := events[len(events)-1]
+	if last.RepoURL != repo.dir || !last.Done || last.Current == 0 {
+		t.Errorf("Unexpected final event: %+v", last)
+	}
+
+	t.Run("Nil reporter", func(t *testing.T) {
+		if _, err := NewProvider(WithProgressReporter(nil)); err == nil {
+			t.Error("Expected error for nil reporter")
+		}
+	})
+}
@@ -0,0 +1,145 @@
+// raw.go: Raw file downloads as a fast path for public repositories
+//
+// Code hosting platforms serve the files of public repositories over plain HTTPS,
+// e.g. https://raw.githubusercontent.com/org/configs/main/app.yaml. With
+// WithRawFastPath(true), loads of unauthenticated GitHub and GitLab URLs first try
+// such a download, which replaces the reference lookup and the clone with a
+// single request:
+//
+//	github.com/<project>            https://raw.githubusercontent.com/<project>/<ref>/<path>
+//	gitlab.com, gitlab.* hosts      https://<host>/<project>/-/raw/<ref>/<path>
+//
+// Any failure to download, such as a private repository, a missing file or an
+// unreachable host, falls back to the clone, which reports the actual error.
+// Downloaded content is parsed like a tree file; since the serving commit is not
+// known, results have no commit hash and are not cached. Platforms may serve raw
+// files from a CDN cache, so a change can take a few minutes to be visible.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	gohttp "net/http"
+	"net/url"
+	"strings"
+
+	"github.com/agilira/go-errors"
+)
+
+// rawFileURL returns the raw download URL of the URL's file, or false when the host
+// has no supported raw endpoint or the reference cannot be expressed in one
+func rawFileURL(gitURL *GitURL) (string, bool) {
+	if !strings.HasPrefix(strings.ToLower(gitURL.RepoURL), "https://") || strings.HasPrefix(gitURL.Reference, "refs/") ||
+		isRevisionExpression(gitURL.Reference) {
+		return "", false
+	}
+	parsedURL, err := url.Parse(transportURL(gitURL.RepoURL))
+	if err != nil || parsedURL.User != nil {
+		return "", false
+	}
+
+	project := strings.TrimSuffix(strings.Trim(parsedURL.Path, "/"), ".git")
+	if project == "" {
+		return "", false
+	}
+	reference := gitURL.Reference
+	if reference == "" {
+		reference = "HEAD"
+	}
+	filePath := (&url.URL{Path: gitURL.FilePath}).EscapedPath()
+	ref := (&url.URL{Path: reference}).EscapedPath()
+
+	host := strings.ToLower(parsedURL.Hostname())
+	switch {
+	case host == "github.com" && parsedURL.Port() == "":
+		return "https://raw.githubusercontent.com/" + project + "/" + ref + "/" + filePath, true
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return "https://" + parsedURL.Host + "/" + project + "/-/raw/" + ref + "/" + filePath, true
+	}
+	return "", false
+}
+
+// loadRawConfig downloads and parses the URL's file from its raw endpoint. It reports
+// false, without an error, when the load has to clone instead: when the fast path is
+// disabled or does not apply to the URL, and when the download fails.
+func (g *GitProvider) loadRawConfig(ctx context.Context, gitURL *GitURL) (*LoadResult, bool, error) {
+	// Raw endpoints only serve public files, and includes need the rest of the tree.
+	// Downloads report no commit, so references to be pinned are cloned.
+	if !g.options.rawFastPath || g.options.includes || gitURL.FilePath == "" || g.withCredential(gitURL).AuthType != "" ||
+		g.pinnable(gitURL) {
+		return nil, false, nil
+	}
+	rawURL, ok := rawFileURL(gitURL)
+	if !ok {
+		return nil, false, nil
+	}
+
+	content, err := g.rawRequest(ctx, rawURL)
+	if errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+		return nil, true, err // The clone would read the same file
+	}
+	if err != nil {
+		g.logEvent(ctx, slog.LevelDebug, "raw download failed, cloning",
+			append(logURLAttrs(gitURL), logErrorAttr(err))...)
+		return nil, false, nil
+	}
+	g.logEvent(ctx, slog.LevelDebug, "configuration downloaded from raw endpoint",
+		append(logURLAttrs(gitURL), slog.Int("bytes", len(content)))...)
+
+	config, err := g.parseConfigFileAs(gitURL.FilePath, gitURL.Format, content)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return &LoadResult{
+		Config:    config,
+		Format:    resultFormat(gitURL.FilePath, gitURL.Format),
+		Reference: gitURL.Reference,
+		Size:      len(content),
+	}, true, nil
+}
+
+// rawRequest downloads a raw file without credentials, enforcing the configuration
+// file size limit. Redirects are checked as for release assets.
+func (g *GitProvider) rawRequest(ctx context.Context, rawURL string) ([]byte, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid raw file URL")
+	}
+	if err := validateReleaseURL(parsedURL); err != nil {
+		return nil, err
+	}
+
+	req, err := gohttp.NewRequestWithContext(ctx, gohttp.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid raw file URL")
+	}
+
+	resp, err := g.releaseClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "raw file request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New("ARGUS_GIT_ERROR", fmt.Sprintf("raw file request failed: %s", resp.Status))
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigFileSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to read raw file")
+	}
+	if len(content) > maxConfigFileSize {
+		g.metrics.incrementResourceLimitHits()
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("configuration file too large (max %d bytes)", maxConfigFileSize))
+	}
+	return content, nil
+}
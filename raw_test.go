@@ -0,0 +1,133 @@
+// raw_test.go
+//
+// Raw file fast path tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestRawFileURL tests the raw endpoints built for repository URLs
+func TestRawFileURL(t *testing.T) {
+	testCases := []struct {
+		repoURL   string
+		filePath  string
+		reference string
+		want      string
+	}{
+		{"https://github.com/org/configs.git", "services/app.yaml", "main", "https://raw.githubusercontent.com/org/configs/main/services/app.yaml"},
+		{"https://github.com/org/configs.git", "app.json", "", "https://raw.githubusercontent.com/org/configs/HEAD/app.json"},
+		{"https://github.com/org/configs.git", "my app.json", "release/2025", "https://raw.githubusercontent.com/org/configs/release/2025/my%20app.json"},
+		{"https://gitlab.com/group/sub/configs.git", "app.toml", "v1.0.0", "https://gitlab.com/group/sub/configs/-/raw/v1.0.0/app.toml"},
+		{"https://gitlab.acme.io/group/configs.git", "app.json", "main", "https://gitlab.acme.io/group/configs/-/raw/main/app.json"},
+		{"https://bitbucket.org/org/configs.git", "app.json", "main", ""},
+		{"http://github.com/org/configs.git", "app.json", "main", ""},
+		{"https://github.com/org/configs.git", "app.json", "refs/pull/1/head", ""},
+		{"git@github.com:org/configs.git", "app.json", "main", ""},
+	}
+
+	for _, tc := range testCases {
+		got, ok := rawFileURL(&GitURL{RepoURL: tc.repoURL, FilePath: tc.filePath, Reference: tc.reference})
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("rawFileURL(%s, %s, %s) = %q, %v; want %q", tc.repoURL, tc.filePath, tc.reference, got, ok, tc.want)
+		}
+	}
+}
+
+// TestWithRawFastPath tests loading public files from raw endpoints and falling back to cloning
+func TestWithRawFastPath(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	provider := newReleaseTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.Host+r.URL.Path)
+		mu.Unlock()
+
+		switch r.Host + r.URL.Path {
+		case "raw.githubusercontent.com/org/configs/main/services/app.yaml":
+			_, _ = w.Write([]byte("service: api\nreplicas: 3\n"))
+		case "gitlab.com/group/configs/-/raw/v1.0.0/app.json":
+			_, _ = w.Write([]byte(`{"service": "worker"}`))
+		case "raw.githubusercontent.com/org/configs/main/broken.json":
+			_, _ = w.Write([]byte(`{"service": `))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	provider.options.rawFastPath = true
+
+	var logs bytes.Buffer
+	provider.options.logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	requests := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	}
+
+	result, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#services/app.yaml?ref=main")
+	if err != nil {
+		t.Fatalf("LoadDetailed failed: %v", err)
+	}
+	if result.Config["service"] != "api" || result.Config["replicas"] != 3 || result.Format != FormatYAML || result.Reference != "main" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.CommitHash != "" {
+		t.Errorf("Expected no commit hash for a raw download, got %s", result.CommitHash)
+	}
+
+	config, err := provider.Load(ctx, "https://gitlab.com/group/configs.git#app.json?ref=v1.0.0")
+	if err != nil {
+		t.Fatalf("Load from GitLab failed: %v", err)
+	}
+	if config["service"] != "worker" {
+		t.Errorf("Unexpected config: %v", config)
+	}
+
+	// Downloaded content that fails to parse is reported rather than cloned again
+	if _, err := provider.Load(ctx, "https://github.com/org/configs.git#broken.json?ref=main"); !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+		t.Errorf("Expected ARGUS_PARSE_ERROR, got %v", err)
+	}
+
+	// Failed downloads fall back to cloning, which cannot reach the host in tests
+	fallbackCtx, fallbackCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer fallbackCancel()
+	if _, err := provider.Load(fallbackCtx, "https://github.com/org/configs.git#missing.json?ref=main"); err == nil {
+		t.Error("Expected the fallback clone to fail")
+	}
+	if !strings.Contains(logs.String(), "raw download failed, cloning") {
+		t.Errorf("Expected the failed download to fall back to cloning, got logs:\n%s", logs.String())
+	}
+
+	// Authenticated URLs never use the raw endpoint
+	before := len(requests())
+	_, _ = provider.Load(fallbackCtx, "https://github.com/org/configs.git#services/app.yaml?ref=main&auth=token:ghp_test")
+	if after := requests(); len(after) != before {
+		t.Errorf("Expected no raw download for an authenticated URL, got %v", after[before:])
+	}
+
+	// References to be pinned are cloned to resolve their commit
+	provider.options.pinResolvedRef = true
+	before = len(requests())
+	_, _ = provider.Load(fallbackCtx, "https://github.com/org/configs.git#services/app.yaml?ref=main")
+	if after := requests(); len(after) != before {
+		t.Errorf("Expected no raw download for a reference to be pinned, got %v", after[before:])
+	}
+}
@@ -0,0 +1,119 @@
+// refpin.go: Pinning references to the commit they first resolved to
+//
+// For reproducible deploys a process should see one version of its configuration
+// for its whole lifetime, even while the branch moves on. With
+// WithPinResolvedRef(true), the first load of a reference records the commit it
+// resolved to, and later loads of the same repository and reference read that
+// commit instead of the reference:
+//
+//	provider, _ := git.NewProvider(git.WithPinResolvedRef(true))
+//	config, _ := provider.Load(ctx, "https://github.com/org/configs.git#app.yaml?ref=main")
+//	...                                         // main advances
+//	config, _ = provider.Load(ctx, "https://github.com/org/configs.git#app.yaml?ref=main")
+//	// Still the configuration of the first load
+//
+// Pins are shared by all files of a repository and reference, so files loaded
+// later come from the same commit as the first. Repin moves a pin to the current
+// commit of its reference. Watches, releases and blobs are never pinned.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/agilira/go-errors"
+)
+
+// refPinKey identifies the repository and reference a pin applies to
+func refPinKey(gitURL *GitURL) string {
+	return gitURL.RepoURL + "\x00" + gitURL.Reference + "\x00" + gitURL.TagConstraint
+}
+
+// pinnable reports whether loads of the URL are pinned with WithPinResolvedRef
+func (g *GitProvider) pinnable(gitURL *GitURL) bool {
+	return g.options.pinResolvedRef && gitURL.Release == "" && !gitURL.isImmutable()
+}
+
+// applyRefPin returns the URL at its pinned commit, if any, and the key of its pin;
+// the key is empty when the URL is not pinnable
+func (g *GitProvider) applyRefPin(gitURL *GitURL) (*GitURL, string) {
+	if !g.pinnable(gitURL) {
+		return gitURL, ""
+	}
+
+	key := refPinKey(gitURL)
+	g.refPinsMutex.RLock()
+	commit, ok := g.refPins[key]
+	g.refPinsMutex.RUnlock()
+	if !ok {
+		return gitURL, key
+	}
+
+	pinned := *gitURL
+	pinned.Reference = commit
+	pinned.TagConstraint = ""
+	pinned.FallbackRefs = nil
+	return &pinned, key
+}
+
+// recordRefPin pins a reference to the commit its first load resolved to, unless a
+// concurrent load pinned it first
+func (g *GitProvider) recordRefPin(key string, result *LoadResult) {
+	if key == "" || result.Stale || !isCommitHash(result.CommitHash) {
+		return
+	}
+
+	g.refPinsMutex.Lock()
+	defer g.refPinsMutex.Unlock()
+	if g.refPins == nil {
+		g.refPins = make(map[string]string)
+	}
+	if _, exists := g.refPins[key]; !exists {
+		g.refPins[key] = result.CommitHash
+	}
+}
+
+// Repin moves the pin of the URL's repository and reference to the commit the
+// reference currently resolves to, and returns that commit; revision expressions
+// such as main~1 are pinned to the same steps from the current commit of their base
+// (e.g. 3f2a...~1). Later loads of the reference read the new commit. It fails with ARGUS_INVALID_CONFIG unless
+// WithPinResolvedRef is enabled and the URL names a loadable reference.
+func (g *GitProvider) Repin(ctx context.Context, configURL string) (string, error) {
+	gitURL, err := g.parseRequestURL(ctx, configURL, true)
+	if err != nil {
+		return "", err
+	}
+	if !g.options.pinResolvedRef {
+		return "", errors.New("ARGUS_INVALID_CONFIG", "reference pinning is not enabled (use WithPinResolvedRef)")
+	}
+	if !g.pinnable(gitURL) {
+		return "", errors.New("ARGUS_INVALID_CONFIG", "URL does not name a reference that can be pinned")
+	}
+
+	resolvedURL, err := g.resolveTagConstraint(ctx, gitURL)
+	if err != nil {
+		return "", err
+	}
+	commit, err := g.getRemoteCommitHash(ctx, resolvedURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Ancestors are pinned relative to the commit of their base, e.g. 3f2a...~1
+	commit += revisionSuffix(gitURL.Reference)
+
+	g.refPinsMutex.Lock()
+	if g.refPins == nil {
+		g.refPins = make(map[string]string)
+	}
+	g.refPins[refPinKey(gitURL)] = commit
+	g.refPinsMutex.Unlock()
+
+	g.logEvent(ctx, slog.LevelInfo, "reference repinned", append(logURLAttrs(gitURL), slog.String("commit", commit))...)
+	return commit, nil
+}
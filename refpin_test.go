@@ -0,0 +1,89 @@
+// refpin_test.go
+//
+// Reference pinning tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestWithPinResolvedRef tests that loads keep reading the commit a reference first resolved to
+func TestWithPinResolvedRef(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"app.json": `{"version": 1}`,
+		"db.json":  `{"version": 1}`,
+	})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true), WithPinResolvedRef(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	unpinned, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#"
+	load := func(t *testing.T, p *GitProvider, file string) *LoadResult {
+		t.Helper()
+		result, err := p.LoadDetailed(ctx, baseURL+file+"?ref=main")
+		if err != nil {
+			t.Fatalf("LoadDetailed of %s failed: %v", file, err)
+		}
+		return result
+	}
+
+	first := load(t, provider, "app.json")
+	advanced := repo.commit("bump", map[string]string{
+		"app.json": `{"version": 2}`,
+		"db.json":  `{"version": 2}`,
+	})
+
+	if result := load(t, unpinned, "app.json"); result.Config["version"] != float64(2) {
+		t.Fatalf("Expected an unpinned provider to follow the branch, got %v", result.Config)
+	}
+
+	// The branch advanced, but loads of any file of it stay at the pinned commit
+	for _, file := range []string{"app.json", "db.json"} {
+		result := load(t, provider, file)
+		if result.Config["version"] != float64(1) || result.CommitHash != first.CommitHash {
+			t.Errorf("Expected %s at the pinned commit %s, got %v at %s", file, first.CommitHash, result.Config, result.CommitHash)
+		}
+	}
+
+	// Other references are pinned independently
+	repo.branch("staging")
+	if result, err := provider.LoadDetailed(ctx, baseURL+"app.json?ref=staging"); err != nil || result.CommitHash != advanced {
+		t.Errorf("Expected staging at %s, got %v (err=%v)", advanced, result, err)
+	}
+
+	commit, err := provider.Repin(ctx, baseURL+"app.json?ref=main")
+	if err != nil {
+		t.Fatalf("Repin failed: %v", err)
+	}
+	if commit != advanced {
+		t.Errorf("Expected Repin to resolve %s, got %s", advanced, commit)
+	}
+	if result := load(t, provider, "app.json"); result.Config["version"] != float64(2) || result.CommitHash != advanced {
+		t.Errorf("Expected the repinned commit, got %v at %s", result.Config, result.CommitHash)
+	}
+
+	if _, err := unpinned.Repin(ctx, baseURL+"app.json?ref=main"); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG without WithPinResolvedRef, got %v", err)
+	}
+	if _, err := provider.Repin(ctx, baseURL+"app.json?ref="+first.CommitHash); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for a commit URL, got %v", err)
+	}
+}
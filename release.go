@@ -0,0 +1,357 @@
+// release.go: Configuration files published as release assets
+//
+// Some teams publish configuration as an asset of a GitHub or GitLab release
+// rather than as a file in the tree. With "release=<tag>" (or "release=latest")
+// the URL fragment names the asset instead of a file path:
+//
+//	https://github.com/org/configs.git#app.yaml?release=latest&auth=token:ghp_xxx
+//
+// The release is looked up through the platform's releases API using the URL's
+// credentials, and the asset is downloaded and parsed like any configuration
+// file. Release assets are not cached, since they are not tied to a commit.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	gohttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+const (
+	// latestRelease selects the most recent release instead of a release tag
+	latestRelease = "latest"
+
+	// maxReleaseMetadataSize bounds the release API responses describing releases
+	maxReleaseMetadataSize = 1024 * 1024
+
+	// releaseRequestTimeout bounds each releases API request and asset download
+	releaseRequestTimeout = 60 * time.Second
+
+	// maxReleaseRedirects bounds the redirects followed by an asset download
+	maxReleaseRedirects = 5
+)
+
+// Release API platforms
+const (
+	releasePlatformGitHub = "github"
+	releasePlatformGitLab = "gitlab"
+)
+
+// releaseAPI identifies the releases API of a repository
+type releaseAPI struct {
+	platform string // releasePlatformGitHub or releasePlatformGitLab
+	baseURL  string // API root, e.g. "https://api.github.com"
+	host     string // Repository host, which may receive the URL's credentials
+	project  string // Repository path, e.g. "org/configs"
+}
+
+// releaseAsset is a release asset as described by the releases API
+type releaseAsset struct {
+	tag         string // Tag name of the release
+	downloadURL string // URL the asset content is downloaded from
+	version     string // Changes whenever the asset is replaced
+}
+
+// newReleaseClient creates the HTTP client of releases API requests. Redirects,
+// which asset downloads commonly follow to a storage host, must stay on HTTPS and
+// may not lead to local or private addresses. Credentials are dropped when a
+// redirect leaves the original host.
+func newReleaseClient() *gohttp.Client {
+	return &gohttp.Client{
+		Timeout: releaseRequestTimeout,
+		CheckRedirect: func(req *gohttp.Request, via []*gohttp.Request) error {
+			if len(via) >= maxReleaseRedirects {
+				return errors.New("ARGUS_SECURITY_ERROR", "too many release asset redirects")
+			}
+			if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+				accept := req.Header.Get("Accept")
+				req.Header = make(gohttp.Header)
+				req.Header.Set("Accept", accept)
+			}
+			return validateReleaseURL(req.URL)
+		},
+	}
+}
+
+// validateReleaseURL ensures a releases API or asset URL is HTTPS and not a local or private host
+func validateReleaseURL(target *url.URL) error {
+	if target.Scheme != "https" {
+		return errors.New("ARGUS_SECURITY_ERROR",
+			fmt.Sprintf("release asset URL must use https, got %q", target.Scheme))
+	}
+	return validateGitHost(target.Host)
+}
+
+// releaseAPIFor returns the releases API of a repository URL. Only GitHub (including
+// GitHub Enterprise hosts named "github.*") and GitLab (including self-managed hosts
+// named "gitlab.*") publish releases through a supported API.
+func releaseAPIFor(repoURL string) (*releaseAPI, error) {
+	parsedURL, err := url.Parse(transportURL(repoURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid repository URL")
+	}
+
+	host := strings.ToLower(parsedURL.Hostname())
+	project := strings.TrimSuffix(strings.Trim(parsedURL.Path, "/"), ".git")
+	if project == "" {
+		return nil, errors.New("ARGUS_INVALID_CONFIG", "repository URL has no project path")
+	}
+
+	switch {
+	case host == "github.com":
+		return &releaseAPI{releasePlatformGitHub, "https://api.github.com", host, project}, nil
+	case strings.HasPrefix(host, "github."):
+		return &releaseAPI{releasePlatformGitHub, "https://" + host + "/api/v3", host, project}, nil
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return &releaseAPI{releasePlatformGitLab, "https://" + host + "/api/v4", host, project}, nil
+	}
+
+	return nil, errors.New("ARGUS_INVALID_CONFIG",
+		fmt.Sprintf("release assets are not supported for host %s (only GitHub and GitLab releases APIs are)", host))
+}
+
+// releaseURL returns the API URL describing the release
+func (api *releaseAPI) releaseURL(release string) string {
+	if api.platform == releasePlatformGitLab {
+		base := api.baseURL + "/projects/" + url.PathEscape(api.project) + "/releases/"
+		if release == latestRelease {
+			return base + "permalink/latest"
+		}
+		return base + url.PathEscape(release)
+	}
+
+	base := api.baseURL + "/repos/" + api.project + "/releases/"
+	if release == latestRelease {
+		return base + latestRelease
+	}
+	return base + "tags/" + url.PathEscape(release)
+}
+
+// findAsset decodes a release description and returns the named asset
+func (api *releaseAPI) findAsset(body []byte, name string) (*releaseAsset, error) {
+	var release struct {
+		TagName string          `json:"tag_name"`
+		Assets  json.RawMessage `json:"assets"` // Shape differs per platform
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "invalid releases API response")
+	}
+
+	if api.platform == releasePlatformGitLab {
+		// GitLab: asset links, downloaded from their direct URL
+		var assets struct {
+			Links []struct {
+				ID             int64  `json:"id"`
+				Name           string `json:"name"`
+				URL            string `json:"url"`
+				DirectAssetURL string `json:"direct_asset_url"`
+			} `json:"links"`
+		}
+		if len(release.Assets) > 0 {
+			if err := json.Unmarshal(release.Assets, &assets); err != nil {
+				return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "invalid releases API response")
+			}
+		}
+		for _, link := range assets.Links {
+			if link.Name == name {
+				downloadURL := link.DirectAssetURL
+				if downloadURL == "" {
+					downloadURL = link.URL
+				}
+				return &releaseAsset{release.TagName, downloadURL, fmt.Sprintf("%d:%s", link.ID, downloadURL)}, nil
+			}
+		}
+	} else {
+		// GitHub: uploaded assets, downloaded from their asset API URL
+		var assets []struct {
+			ID        int64  `json:"id"`
+			Name      string `json:"name"`
+			URL       string `json:"url"`
+			UpdatedAt string `json:"updated_at"`
+		}
+		if len(release.Assets) > 0 {
+			if err := json.Unmarshal(release.Assets, &assets); err != nil {
+				return nil, errors.Wrap(err, "ARGUS_PARSE_ERROR", "invalid releases API response")
+			}
+		}
+		for _, asset := range assets {
+			if asset.Name == name {
+				return &releaseAsset{release.TagName, asset.URL, fmt.Sprintf("%d:%s", asset.ID, asset.UpdatedAt)}, nil
+			}
+		}
+	}
+
+	return nil, errors.New("ARGUS_CONFIG_NOT_FOUND",
+		fmt.Sprintf("release %s has no asset named %s", release.TagName, name))
+}
+
+// setReleaseAuth authenticates a releases API request with the URL's credentials.
+// Credentials are only sent to the API and repository hosts, never to the storage
+// hosts asset downloads may be redirected or linked to.
+func (api *releaseAPI) setReleaseAuth(req *gohttp.Request, gitURL *GitURL) error {
+	host := strings.ToLower(req.URL.Hostname())
+	if host != api.host && host != repoHostname(api.baseURL) {
+		return nil
+	}
+
+	token := gitURL.AuthData["token"]
+	switch gitURL.AuthType {
+	case "token":
+		if api.platform == releasePlatformGitLab {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case "gitea":
+		req.Header.Set("Authorization", "token "+token)
+	case "basic":
+		req.SetBasicAuth(gitURL.AuthData["username"], gitURL.AuthData["password"])
+	case "header":
+		name, value := gitURL.AuthData["header_name"], gitURL.AuthData["header_value"]
+		if err := validateHTTPHeader(name, value); err != nil {
+			return err
+		}
+		req.Header.Set(name, value)
+	}
+	return nil
+}
+
+// releaseRequest performs a releases API request and returns at most limit bytes of
+// the response body; a larger body fails with ARGUS_RESOURCE_LIMIT
+func (g *GitProvider) releaseRequest(ctx context.Context, api *releaseAPI, gitURL *GitURL, target, accept string, limit int64) ([]byte, error) {
+	parsedTarget, err := url.Parse(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid release asset URL")
+	}
+	if err := validateReleaseURL(parsedTarget); err != nil {
+		return nil, err
+	}
+
+	req, err := gohttp.NewRequestWithContext(ctx, gohttp.MethodGet, parsedTarget.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_INVALID_CONFIG", "invalid release asset URL")
+	}
+	req.Header.Set("Accept", accept)
+	if err := api.setReleaseAuth(req, g.withCredential(gitURL)); err != nil {
+		return nil, err
+	}
+
+	resp, err := g.releaseClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "releases API request failed")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == gohttp.StatusNotFound:
+		return nil, errors.New("ARGUS_CONFIG_NOT_FOUND",
+			fmt.Sprintf("release %s or its asset %s not found", gitURL.Release, gitURL.FilePath))
+	case resp.StatusCode == gohttp.StatusUnauthorized || resp.StatusCode == gohttp.StatusForbidden:
+		return nil, errors.New("ARGUS_AUTH_ERROR",
+			fmt.Sprintf("releases API request unauthorized: %s", resp.Status))
+	case resp.StatusCode != gohttp.StatusOK:
+		return nil, errors.New("ARGUS_GIT_ERROR",
+			fmt.Sprintf("releases API request failed: %s", resp.Status))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to read releases API response")
+	}
+	if int64(len(body)) > limit {
+		g.metrics.incrementResourceLimitHits()
+		return nil, errors.New("ARGUS_RESOURCE_LIMIT",
+			fmt.Sprintf("release asset response too large (max %d bytes)", limit))
+	}
+	return body, nil
+}
+
+// fetchReleaseAsset looks up the release and the asset named by the URL's file path
+func (g *GitProvider) fetchReleaseAsset(ctx context.Context, api *releaseAPI, gitURL *GitURL) (*releaseAsset, error) {
+	var asset *releaseAsset
+	err := g.retryOperation(ctx, func() error {
+		body, err := g.releaseRequest(ctx, api, gitURL, api.releaseURL(gitURL.Release), "application/json", maxReleaseMetadataSize)
+		if err != nil {
+			return err
+		}
+		asset, err = api.findAsset(body, gitURL.FilePath)
+		return err
+	}, "releases API request")
+	return asset, err
+}
+
+// loadReleaseAsset downloads and parses the release asset named by the URL's file path.
+// The result's Reference is the release tag and its CommitHash is empty.
+func (g *GitProvider) loadReleaseAsset(ctx context.Context, gitURL *GitURL) (*LoadResult, error) {
+	api, err := releaseAPIFor(gitURL.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := g.fetchReleaseAsset(ctx, api, gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	err = g.retryOperation(ctx, func() error {
+		content, err = g.releaseRequest(ctx, api, gitURL, asset.downloadURL, "application/octet-stream", maxConfigFileSize)
+		return err
+	}, "release asset download")
+	if err != nil {
+		return nil, err
+	}
+	g.logEvent(ctx, slog.LevelDebug, "release asset downloaded",
+		append(logURLAttrs(gitURL), slog.String("release", asset.tag), slog.Int("bytes", len(content)))...)
+
+	config, err := g.parseConfigFileAs(gitURL.FilePath, gitURL.Format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoadResult{
+		Config:    config,
+		Format:    resultFormat(gitURL.FilePath, gitURL.Format),
+		Reference: asset.tag,
+		Size:      len(content),
+	}, nil
+}
+
+// hasReleaseAssetChanged reports whether the release asset was replaced since the last poll
+func (g *GitProvider) hasReleaseAssetChanged(ctx context.Context, gitURL *GitURL) bool {
+	api, err := releaseAPIFor(gitURL.RepoURL)
+	if err != nil {
+		return true
+	}
+	asset, err := g.fetchReleaseAsset(ctx, api, gitURL)
+	if err != nil {
+		return true // Reload to surface the error, as for repositories
+	}
+
+	// Assets are tracked per release and asset, not per repository
+	key := gitURL.RepoURL + "#" + gitURL.FilePath + "?release=" + gitURL.Release
+	g.repoCacheMutex.RLock()
+	cached, exists := g.repoCache[key]
+	g.repoCacheMutex.RUnlock()
+
+	if !exists || cached.LastCommit != asset.version {
+		g.updateRepoCache(key, asset.version)
+		return true
+	}
+	return false
+}
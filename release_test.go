@@ -0,0 +1,216 @@
+// release_test.go
+//
+// Release asset loading tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// releaseServerTransport sends every request to a test server, preserving the
+// original host so handlers can route by the platform host that was requested
+type releaseServerTransport struct {
+	server *httptest.Server
+}
+
+func (rt *releaseServerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(rt.server.URL)
+	forwarded := req.Clone(req.Context())
+	forwarded.Host = req.URL.Host
+	forwarded.URL.Host = target.Host
+	return rt.server.Client().Transport.RoundTrip(forwarded)
+}
+
+// newReleaseTestProvider creates a provider whose releases API requests are served by handler
+func newReleaseTestProvider(t *testing.T, handler http.HandlerFunc) *GitProvider {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := newNoRetryProvider()
+	provider.releaseClient.Transport = &releaseServerTransport{server: server}
+	return provider
+}
+
+// writeReleaseJSON writes a releases API response
+func writeReleaseJSON(w http.ResponseWriter, release interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(release)
+}
+
+// TestGitProvider_GitHubReleaseAsset tests loading an asset of a GitHub release
+func TestGitProvider_GitHubReleaseAsset(t *testing.T) {
+	var assetAuth string
+	provider := newReleaseTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "api.github.com/repos/org/configs/releases/latest", "api.github.com/repos/org/configs/releases/tags/v1.2.0":
+			if r.Header.Get("Authorization") != "Bearer ghp_test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writeReleaseJSON(w, map[string]interface{}{
+				"tag_name": "v1.2.0",
+				"assets": []map[string]interface{}{
+					{"id": 6, "name": "notes.txt", "url": "https://api.github.com/repos/org/configs/releases/assets/6"},
+					{"id": 7, "name": "app.yaml", "url": "https://api.github.com/repos/org/configs/releases/assets/7", "updated_at": "2025-01-01T00:00:00Z"},
+				},
+			})
+		case "api.github.com/repos/org/configs/releases/assets/7":
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			http.Redirect(w, r, "https://objects.githubusercontent.com/assets/7", http.StatusFound)
+		case "objects.githubusercontent.com/assets/7":
+			assetAuth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte("service: api\nreplicas: 3\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, release := range []string{"latest", "v1.2.0"} {
+		t.Run(release, func(t *testing.T) {
+			result, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#app.yaml?release="+release+"&auth=token:ghp_test")
+			if err != nil {
+				t.Fatalf("LoadDetailed failed: %v", err)
+			}
+			if result.Config["service"] != "api" || result.Format != FormatYAML || result.Reference != "v1.2.0" {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+			if assetAuth != "" {
+				t.Errorf("SECURITY: Credentials sent to the storage host: %q", assetAuth)
+			}
+		})
+	}
+
+	t.Run("Missing release", func(t *testing.T) {
+		_, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#app.yaml?release=v9.9.9&auth=token:ghp_test")
+		if !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+			t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND, got %v", err)
+		}
+	})
+
+	t.Run("Missing asset", func(t *testing.T) {
+		_, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#db.yaml?release=latest&auth=token:ghp_test")
+		if !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") || !strings.Contains(errors.RootCause(err).Error(), "db.yaml") {
+			t.Errorf("Expected ARGUS_CONFIG_NOT_FOUND naming the asset, got %v", err)
+		}
+	})
+
+	t.Run("Bad credentials", func(t *testing.T) {
+		_, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#app.yaml?release=latest&auth=token:wrong")
+		if !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Errorf("Expected ARGUS_AUTH_ERROR, got %v", err)
+		}
+	})
+
+	t.Run("Change detection", func(t *testing.T) {
+		gitURL, err := provider.parseGitURL("https://github.com/org/configs.git#app.yaml?release=latest&auth=token:ghp_test")
+		if err != nil {
+			t.Fatalf("parseGitURL failed: %v", err)
+		}
+		if !provider.hasReleaseAssetChanged(ctx, gitURL) {
+			t.Error("Expected the first poll to report a change")
+		}
+		if provider.hasReleaseAssetChanged(ctx, gitURL) {
+			t.Error("Expected an unchanged asset not to report a change")
+		}
+	})
+}
+
+// TestGitProvider_GitLabReleaseAsset tests loading an asset link of a GitLab release
+func TestGitProvider_GitLabReleaseAsset(t *testing.T) {
+	provider := newReleaseTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.EscapedPath() {
+		case "gitlab.com/api/v4/projects/group%2Fsub%2Fconfigs/releases/v2.0.0":
+			if r.Header.Get("PRIVATE-TOKEN") != "glpat_test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writeReleaseJSON(w, map[string]interface{}{
+				"tag_name": "v2.0.0",
+				"assets": map[string]interface{}{
+					"links": []map[string]interface{}{
+						{"id": 1, "name": "app.json", "url": "https://gitlab.com/group/sub/configs/-/releases/v2.0.0/downloads/app.json"},
+						{"id": 2, "name": "private.json", "url": "https://10.0.0.5/private.json"},
+					},
+				},
+			})
+		case "gitlab.com/group/sub/configs/-/releases/v2.0.0/downloads/app.json":
+			_, _ = w.Write([]byte(`{"service": "worker"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := provider.LoadDetailed(ctx, "https://gitlab.com/group/sub/configs.git#app.json?release=v2.0.0&auth=token:glpat_test")
+	if err != nil {
+		t.Fatalf("LoadDetailed failed: %v", err)
+	}
+	if result.Config["service"] != "worker" || result.Reference != "v2.0.0" || result.CommitHash != "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	t.Run("Private asset link", func(t *testing.T) {
+		_, err := provider.LoadDetailed(ctx, "https://gitlab.com/group/sub/configs.git#private.json?release=v2.0.0&auth=token:glpat_test")
+		if !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+			t.Errorf("Expected ARGUS_SECURITY_ERROR, got %v", err)
+		}
+	})
+}
+
+// TestReleaseAPIFor tests mapping repository URLs to releases APIs
+func TestReleaseAPIFor(t *testing.T) {
+	testCases := []struct {
+		repoURL    string
+		releaseURL string
+	}{
+		{"https://github.com/org/configs.git", "https://api.github.com/repos/org/configs/releases/latest"},
+		{"ssh://git@github.com/org/configs.git", "https://api.github.com/repos/org/configs/releases/latest"},
+		{"https://github.example.com/org/configs.git", "https://github.example.com/api/v3/repos/org/configs/releases/latest"},
+		{"https://gitlab.com/group/configs.git", "https://gitlab.com/api/v4/projects/group%2Fconfigs/releases/permalink/latest"},
+		{"https://gitlab.example.com/group/configs.git?token=x", "https://gitlab.example.com/api/v4/projects/group%2Fconfigs/releases/permalink/latest"},
+	}
+
+	for _, tc := range testCases {
+		api, err := releaseAPIFor(tc.repoURL)
+		if err != nil {
+			t.Fatalf("releaseAPIFor(%q) failed: %v", tc.repoURL, err)
+		}
+		if got := api.releaseURL(latestRelease); got != tc.releaseURL {
+			t.Errorf("releaseAPIFor(%q) = %s, want %s", tc.repoURL, got, tc.releaseURL)
+		}
+	}
+
+	provider := GetProvider().(*GitProvider)
+	for _, unsupported := range []string{
+		"https://bitbucket.org/org/configs.git#app.json?release=latest",
+		"https://git.example.com/configs.git#app.json?release=v1.0.0",
+	} {
+		if err := provider.Validate(unsupported); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") || !strings.Contains(err.Error(), "not supported") {
+			t.Errorf("Expected an unsupported host error for %s, got %v", unsupported, err)
+		}
+	}
+}
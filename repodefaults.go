@@ -0,0 +1,138 @@
+// repodefaults.go: Per-repository URL defaults
+//
+// Services loading many files from the same repositories otherwise repeat the
+// same reference, polling and authentication parameters in every URL.
+// RegisterRepoDefaults records them once per repository, and URLs of that
+// repository inherit whatever they do not set themselves:
+//
+//	err := provider.RegisterRepoDefaults("https://github.com/org/configs.git", git.RepoDefaults{
+//	    Ref:      "production",
+//	    Poll:     time.Minute,
+//	    AuthType: "token",
+//	    TokenEnv: "GH_TOKEN",
+//	})
+//	config, err := provider.Load(ctx, "https://github.com/org/configs.git#app.json")
+//
+// Parameters in the URL always take precedence. Tokens are read from the named
+// environment variable whenever a URL is parsed, so secrets stay out of URLs and
+// rotated values are picked up without re-registering.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// RepoDefaults are the URL parameters a repository's URLs inherit when they do not set them
+type RepoDefaults struct {
+	Ref      string        // Reference loaded when the URL names none (default "main")
+	Poll     time.Duration // Watch polling interval when the URL sets none
+	AuthType string        // Authentication when the URL has none: "token", "bearer", "gitea" or "ssh"
+	TokenEnv string        // Environment variable holding the token of token, bearer and gitea authentication
+	KeyPath  string        // SSH private key of ssh authentication
+}
+
+// RegisterRepoDefaults registers the defaults of a repository, replacing any registered
+// before. repoURL accepts the same base URLs as configuration URLs; a file path or
+// parameters in it are ignored. Invalid defaults fail with ARGUS_INVALID_CONFIG.
+func (g *GitProvider) RegisterRepoDefaults(repoURL string, defaults RepoDefaults) error {
+	gitURL, err := g.parseGitURLWith(repoURL, false)
+	if err != nil {
+		return err
+	}
+
+	if err := defaults.validate(strings.HasPrefix(gitURL.RepoURL, "file://")); err != nil {
+		return err
+	}
+	if defaults.AuthType != "" && isGitProtocolURL(gitURL.RepoURL) {
+		return errors.New("ARGUS_INVALID_CONFIG", "authentication is not supported for git:// repositories")
+	}
+
+	g.repoDefaultsMutex.Lock()
+	defer g.repoDefaultsMutex.Unlock()
+	if g.repoDefaults == nil {
+		g.repoDefaults = make(map[string]RepoDefaults)
+	}
+	g.repoDefaults[gitURL.RepoURL] = defaults
+	return nil
+}
+
+// validate checks the defaults as the equivalent URL parameters are checked
+func (d RepoDefaults) validate(local bool) error {
+	if len(d.Ref) > maxRefLength {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("default reference too long: %d bytes (max %d)", len(d.Ref), maxRefLength))
+	}
+	if strings.HasPrefix(d.Ref, "refs/") {
+		if _, ok := qualifiedRefName(d.Ref); !ok {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("invalid default reference name: %q", d.Ref))
+		}
+	}
+
+	if d.Poll != 0 && (d.Poll < minPollInterval || d.Poll > maxPollInterval) {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("default poll interval %s out of range (%s to %s)", d.Poll, minPollInterval, maxPollInterval))
+	}
+
+	if d.AuthType != "" && local {
+		return errors.New("ARGUS_INVALID_CONFIG", "authentication is not supported for local repositories")
+	}
+	switch d.AuthType {
+	case "":
+		if d.TokenEnv != "" || d.KeyPath != "" {
+			return errors.New("ARGUS_INVALID_CONFIG", "default credentials require an AuthType")
+		}
+	case "token", "bearer", "gitea":
+		if d.TokenEnv == "" || strings.ContainsAny(d.TokenEnv, "= \x00") {
+			return errors.New("ARGUS_INVALID_CONFIG",
+				fmt.Sprintf("default auth type %s requires a valid TokenEnv, got %q", d.AuthType, d.TokenEnv))
+		}
+	case "ssh", "key":
+		if d.KeyPath == "" {
+			return errors.New("ARGUS_INVALID_CONFIG", fmt.Sprintf("default auth type %s requires KeyPath", d.AuthType))
+		}
+		if err := validateSecretFile(d.KeyPath, "SSH key file"); err != nil {
+			return err
+		}
+	default:
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("unsupported default authentication type: %s (use token, bearer, gitea or ssh)", d.AuthType))
+	}
+
+	return nil
+}
+
+// repoDefaultsFor returns the defaults registered for a repository URL
+func (g *GitProvider) repoDefaultsFor(repoURL string) RepoDefaults {
+	g.repoDefaultsMutex.RLock()
+	defer g.repoDefaultsMutex.RUnlock()
+	return g.repoDefaults[repoURL]
+}
+
+// applyDefaultAuth sets the registered default authentication of a URL without any
+func applyDefaultAuth(gitURL *GitURL, defaults RepoDefaults) error {
+	switch defaults.AuthType {
+	case "":
+		return nil
+	case "ssh", "key":
+		gitURL.AuthData["keypath"] = defaults.KeyPath
+	default:
+		token, ok := os.LookupEnv(defaults.TokenEnv)
+		if !ok || token == "" {
+			return errors.New("ARGUS_AUTH_ERROR",
+				fmt.Sprintf("token environment variable %s of the repository defaults is not set", defaults.TokenEnv))
+		}
+		gitURL.AuthData["token"] = token
+	}
+	gitURL.AuthType = defaults.AuthType
+	return nil
+}
@@ -0,0 +1,129 @@
+// repodefaults_test.go
+//
+// Per-repository URL default tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestGitProvider_RegisterRepoDefaults tests that bare URLs inherit registered defaults
+func TestGitProvider_RegisterRepoDefaults(t *testing.T) {
+	t.Setenv("ARGUS_TEST_GH_TOKEN", "ghp_from_env")
+
+	provider := GetProvider().(*GitProvider)
+	err := provider.RegisterRepoDefaults("https://github.com/org/configs.git", RepoDefaults{
+		Ref:      "production",
+		Poll:     time.Minute,
+		AuthType: "token",
+		TokenEnv: "ARGUS_TEST_GH_TOKEN",
+	})
+	if err != nil {
+		t.Fatalf("RegisterRepoDefaults failed: %v", err)
+	}
+
+	t.Run("Bare URL inherits defaults", func(t *testing.T) {
+		for _, configURL := range []string{
+			"https://github.com/org/configs.git#app.json",
+			"https://github.com/org/configs#app.json", // Normalized to the registered URL
+		} {
+			gitURL, err := provider.parseGitURL(configURL)
+			if err != nil {
+				t.Fatalf("parseGitURL(%s) failed: %v", configURL, err)
+			}
+			if gitURL.Reference != "production" || gitURL.PollInterval != time.Minute {
+				t.Errorf("Expected the default ref and poll for %s, got %s and %s", configURL, gitURL.Reference, gitURL.PollInterval)
+			}
+			if gitURL.AuthType != "token" || gitURL.AuthData["token"] != "ghp_from_env" {
+				t.Errorf("Expected the default token for %s, got %s %v", configURL, gitURL.AuthType, gitURL.AuthData)
+			}
+		}
+	})
+
+	t.Run("URL parameters override defaults", func(t *testing.T) {
+		gitURL, err := provider.parseGitURL("https://github.com/org/configs.git#app.json?ref=staging&poll=30s&auth=bearer:oauth_token")
+		if err != nil {
+			t.Fatalf("parseGitURL failed: %v", err)
+		}
+		if gitURL.Reference != "staging" || gitURL.PollInterval != 30*time.Second {
+			t.Errorf("Expected the URL ref and poll, got %s and %s", gitURL.Reference, gitURL.PollInterval)
+		}
+		if gitURL.AuthType != "bearer" || gitURL.AuthData["token"] != "oauth_token" {
+			t.Errorf("Expected the URL credentials, got %s %v", gitURL.AuthType, gitURL.AuthData)
+		}
+	})
+
+	t.Run("Other repositories are unaffected", func(t *testing.T) {
+		gitURL, err := provider.parseGitURL("https://github.com/org/other.git#app.json")
+		if err != nil {
+			t.Fatalf("parseGitURL failed: %v", err)
+		}
+		if gitURL.Reference != "main" || gitURL.PollInterval != defaultPollInterval || gitURL.AuthType != "" {
+			t.Errorf("Unexpected defaults for an unregistered repository: %+v", gitURL)
+		}
+	})
+
+	t.Run("Unset token variable", func(t *testing.T) {
+		if err := provider.RegisterRepoDefaults("https://github.com/org/unset.git", RepoDefaults{AuthType: "gitea", TokenEnv: "ARGUS_TEST_UNSET_TOKEN"}); err != nil {
+			t.Fatalf("RegisterRepoDefaults failed: %v", err)
+		}
+		if _, err := provider.parseGitURL("https://github.com/org/unset.git#app.json"); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Errorf("Expected ARGUS_AUTH_ERROR for an unset token variable, got %v", err)
+		}
+	})
+
+	t.Run("Invalid defaults", func(t *testing.T) {
+		for name, defaults := range map[string]RepoDefaults{
+			"poll too short":       {Poll: time.Second},
+			"unsupported auth":     {AuthType: "basic"},
+			"token without env":    {AuthType: "token"},
+			"credentials, no type": {TokenEnv: "GH_TOKEN"},
+			"invalid ref":          {Ref: "refs/heads/../main"},
+		} {
+			if err := provider.RegisterRepoDefaults("https://github.com/org/invalid.git", defaults); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+				t.Errorf("Expected ARGUS_INVALID_CONFIG for %s, got %v", name, err)
+			}
+		}
+		if err := provider.RegisterRepoDefaults("file:///etc/passwd", RepoDefaults{}); err == nil {
+			t.Error("Expected repository URLs to be validated")
+		}
+	})
+}
+
+// TestGitProvider_RepoDefaultsLoad tests loading through a bare URL of a repository with defaults
+func TestGitProvider_RepoDefaultsLoad(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"env": "staging"}`})
+	repo.branch("staging")
+	repo.commit("main config", map[string]string{"config.json": `{"env": "main"}`})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if err := provider.RegisterRepoDefaults("file://"+repo.dir, RepoDefaults{Ref: "staging"}); err != nil {
+		t.Fatalf("RegisterRepoDefaults failed: %v", err)
+	}
+	if err := provider.RegisterRepoDefaults("file://"+repo.dir, RepoDefaults{AuthType: "token", TokenEnv: "GH_TOKEN"}); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected authentication defaults to be rejected for local repositories, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := provider.LoadDetailed(ctx, "file://"+repo.dir+"#config.json")
+	if err != nil {
+		t.Fatalf("LoadDetailed failed: %v", err)
+	}
+	if result.Config["env"] != "staging" || result.Reference != "staging" {
+		t.Errorf("Expected the default reference to be loaded, got %v at %s", result.Config, result.Reference)
+	}
+}
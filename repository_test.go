@@ -0,0 +1,257 @@
+// repository_test.go
+//
+// Local Git repository fixtures for tests that must not depend on network access
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// testRepository is a local, non-bare Git repository created in a temporary directory
+type testRepository struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+}
+
+// newTestRepository creates a repository on branch "main" with an initial commit of files
+func newTestRepository(t *testing.T, files map[string]string) *testRepository {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.Main},
+	})
+	if err != nil {
+		t.Fatalf("Failed to initialize test repository: %v", err)
+	}
+
+	r := &testRepository{t: t, dir: dir, repo: repo}
+	r.commit("initial commit", files)
+	return r
+}
+
+// commit writes files (path -> content) and commits them, returning the commit hash
+func (r *testRepository) commit(message string, files map[string]string) string {
+	r.t.Helper()
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	for name, content := range files {
+		fullPath := filepath.Join(r.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o750); err != nil {
+			r.t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o600); err != nil {
+			r.t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			r.t.Fatalf("Failed to stage %s: %v", name, err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author: &object.Signature{
+			Name:  "Argus Test",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		r.t.Fatalf("Failed to commit: %v", err)
+	}
+
+	return hash.String()
+}
+
+// symlink commits a symbolic link at name pointing to target, returning the commit hash
+func (r *testRepository) symlink(message, name, target string) string {
+	r.t.Helper()
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	fullPath := filepath.Join(r.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o750); err != nil {
+		r.t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.Symlink(target, fullPath); err != nil {
+		r.t.Skipf("Symbolic links not supported: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		r.t.Fatalf("Failed to stage %s: %v", name, err)
+	}
+
+	return r.commit(message, nil)
+}
+
+// tag creates a lightweight tag pointing at the current HEAD
+func (r *testRepository) tag(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	if _, err := r.repo.CreateTag(name, head.Hash(), nil); err != nil {
+		r.t.Fatalf("Failed to create tag %s: %v", name, err)
+	}
+}
+
+// annotatedTag creates an annotated tag pointing at the current HEAD
+func (r *testRepository) annotatedTag(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	_, err = r.repo.CreateTag(name, head.Hash(), &git.CreateTagOptions{
+		Message: "Release " + name,
+		Tagger: &object.Signature{
+			Name:  "Argus Test",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		r.t.Fatalf("Failed to create tag %s: %v", name, err)
+	}
+}
+
+// gitURL returns a GitURL pointing at the local repository, bypassing URL validation
+func (r *testRepository) gitURL(filePath, reference string) *GitURL {
+	return &GitURL{
+		RepoURL:      r.dir,
+		FilePath:     filePath,
+		Reference:    reference,
+		AuthData:     make(map[string]string),
+		PollInterval: defaultPollInterval,
+	}
+}
+
+// branch creates a branch pointing at the current HEAD
+func (r *testRepository) branch(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), head.Hash())
+	if err := r.repo.Storer.SetReference(ref); err != nil {
+		r.t.Fatalf("Failed to create branch %s: %v", name, err)
+	}
+}
+
+// remove deletes files from the worktree and commits the removal, returning the commit hash
+func (r *testRepository) remove(message string, names ...string) string {
+	r.t.Helper()
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatalf("Failed to get worktree: %v", err)
+	}
+	for _, name := range names {
+		if _, err := worktree.Remove(name); err != nil {
+			r.t.Fatalf("Failed to remove %s: %v", name, err)
+		}
+	}
+
+	return r.commit(message, nil)
+}
+
+// serveHTTP serves the repository over the Git smart HTTP protocol using git http-backend.
+// Its repository URL is the server URL followed by "/.git". The test is skipped when
+// the git binary is not available.
+func (r *testRepository) serveHTTP() *httptest.Server {
+	r.t.Helper()
+
+	server := httptest.NewServer(r.httpBackend())
+	r.t.Cleanup(server.Close)
+	return server
+}
+
+// serveHTTPS is like serveHTTP over TLS, with a certificate issued by the test server's own CA
+func (r *testRepository) serveHTTPS() *httptest.Server {
+	r.t.Helper()
+
+	server := httptest.NewTLSServer(r.httpBackend())
+	r.t.Cleanup(server.Close)
+	return server
+}
+
+// httpBackend returns a git http-backend handler serving the repository
+func (r *testRepository) httpBackend() http.Handler {
+	r.t.Helper()
+
+	gitBinary, err := exec.LookPath("git")
+	if err != nil {
+		r.t.Skip("git binary not available")
+	}
+
+	return &cgi.Handler{
+		Path: gitBinary,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + r.dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+}
+
+// serveGitDaemon serves the repository over the native git:// protocol, running
+// git daemon in inetd mode for every accepted connection. Its repository URL is the
+// returned address followed by "/.git". The test is skipped when the git binary is
+// not available.
+func (r *testRepository) serveGitDaemon() string {
+	r.t.Helper()
+
+	gitBinary, err := exec.LookPath("git")
+	if err != nil {
+		r.t.Skip("git binary not available")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		r.t.Fatalf("Failed to listen: %v", err)
+	}
+	r.t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				cmd := exec.Command(gitBinary, "daemon", "--inetd", "--export-all", "--base-path="+r.dir, r.dir)
+				cmd.Stdin, cmd.Stdout = conn, conn
+				_ = cmd.Run()
+			}()
+		}
+	}()
+
+	return "git://" + listener.Addr().String()
+}
@@ -0,0 +1,64 @@
+// requiredkeys.go: Required configuration keys
+//
+// A full schema is more than many services need to catch the most common mistake,
+// a required field deleted in a commit. WithRequiredKeys lists the dotted key paths
+// a configuration must contain and fails loads of configurations lacking any of them:
+//
+//	git.WithRequiredKeys("database.host", "port")
+//
+// Missing keys are reported together, with ARGUS_SCHEMA_ERROR, so an incomplete
+// configuration is rejected before the service applies it. Watches skip such
+// configurations like those that fail to parse, keeping the last delivered one.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agilira/go-errors"
+)
+
+// checkRequiredKeys verifies that the configuration contains every required key
+func (g *GitProvider) checkRequiredKeys(config map[string]interface{}) error {
+	var missing []string
+	for _, key := range g.options.requiredKeys {
+		if lookupKeyPath(config, key) == nil {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("ARGUS_SCHEMA_ERROR",
+			fmt.Sprintf("configuration is missing required keys: %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// lookupKeyPath returns the value at a dotted key path, or nil when there is none.
+// Segments select keys of objects and, when numeric, elements of arrays.
+func lookupKeyPath(config map[string]interface{}, keyPath string) interface{} {
+	var value interface{} = config
+	for _, segment := range strings.Split(keyPath, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			value = node[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			value = node[index]
+		default:
+			return nil
+		}
+		if value == nil {
+			return nil
+		}
+	}
+	return value
+}
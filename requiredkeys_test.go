@@ -0,0 +1,89 @@
+// requiredkeys_test.go
+//
+// Required configuration key tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestLookupKeyPath tests resolving dotted key paths in nested configurations
+func TestLookupKeyPath(t *testing.T) {
+	config := map[string]interface{}{
+		"port":     8080,
+		"database": map[string]interface{}{"host": "db.internal", "replica": nil},
+		"servers":  []interface{}{map[string]interface{}{"url": "https://a.internal"}},
+		"enabled":  false,
+	}
+
+	for keyPath, want := range map[string]bool{
+		"port":             true,
+		"database":         true,
+		"database.host":    true,
+		"database.replica": false,
+		"database.port":    false,
+		"servers.0.url":    true,
+		"servers.1.url":    false,
+		"servers.x":        false,
+		"port.number":      false,
+		"enabled":          true,
+		"missing":          false,
+	} {
+		if found := lookupKeyPath(config, keyPath) != nil; found != want {
+			t.Errorf("lookupKeyPath(%q) found = %v, want %v", keyPath, found, want)
+		}
+	}
+}
+
+// TestWithRequiredKeys tests that loads fail when required keys are missing
+func TestWithRequiredKeys(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"complete.yaml":   "port: 8080\ndatabase:\n  host: db.internal\n  port: 5432\n",
+		"incomplete.yaml": "port: 8080\ndatabase:\n  port: 5432\n",
+		"empty.json":      `{}`,
+	})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true), WithRequiredKeys("database.host", "port"))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := provider.Load(ctx, "file://"+repo.dir+"#complete.yaml?ref=main")
+	if err != nil {
+		t.Fatalf("Load of the complete configuration failed: %v", err)
+	}
+	if config["port"] != 8080 {
+		t.Errorf("Unexpected config: %v", config)
+	}
+
+	_, err = provider.Load(ctx, "file://"+repo.dir+"#incomplete.yaml?ref=main")
+	if !errors.HasCode(err, "ARGUS_SCHEMA_ERROR") || !strings.Contains(err.Error(), "database.host") {
+		t.Errorf("Expected ARGUS_SCHEMA_ERROR naming database.host, got %v", err)
+	}
+
+	// Every missing key is reported at once
+	_, err = provider.Load(ctx, "file://"+repo.dir+"#empty.json?ref=main")
+	if !errors.HasCode(err, "ARGUS_SCHEMA_ERROR") || !strings.Contains(err.Error(), "database.host, port") {
+		t.Errorf("Expected ARGUS_SCHEMA_ERROR naming both keys, got %v", err)
+	}
+
+	for _, key := range []string{"", ".port", "database.", "database..host"} {
+		if _, err := NewProvider(WithRequiredKeys(key)); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG for required key %q, got %v", key, err)
+		}
+	}
+}
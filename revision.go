@@ -0,0 +1,160 @@
+// revision.go: Relative revision expressions
+//
+// References may name an ancestor of a branch, tag or commit with the suffixes of
+// git rev-parse, e.g. for rollback tooling loading the previous configuration:
+//
+//	ref=main~1        First parent of the tip of main
+//	ref=HEAD~2        Two first-parent steps back from the default branch
+//	ref=v1.4.0^2      Second parent of the (merge) commit tagged v1.4.0
+//	ref=3f2a9c1~1^    Steps combine, and apply to commit hashes too
+//
+// The base is resolved like any reference, so ancestors of a branch move with the
+// branch, while ancestors of a full commit hash are as immutable as the hash. The
+// shallow clone is deepened until the ancestor is present. Other rev-parse syntax,
+// such as ^{/message} searches or @{date} reflog lookups, is not supported.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxRevisionSteps bounds the ancestry steps a revision expression may take in total
+const maxRevisionSteps = 10000
+
+// revisionStep is one step of a revision expression: "~n" follows the first parent
+// n times, "^n" selects the nth parent ("^0" is the commit itself)
+type revisionStep struct {
+	tilde bool
+	n     int
+}
+
+// isRevisionExpression reports whether a reference is a revision expression rather
+// than a name or a tag constraint: a non-empty base followed by "~" or "^" suffixes.
+// Constraints start with their operator or contain characters names cannot.
+func isRevisionExpression(reference string) bool {
+	i := strings.IndexAny(reference, "~^")
+	return i > 0 && !strings.ContainsAny(reference[:i], " *,|<>=")
+}
+
+// parseRevisionExpression splits a revision expression into the reference it starts
+// from and its steps. The HEAD base is returned as the empty reference, which names
+// the default branch.
+func parseRevisionExpression(reference string) (string, []revisionStep, error) {
+	invalid := func(reason string) error {
+		return errors.New("ARGUS_GIT_ERROR",
+			fmt.Sprintf("invalid revision expression %q: %s", reference, reason))
+	}
+
+	i := strings.IndexAny(reference, "~^")
+	if i <= 0 {
+		return "", nil, invalid("missing base reference")
+	}
+	base, suffix := reference[:i], reference[i:]
+	name := base
+	if !strings.HasPrefix(name, "refs/") {
+		name = "refs/heads/" + name
+	}
+	if plumbing.ReferenceName(name).Validate() != nil {
+		return "", nil, invalid(fmt.Sprintf("invalid base reference %q", base))
+	}
+
+	var steps []revisionStep
+	total := 0
+	for suffix != "" {
+		step := revisionStep{tilde: suffix[0] == '~', n: 1}
+		if suffix[0] != '~' && suffix[0] != '^' {
+			return "", nil, invalid(fmt.Sprintf("unexpected %q (expected ~n or ^n)", suffix[0]))
+		}
+		suffix = suffix[1:]
+
+		digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+		if digits > 0 {
+			n, err := strconv.Atoi(suffix[:digits])
+			if err != nil || n > maxRevisionSteps {
+				return "", nil, invalid(fmt.Sprintf("step count above %d", maxRevisionSteps))
+			}
+			step.n = n
+			suffix = suffix[digits:]
+		}
+
+		if step.tilde {
+			total += step.n
+		} else if step.n > 0 {
+			total++
+		}
+		if total > maxRevisionSteps {
+			return "", nil, invalid(fmt.Sprintf("more than %d steps", maxRevisionSteps))
+		}
+		steps = append(steps, step)
+	}
+
+	if base == "HEAD" {
+		base = ""
+	}
+	return base, steps, nil
+}
+
+// revisionBase returns the reference a revision expression starts from, or the
+// reference itself when it is not a revision expression
+func revisionBase(reference string) string {
+	if !isRevisionExpression(reference) {
+		return reference
+	}
+	base, _, err := parseRevisionExpression(reference)
+	if err != nil {
+		return reference
+	}
+	return base
+}
+
+// revisionSuffix returns the steps of a revision expression as written, e.g. "~1"
+// for main~1, or the empty string when the reference is not a revision expression
+func revisionSuffix(reference string) string {
+	if !isRevisionExpression(reference) {
+		return ""
+	}
+	return reference[strings.IndexAny(reference, "~^"):]
+}
+
+// revisionBaseURL returns the URL at the base of its revision expression, which is
+// the reference that is looked up remotely and cloned
+func revisionBaseURL(gitURL *GitURL) *GitURL {
+	if !isRevisionExpression(gitURL.Reference) {
+		return gitURL
+	}
+	base := *gitURL
+	base.Reference = revisionBase(gitURL.Reference)
+	return &base
+}
+
+// walkRevision applies the steps of a revision expression to a commit. It fails when
+// an ancestor does not exist or is missing from a shallow clone.
+func walkRevision(commit *object.Commit, steps []revisionStep) (*object.Commit, error) {
+	var err error
+	for _, step := range steps {
+		switch {
+		case step.tilde:
+			for i := 0; i < step.n; i++ {
+				if commit, err = commit.Parent(0); err != nil {
+					return nil, err
+				}
+			}
+		case step.n > 0:
+			if commit, err = commit.Parent(step.n - 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return commit, nil
+}
@@ -0,0 +1,131 @@
+// revision_test.go
+//
+// Relative revision expression tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestParseRevisionExpression tests the recognition and parsing of revision expressions
+func TestParseRevisionExpression(t *testing.T) {
+	valid := []struct {
+		reference string
+		base      string
+		steps     int
+	}{
+		{"main~1", "main", 1},
+		{"HEAD~2", "", 1},
+		{"v1.4.0^2", "v1.4.0", 1},
+		{"3f2a9c1~1^", "3f2a9c1", 2},
+		{"refs/heads/release~3^0", "refs/heads/release", 2},
+		{"feature/login~", "feature/login", 1},
+	}
+	for _, tc := range valid {
+		if !isRevisionExpression(tc.reference) {
+			t.Errorf("Expected %q to be a revision expression", tc.reference)
+			continue
+		}
+		base, steps, err := parseRevisionExpression(tc.reference)
+		if err != nil || base != tc.base || len(steps) != tc.steps {
+			t.Errorf("parseRevisionExpression(%q) = %q, %v, %v", tc.reference, base, steps, err)
+		}
+	}
+
+	// Tag constraints and plain names are not revision expressions
+	for _, reference := range []string{"~1.4", "^1.2.0", ">=1.2 <2~1", "main", "v1.4.0", ""} {
+		if isRevisionExpression(reference) {
+			t.Errorf("Expected %q not to be a revision expression", reference)
+		}
+	}
+
+	for _, reference := range []string{"main~x", "main^{tree}", "main@{1}~1", "main~99999"} {
+		if _, err := newNoRetryProvider().parseGitURL("https://github.com/org/configs.git#config.json?ref=" + reference); !errors.HasCode(err, "ARGUS_GIT_ERROR") {
+			t.Errorf("Expected ARGUS_GIT_ERROR for %q, got %v", reference, err)
+		}
+	}
+
+	gitURL, err := newNoRetryProvider().parseGitURL("https://github.com/org/configs.git#config.json?ref=main~1")
+	if err != nil {
+		t.Fatalf("ParseGitURL failed: %v", err)
+	}
+	if gitURL.Reference != "main~1" || gitURL.TagConstraint != "" {
+		t.Errorf("Expected main~1 as the reference, got %q (constraint %q)", gitURL.Reference, gitURL.TagConstraint)
+	}
+}
+
+// TestGitProvider_RevisionExpressions tests loading ancestors of branches, tags and commits
+func TestGitProvider_RevisionExpressions(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+	for version := 2; version <= 12; version++ {
+		repo.commit(fmt.Sprintf("version %d", version),
+			map[string]string{"config.json": fmt.Sprintf(`{"version": %d}`, version)})
+		if version == 8 {
+			repo.tag("v8")
+		}
+	}
+
+	// Over HTTP the shallow clone has to be deepened to reach older ancestors
+	server := repo.serveHTTP()
+	provider := newNoRetryProvider()
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		reference string
+		version   float64
+	}{
+		{"main~1", 11},
+		{"HEAD~2", 10},
+		{"main~10", 2},
+		{"main~1^~1", 9},
+		{"v8~3", 5},
+		{"main^0", 12},
+	}
+	for _, tc := range testCases {
+		gitURL := &GitURL{RepoURL: server.URL + "/.git", FilePath: "config.json", Reference: tc.reference}
+		result, err := provider.loadConfigFromRepo(ctx, gitURL)
+		if err != nil {
+			t.Fatalf("Load of %s failed: %v", tc.reference, err)
+		}
+		if result.Config["version"] != tc.version || result.Reference != tc.reference {
+			t.Errorf("Expected version %v at %s, got %v (reference %s)", tc.version, tc.reference, result.Config, result.Reference)
+		}
+
+		// Cached under the base commit, the second load is a cache hit
+		if cached, err := provider.loadConfigFromRepo(ctx, gitURL); err != nil || !cached.cacheHit || cached.Config["version"] != tc.version || cached.CommitHash != result.CommitHash {
+			t.Errorf("Expected a cache hit for %s, got %v (err=%v)", tc.reference, cached, err)
+		}
+	}
+
+	// Relative to a full commit hash, the result is immutable
+	tip := repo.commit("version 13", map[string]string{"config.json": `{"version": 13}`})
+	result, err := provider.loadConfigFromRepo(ctx, &GitURL{RepoURL: server.URL + "/.git", FilePath: "config.json", Reference: tip + "~3"})
+	if err != nil || result.Config["version"] != float64(10) {
+		t.Errorf("Expected version 10 three commits before %s, got %v (err=%v)", tip, result, err)
+	}
+
+	// The branch moved, so its ancestors moved with it
+	result, err = provider.loadConfigFromRepo(ctx, &GitURL{RepoURL: server.URL + "/.git", FilePath: "config.json", Reference: "main~1"})
+	if err != nil || result.Config["version"] != float64(12) {
+		t.Errorf("Expected version 12 one commit before the new tip, got %v (err=%v)", result, err)
+	}
+
+	// Ancestors beyond the root commit do not exist
+	_, err = provider.loadConfigFromRepo(ctx, &GitURL{RepoURL: server.URL + "/.git", FilePath: "config.json", Reference: "main~20"})
+	if !errors.HasCode(err, "ARGUS_GIT_ERROR") {
+		t.Errorf("Expected ARGUS_GIT_ERROR beyond the root commit, got %v", err)
+	}
+}
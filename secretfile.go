@@ -0,0 +1,27 @@
+// secretfile.go: Access checks of files holding secrets
+//
+// SSH keys and passphrase files must only be accessible to the user running the
+// provider. How that is verified depends on the platform: Unix permission bits
+// (secretfile_unix.go) or Windows access control lists (secretfile_windows.go).
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"os"
+
+	"github.com/agilira/go-errors"
+)
+
+// validateSecretFile ensures a file holding a secret exists and is accessible to the
+// current user only; kind names the file in errors, e.g. "SSH key file"
+func validateSecretFile(path, kind string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.New("ARGUS_AUTH_ERROR", kind+" not accessible")
+	}
+	return checkSecretFileAccess(path, info, kind)
+}
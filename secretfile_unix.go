@@ -0,0 +1,23 @@
+// secretfile_unix.go: Unix permission checks of files holding secrets
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package git
+
+import (
+	"os"
+
+	"github.com/agilira/go-errors"
+)
+
+// checkSecretFileAccess rejects secret files with permissions beyond 0600, as OpenSSH does
+func checkSecretFileAccess(_ string, info os.FileInfo, kind string) error {
+	if info.Mode().Perm() > 0o600 {
+		return errors.New("ARGUS_SECURITY_ERROR", kind+" permissions too open (should be 0600 or less)")
+	}
+	return nil
+}
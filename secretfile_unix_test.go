@@ -0,0 +1,47 @@
+// secretfile_unix_test.go
+//
+// Unix permission checks of secret files for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestValidateSecretFile tests the 0600 rule for secret files
+func TestValidateSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, []byte("key"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	for _, mode := range []os.FileMode{0o600, 0o400} {
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("Chmod failed: %v", err)
+		}
+		if err := validateSecretFile(path, "SSH key file"); err != nil {
+			t.Errorf("Expected mode %o to be accepted, got %v", mode, err)
+		}
+	}
+
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	if err := validateSecretFile(path, "SSH key file"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR for mode 640, got %v", err)
+	}
+
+	if err := validateSecretFile(filepath.Join(t.TempDir(), "missing"), "SSH key file"); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+		t.Errorf("Expected ARGUS_AUTH_ERROR for a missing file, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+// secretfile_windows.go: Windows ACL checks of files holding secrets
+//
+// Unix permission bits do not apply on Windows, where os.FileInfo reports 0666 for
+// every writable file. Instead the file's access control list is inspected, as
+// OpenSSH for Windows does: only the current user, SYSTEM and the Administrators
+// group may be granted access to it.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package git
+
+import (
+	"os"
+	"unsafe"
+
+	"github.com/agilira/go-errors"
+	"golang.org/x/sys/windows"
+)
+
+// secretAccessMask holds the access rights that expose a secret file's content:
+// reading, writing or appending data, their generic equivalents, and changing the ACL
+// or owner (which would allow granting them)
+const secretAccessMask = 0x1 | 0x2 | 0x4 | // FILE_READ_DATA, FILE_WRITE_DATA, FILE_APPEND_DATA
+	windows.GENERIC_READ | windows.GENERIC_WRITE | windows.GENERIC_ALL | windows.WRITE_DAC | windows.WRITE_OWNER
+
+// checkSecretFileAccess rejects secret files whose ACL grants access to anyone but
+// the current user, SYSTEM and Administrators
+func checkSecretFileAccess(path string, _ os.FileInfo, kind string) error {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_AUTH_ERROR", kind+" access control list not readable")
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil || dacl == nil {
+		// A missing DACL grants everyone full access
+		return errors.New("ARGUS_SECURITY_ERROR", kind+" is accessible to all users (restrict its ACL to the current user)")
+	}
+
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return errors.Wrap(err, "ARGUS_AUTH_ERROR", "failed to identify the current user")
+	}
+	trusted := []*windows.SID{tokenUser.User.Sid}
+	for _, wellKnown := range []windows.WELL_KNOWN_SID_TYPE{windows.WinLocalSystemSid, windows.WinBuiltinAdministratorsSid} {
+		if sid, err := windows.CreateWellKnownSid(wellKnown); err == nil {
+			trusted = append(trusted, sid)
+		}
+	}
+
+	for i := uint32(0); i < uint32(dacl.AceCount); i++ {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return errors.Wrap(err, "ARGUS_AUTH_ERROR", kind+" access control list not readable")
+		}
+		if ace.Header.AceType != windows.ACCESS_ALLOWED_ACE_TYPE || ace.Mask&secretAccessMask == 0 {
+			continue
+		}
+
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+		isTrusted := false
+		for _, trustedSID := range trusted {
+			if sid.Equals(trustedSID) {
+				isTrusted = true
+				break
+			}
+		}
+		if !isTrusted {
+			return errors.New("ARGUS_SECURITY_ERROR",
+				kind+" is accessible to other users (restrict its ACL to the current user)")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+// secretfile_windows_test.go
+//
+// Windows ACL checks of secret files for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestValidateSecretFile tests that secret files shared with other users are rejected
+func TestValidateSecretFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, []byte("key"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	// Reduce the ACL to the current user, as ssh-keygen does
+	// #nosec G204 - Fixed command on a test file
+	if out, err := exec.Command("icacls", path, "/inheritance:r", "/grant:r", os.Getenv("USERNAME")+":F").CombinedOutput(); err != nil {
+		t.Skipf("icacls not usable: %v: %s", err, out)
+	}
+	if err := validateSecretFile(path, "SSH key file"); err != nil {
+		t.Errorf("Expected a key restricted to the current user to be accepted, got %v", err)
+	}
+
+	// #nosec G204 - Fixed command on a test file
+	if out, err := exec.Command("icacls", path, "/grant", "*S-1-1-0:R").CombinedOutput(); err != nil {
+		t.Fatalf("icacls failed: %v: %s", err, out)
+	}
+	if err := validateSecretFile(path, "SSH key file"); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+		t.Errorf("Expected ARGUS_SECURITY_ERROR for a key readable by Everyone, got %v", err)
+	}
+
+	if err := validateSecretFile(filepath.Join(dir, "missing"), "SSH key file"); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+		t.Errorf("Expected ARGUS_AUTH_ERROR for a missing file, got %v", err)
+	}
+}
@@ -480,6 +480,57 @@ func TestPathTraversal_DirectoryTraversal(t *testing.T) {
 	}
 }
 
+// TestPathTraversal_MixedEncoding validates canonicalization of multiply-encoded paths.
+//
+// ATTACK SCENARIO: Attacker layers percent-encoding (%252e), mixes case (%2E%2e), or
+// encodes backslashes (%5c) so a single decoding pass misses the traversal sequence.
+//
+// SECURITY CONTROL: Validators fully decode (bounded) and normalize separators before
+// checking that the cleaned path stays within the repository root.
+func TestPathTraversal_MixedEncoding(t *testing.T) {
+	_ = NewSecurityTestContext(t)
+
+	filePaths := []string{
+		"%252e%252e%252fapp.json",
+		"%25252e%25252e%25252fapp.json",
+		"conf%5c..%5c..%5capp.json",
+		"%2E%2e%2Fapp.json",
+		"conf/%zz/%2e%2e/%2e%2e/%2e%2e/app.json",
+		"%2fetc%2fapp.json",
+		"%5c%5cserver%5cshare%5capp.json",
+	}
+
+	for _, filePath := range filePaths {
+		t.Run("File_"+url.QueryEscape(filePath), func(t *testing.T) {
+			err := validateConfigFilePath(filePath)
+			if err == nil {
+				t.Fatalf("Expected encoded traversal to be blocked: %s", filePath)
+			}
+			if !strings.Contains(err.Error(), "ARGUS_SECURITY_ERROR") {
+				t.Errorf("Expected ARGUS_SECURITY_ERROR for %s, got: %v", filePath, err)
+			}
+		})
+	}
+
+	repoPaths := []string{
+		"/user/%252e%252e/repo.git",
+		"/user/%2e%2E%5c..%5crepo.git",
+		"/%2525252e%2525252e/repo.git",
+	}
+
+	for _, repoPath := range repoPaths {
+		t.Run("Repo_"+url.QueryEscape(repoPath), func(t *testing.T) {
+			if err := validateRepositoryPath(repoPath); err == nil {
+				t.Errorf("Expected encoded traversal to be blocked: %s", repoPath)
+			}
+		})
+	}
+
+	if _, err := decodePathFully("conf%2Fv1%2Fapp.json"); err != nil {
+		t.Errorf("Single-layer encoding should decode cleanly: %v", err)
+	}
+}
+
 // TestPathTraversal_AbsolutePaths validates protection against absolute path access.
 //
 // ATTACK SCENARIO: Attacker provides absolute file paths to access sensitive system
@@ -512,6 +563,78 @@ func TestPathTraversal_AbsolutePaths(t *testing.T) {
 	}
 }
 
+// TestPathTraversal_SensitivePaths validates segment matching of sensitive paths.
+//
+// ATTACK SCENARIO: Attacker points the file fragment at repository metadata,
+// credential directories or key files, possibly hiding the name behind encoding.
+//
+// SECURITY CONTROL: Provider should reject paths with a sensitive directory or file
+// name segment, while allowing benign dot-directories and dotfiles.
+func TestPathTraversal_SensitivePaths(t *testing.T) {
+	_ = NewSecurityTestContext(t)
+
+	for _, filePath := range []string{
+		".config/app.yaml",
+		"conf/.defaults.json",
+		".github/settings.yml",
+		"legacy.git/app.json",
+		".environment/app.json",
+		"conf/.sshrc.json",
+	} {
+		if err := validateConfigFilePath(filePath); err != nil {
+			t.Errorf("Expected %s to be allowed, got: %v", filePath, err)
+		}
+	}
+
+	for _, filePath := range []string{
+		".git/config.json",
+		"conf/.GIT/hooks.yaml",
+		"%2egit/config.json",
+		".ssh/config.json",
+		"home/.aws/credentials.toml",
+		".env.json",
+		"deploy/.env.yaml.gz",
+		"secrets/app.yaml",
+		"config.token.json",
+		"keys/id_rsa.json",
+	} {
+		err := validateConfigFilePath(filePath)
+		if err == nil || !strings.Contains(err.Error(), "ARGUS_SECURITY_ERROR") {
+			t.Errorf("Expected ARGUS_SECURITY_ERROR for %s, got: %v", filePath, err)
+		}
+	}
+
+	t.Run("Custom sensitive paths", func(t *testing.T) {
+		provider, err := NewProvider(WithSensitivePaths(append(DefaultSensitivePaths(), ".kube", "KubeConfig*")...))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		for _, filePath := range []string{".kube/app.yaml", "kubeconfig-prod.yaml", ".ssh/config.json", ".git/config.json"} {
+			if err := provider.Validate("https://github.com/org/repo.git#" + filePath); err == nil || !strings.Contains(err.Error(), "ARGUS_SECURITY_ERROR") {
+				t.Errorf("Expected ARGUS_SECURITY_ERROR for %s, got: %v", filePath, err)
+			}
+		}
+
+		// Replacing the defaults allows what they blocked, except repository metadata
+		provider, err = NewProvider(WithSensitivePaths(".kube"))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		if err := provider.Validate("https://github.com/org/repo.git#secrets/app.yaml"); err != nil {
+			t.Errorf("Expected secrets/app.yaml to be allowed, got: %v", err)
+		}
+		if err := provider.Validate("https://github.com/org/repo.git#.git/config.json"); err == nil {
+			t.Error("Expected .git/config.json to stay blocked")
+		}
+
+		for _, patterns := range [][]string{{}, {""}, {"conf/.env"}, {"[a-"}} {
+			if _, err := NewProvider(WithSensitivePaths(patterns...)); err == nil || !strings.Contains(err.Error(), "ARGUS_INVALID_CONFIG") {
+				t.Errorf("Expected ARGUS_INVALID_CONFIG for patterns %q, got: %v", patterns, err)
+			}
+		}
+	})
+}
+
 // =============================================================================
 // SSH KEY SECURITY TESTS
 // =============================================================================
@@ -746,6 +869,91 @@ func TestResourceExhaustion_SlowResponse(t *testing.T) {
 	}
 }
 
+// TestResourceExhaustion_QueryParameters validates bounds on URL query parameters.
+//
+// ATTACK SCENARIO: Attacker packs thousands of tiny parameters or a giant value into
+// the URL fragment, which maxURLLength does not cover, to stress query parsing.
+//
+// SECURITY CONTROL: Parameter counts and lengths are bounded before parsing.
+func TestResourceExhaustion_QueryParameters(t *testing.T) {
+	provider := GetProvider().(*GitProvider)
+	base := "https://github.com/user/repo.git#config.json?"
+
+	testCases := map[string]string{
+		"10k tiny fragment params":  base + strings.Repeat("a=1&", 10000) + "ref=main",
+		"10k empty fragment params": base + strings.Repeat("&", 10000),
+		"Params split across base and fragment": "https://github.com/user/repo.git?" +
+			strings.Repeat("x=1&", 40) + "y=1#config.json?" + strings.Repeat("a=1&", 30) + "ref=main",
+		"Giant ref":          base + "ref=" + strings.Repeat("r", maxRefLength+1),
+		"Giant fallback ref": base + "fallback_ref=main," + strings.Repeat("r", maxRefLength+1),
+		"Giant auth":         base + "auth=token:" + strings.Repeat("t", maxQueryParamLength),
+		"Giant file":         base + "file=" + strings.Repeat("f", maxQueryParamLength) + ".json",
+	}
+
+	for name, configURL := range testCases {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			_, err := provider.parseGitURL(configURL)
+			if err == nil || !strings.Contains(err.Error(), "ARGUS_INVALID_CONFIG") {
+				t.Errorf("Expected ARGUS_INVALID_CONFIG, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Errorf("Rejection took too long: %v", elapsed)
+			}
+			if err != nil && strings.Contains(err.Error(), strings.Repeat("t", 64)) {
+				t.Error("SECURITY: Oversized parameter value echoed in the error")
+			}
+		})
+	}
+
+	// URLs within the bounds still parse
+	gitURL, err := provider.parseGitURL(base + strings.Repeat("x=1&", maxQueryParams-2) + "ref=" + strings.Repeat("r", maxRefLength))
+	if err != nil || len(gitURL.Reference) != maxRefLength {
+		t.Errorf("Expected a URL at the limits to parse, got %v", err)
+	}
+}
+
+// TestResourceExhaustion_RepositoryPathAndRefLength validates length bounds on repository
+// paths and references.
+//
+// ATTACK SCENARIO: Attacker passes pathological repository paths or reference names,
+// hoping to stress path decoding, constraint parsing or go-git itself.
+//
+// SECURITY CONTROL: Both are bounded with ARGUS_INVALID_CONFIG before any parsing.
+func TestResourceExhaustion_RepositoryPathAndRefLength(t *testing.T) {
+	provider := GetProvider().(*GitProvider)
+	longPath := "/org/" + strings.Repeat("r", maxRepoPathLength) + ".git"
+	longRef := strings.Repeat("r", maxRefLength+1)
+
+	testCases := map[string]string{
+		"Long repository path":     "https://github.com" + longPath + "#config.json",
+		"Long SSH repository path": "ssh://git@github.com" + longPath + "#config.json",
+		"Long mirror path":         "https://github.com/org/configs.git#config.json?mirror_url=" + url.QueryEscape("https://gitlab.com"+longPath),
+		"Long branch":              "https://github.com/org/configs.git#config.json?branch=" + longRef,
+		"Long base URL ref":        "https://github.com/org/configs.git?ref=" + longRef + "#config.json",
+		"Long tag constraint":      "https://github.com/org/configs.git#config.json?tag_constraint=" + url.QueryEscape(">=1.0.0 "+strings.Repeat("|| >=1.0.0 ", maxRefLength/11+1)),
+		"Long revision expression": "https://github.com/org/configs.git#config.json?ref=main" + strings.Repeat("~", maxRefLength),
+	}
+
+	for name, configURL := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := provider.parseGitURL(configURL); err == nil || !strings.Contains(err.Error(), "ARGUS_INVALID_CONFIG") {
+				t.Errorf("Expected ARGUS_INVALID_CONFIG, got %v", err)
+			}
+		})
+	}
+
+	if err := validateRepositoryPath(longPath); err == nil || !strings.Contains(err.Error(), "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for a long repository path, got %v", err)
+	}
+	if err := validateRepositoryPath("/org/" + strings.Repeat("r", maxRepoPathLength-9) + ".git"); err != nil {
+		t.Errorf("Expected a repository path at the limit to be valid, got %v", err)
+	}
+	if err := provider.RegisterRepoDefaults("https://github.com/org/configs.git", RepoDefaults{Ref: longRef}); err == nil || !strings.Contains(err.Error(), "ARGUS_INVALID_CONFIG") {
+		t.Errorf("Expected ARGUS_INVALID_CONFIG for a long default reference, got %v", err)
+	}
+}
+
 // =============================================================================
 // CONCURRENT ACCESS AND RACE CONDITION TESTS
 // =============================================================================
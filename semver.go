@@ -0,0 +1,474 @@
+// semver.go: Semantic version tag constraints
+//
+// A configuration URL can track releases instead of pinning one, e.g.
+// "tag_constraint=^1.4.0" or "ref=~1.4": the constraint is resolved against the
+// repository's tags on every load, and the highest matching tag is loaded.
+// Constraints follow the npm/Cargo conventions:
+//
+//	^1.4.2            >=1.4.2 <2.0.0 (^0.4.2 is >=0.4.2 <0.5.0)
+//	~1.4.2, ~1.4      >=1.4.2 <1.5.0 (~1 is >=1.0.0 <2.0.0)
+//	1.x, 1.4.*, 1     Any version with the given prefix
+//	>=1.2 <2, =1.4.2  Comparisons, combined with spaces or commas (all must hold)
+//	^1 || ^2          Alternatives
+//
+// Tags may carry a "v" prefix. Pre-release tags such as v2.0.0-rc.1 only match
+// constraints that name a pre-release of the same version.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// maxTagConstraintLength bounds the length of tag constraint expressions
+const maxTagConstraintLength = 256
+
+// semVersion is a parsed semantic version; build metadata is ignored
+type semVersion struct {
+	major, minor, patch uint64
+	prerelease          []string
+}
+
+// semComparator is a single comparison of a constraint, e.g. ">=1.4.0"
+type semComparator struct {
+	op      string // One of "=", ">", ">=", "<", "<="
+	version semVersion
+}
+
+// semConstraint is a parsed constraint: alternatives of comparator sets that must all hold
+type semConstraint [][]semComparator
+
+// isTagConstraintRef reports whether a reference is a tag constraint rather than a
+// name: Git reference names cannot contain "^", "~", "*" or spaces
+func isTagConstraintRef(reference string) bool {
+	return strings.ContainsAny(reference, "^~* ")
+}
+
+// parseSemVersion parses a semantic version with an optional "v" prefix. Like tags,
+// it must have all three version components.
+func parseSemVersion(s string) (semVersion, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	s, _, _ = strings.Cut(s, "+")
+
+	var version semVersion
+	core, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease {
+		if prerelease == "" {
+			return semVersion{}, false
+		}
+		version.prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range version.prerelease {
+			if identifier == "" || strings.Trim(identifier, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-") != "" {
+				return semVersion{}, false
+			}
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semVersion{}, false
+	}
+	numbers := make([]uint64, 3)
+	for i, part := range parts {
+		n, ok := parseVersionNumber(part)
+		if !ok {
+			return semVersion{}, false
+		}
+		numbers[i] = n
+	}
+	version.major, version.minor, version.patch = numbers[0], numbers[1], numbers[2]
+
+	return version, true
+}
+
+// parseVersionNumber parses a version component without sign or leading zeros
+func parseVersionNumber(s string) (uint64, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return n, err == nil
+}
+
+// compare orders versions by precedence: -1, 0 or 1
+func (v semVersion) compare(other semVersion) int {
+	for _, pair := range [][2]uint64{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release has lower precedence than its release
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrereleaseIdentifier(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.prerelease) < len(other.prerelease):
+		return -1
+	case len(v.prerelease) > len(other.prerelease):
+		return 1
+	}
+	return 0
+}
+
+// comparePrereleaseIdentifier orders pre-release identifiers: numeric ones numerically
+// and below alphanumeric ones, which are ordered lexically
+func comparePrereleaseIdentifier(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum == bNum {
+			return 0
+		}
+		if aNum < bNum {
+			return -1
+		}
+		return 1
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// sameRelease reports whether two versions share their major, minor and patch numbers
+func (v semVersion) sameRelease(other semVersion) bool {
+	return v.major == other.major && v.minor == other.minor && v.patch == other.patch
+}
+
+// parseSemConstraint parses a tag constraint expression
+func parseSemConstraint(expression string) (semConstraint, error) {
+	invalid := func(reason string) error {
+		return errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("invalid tag constraint %q: %s", expression, reason))
+	}
+
+	if len(expression) > maxTagConstraintLength {
+		return nil, invalid(fmt.Sprintf("longer than %d bytes", maxTagConstraintLength))
+	}
+
+	var constraint semConstraint
+	for _, alternative := range strings.Split(expression, "||") {
+		terms := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		if len(terms) == 0 {
+			return nil, invalid("empty constraint")
+		}
+
+		var comparators []semComparator
+		for _, term := range terms {
+			termComparators, err := parseSemTerm(term)
+			if err != nil {
+				return nil, invalid(err.Error())
+			}
+			comparators = append(comparators, termComparators...)
+		}
+		constraint = append(constraint, comparators)
+	}
+
+	return constraint, nil
+}
+
+// parseSemTerm parses a single constraint term into the comparators it stands for
+func parseSemTerm(term string) ([]semComparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	version, components, err := parsePartialVersion(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, err
+	}
+
+	// upper returns the first version above the range the given components fix
+	upper := func(fixed int) semVersion {
+		switch fixed {
+		case 1:
+			return semVersion{major: version.major + 1}
+		case 2:
+			return semVersion{major: version.major, minor: version.minor + 1}
+		}
+		return semVersion{major: version.major, minor: version.minor, patch: version.patch + 1}
+	}
+	lower := semComparator{op: ">=", version: version}
+
+	// A bare wildcard matches every version
+	if components == 0 {
+		if op == ">" || op == "<" {
+			return nil, fmt.Errorf("%s matches no version", term)
+		}
+		return []semComparator{lower}, nil
+	}
+
+	switch op {
+	case "^":
+		// The first non-zero component may not change
+		switch {
+		case version.major > 0 || components == 1:
+			return []semComparator{lower, {op: "<", version: upper(1)}}, nil
+		case version.minor > 0 || components == 2:
+			return []semComparator{lower, {op: "<", version: upper(2)}}, nil
+		}
+		return []semComparator{lower, {op: "<", version: upper(3)}}, nil
+	case "~":
+		// Patch updates only, or minor updates when only the major version is given
+		return []semComparator{lower, {op: "<", version: upper(min(components, 2))}}, nil
+	case ">":
+		// ">1.4" is ">=1.5.0"
+		if components < 3 {
+			return []semComparator{{op: ">=", version: upper(components)}}, nil
+		}
+		return []semComparator{{op: op, version: version}}, nil
+	case "<=":
+		// "<=1.4" is "<1.5.0"
+		if components < 3 {
+			return []semComparator{{op: "<", version: upper(components)}}, nil
+		}
+		return []semComparator{{op: op, version: version}}, nil
+	case ">=", "<":
+		return []semComparator{{op: op, version: version}}, nil
+	}
+
+	// Exact versions, and prefixes like "1.4" or "1.x" matching every version they fix
+	if components == 3 {
+		return []semComparator{{op: "=", version: version}}, nil
+	}
+	return []semComparator{lower, {op: "<", version: upper(components)}}, nil
+}
+
+// parsePartialVersion parses a possibly partial version such as "1", "1.4", "1.x" or
+// "1.4.2-rc.1", returning the number of components given before any wildcard
+func parsePartialVersion(s string) (semVersion, int, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if s == "" {
+		return semVersion{}, 0, fmt.Errorf("missing version")
+	}
+
+	core, prerelease, hasPrerelease := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return semVersion{}, 0, fmt.Errorf("malformed version %q", s)
+	}
+
+	numbers := make([]uint64, 3)
+	components := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		if components != i {
+			return semVersion{}, 0, fmt.Errorf("malformed version %q", s)
+		}
+		n, ok := parseVersionNumber(part)
+		if !ok {
+			return semVersion{}, 0, fmt.Errorf("malformed version %q", s)
+		}
+		numbers[i] = n
+		components++
+	}
+	for _, part := range parts[components:] {
+		if part != "x" && part != "X" && part != "*" {
+			return semVersion{}, 0, fmt.Errorf("malformed version %q", s)
+		}
+	}
+
+	version := semVersion{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+	if hasPrerelease {
+		if components != 3 {
+			return semVersion{}, 0, fmt.Errorf("pre-release of partial version %q", s)
+		}
+		full, ok := parseSemVersion(core + "-" + prerelease)
+		if !ok {
+			return semVersion{}, 0, fmt.Errorf("malformed version %q", s)
+		}
+		version = full
+	}
+
+	return version, components, nil
+}
+
+// matches reports whether a version satisfies the constraint
+func (c semConstraint) matches(version semVersion) bool {
+	for _, comparators := range c {
+		if comparatorsMatch(comparators, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// comparatorsMatch reports whether a version satisfies every comparator of a set
+func comparatorsMatch(comparators []semComparator, version semVersion) bool {
+	// Pre-releases are opted into per release by naming one in the constraint
+	if len(version.prerelease) > 0 {
+		optedIn := false
+		for _, comparator := range comparators {
+			if len(comparator.version.prerelease) > 0 && comparator.version.sameRelease(version) {
+				optedIn = true
+				break
+			}
+		}
+		if !optedIn {
+			return false
+		}
+	}
+
+	for _, comparator := range comparators {
+		c := version.compare(comparator.version)
+		var ok bool
+		switch comparator.op {
+		case "=":
+			ok = c == 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// selectTag returns the highest tag matching the constraint and the commit it points
+// to, peeling annotated tags, or false when no tag matches
+func selectTag(refs []*plumbing.Reference, constraint semConstraint) (string, string, bool) {
+	peeled := make(map[string]string)
+	for _, ref := range refs {
+		if name, ok := strings.CutSuffix(ref.Name().String(), "^{}"); ok {
+			peeled[name] = ref.Hash().String()
+		}
+	}
+
+	var bestName, bestCommit string
+	var bestVersion semVersion
+	for _, ref := range refs {
+		if !ref.Name().IsTag() || strings.HasSuffix(ref.Name().String(), "^{}") {
+			continue
+		}
+		name := ref.Name().Short()
+		version, ok := parseSemVersion(name)
+		if !ok || !constraint.matches(version) {
+			continue
+		}
+
+		// Equal versions (v1.4.2 and 1.4.2) are resolved by name for a stable choice
+		if bestName != "" {
+			if c := version.compare(bestVersion); c < 0 || (c == 0 && name > bestName) {
+				continue
+			}
+		}
+
+		bestName, bestVersion = name, version
+		bestCommit = ref.Hash().String()
+		if commit, ok := peeled[ref.Name().String()]; ok {
+			bestCommit = commit
+		}
+	}
+
+	return bestName, bestCommit, bestName != ""
+}
+
+// setReference sets the reference of the URL. A constraint-like reference, or any
+// reference when constraint is set, is validated and kept as the tag constraint;
+// revision expressions such as main~1 are validated and kept as the reference.
+func (u *GitURL) setReference(ref string, constraint bool) error {
+	u.Reference = ref
+	u.TagConstraint = ""
+	if !constraint && isRevisionExpression(ref) {
+		_, _, err := parseRevisionExpression(ref)
+		return err
+	}
+	if !constraint && !isTagConstraintRef(ref) {
+		return nil
+	}
+
+	if _, err := parseSemConstraint(ref); err != nil {
+		return err
+	}
+	u.TagConstraint = ref
+	return nil
+}
+
+// matchTagConstraint returns the highest tag of refs matching a constraint and its commit
+func matchTagConstraint(refs []*plumbing.Reference, expression string) (string, string, error) {
+	constraint, err := parseSemConstraint(expression)
+	if err != nil {
+		return "", "", err
+	}
+
+	tag, commit, ok := selectTag(refs, constraint)
+	if !ok {
+		return "", "", errors.New("ARGUS_GIT_ERROR",
+			fmt.Sprintf("tag matching constraint %s not found in remote repository", expression))
+	}
+	return tag, commit, nil
+}
+
+// resolveTagConstraint returns a copy of gitURL whose reference is the highest tag
+// matching its tag constraint, or gitURL itself when it has no constraint
+func (g *GitProvider) resolveTagConstraint(ctx context.Context, gitURL *GitURL) (*GitURL, error) {
+	if gitURL.TagConstraint == "" {
+		return gitURL, nil
+	}
+
+	var tag string
+
+	// Mirrors are tried in order while the previous repository is unreachable
+	err := g.tryMirrors(gitURL, func(target *GitURL) error {
+		return g.retryOperation(ctx, func() error {
+			refs, err := g.listRemoteRefs(ctx, target)
+			if err != nil {
+				return err
+			}
+			tag, _, err = matchTagConstraint(refs, target.TagConstraint)
+			return err
+		}, "git ls-remote")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.logEvent(ctx, slog.LevelDebug, "tag constraint resolved",
+		append(logURLAttrs(gitURL), slog.String("tag", tag))...)
+
+	resolved := *gitURL
+	resolved.Reference = tag
+	resolved.TagConstraint = ""
+	return &resolved, nil
+}
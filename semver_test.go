@@ -0,0 +1,186 @@
+// semver_test.go
+//
+// Semantic version tag constraint tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// TestSemConstraint tests matching versions against constraints
+func TestSemConstraint(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		matching   []string
+		rejected   []string
+	}{
+		{"^1.4.2", []string{"1.4.2", "1.9.0", "v1.99.99"}, []string{"1.4.1", "2.0.0", "2.0.0-rc.1", "1.5.0-beta"}},
+		{"^0.4.2", []string{"0.4.2", "0.4.9"}, []string{"0.5.0", "0.4.1"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.0"}},
+		{"~1.4", []string{"1.4.0", "1.4.9"}, []string{"1.5.0", "1.3.9"}},
+		{"~1.4.2", []string{"1.4.2", "1.4.3"}, []string{"1.4.1", "1.5.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"1.4.*", []string{"1.4.0", "1.4.7"}, []string{"1.5.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, []string{"1.0.0-rc.1"}},
+		{"1.4.2", []string{"1.4.2", "v1.4.2+build.5"}, []string{"1.4.3"}},
+		{">=1.2 <2", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{">=1.2, <=1.4", []string{"1.4.9"}, []string{"1.5.0"}},
+		{">1.4", []string{"1.5.0"}, []string{"1.4.9"}},
+		{">1.4.2", []string{"1.4.3"}, []string{"1.4.2"}},
+		{"^1 || ^3", []string{"1.2.0", "3.0.0"}, []string{"2.0.0"}},
+		{">=2.0.0-rc.1 <3", []string{"2.0.0-rc.2", "2.0.0", "2.1.0"}, []string{"2.0.0-beta", "2.1.0-rc.1"}},
+	}
+
+	for _, tc := range testCases {
+		constraint, err := parseSemConstraint(tc.constraint)
+		if err != nil {
+			t.Fatalf("parseSemConstraint(%q) failed: %v", tc.constraint, err)
+		}
+		for _, expected := range []struct {
+			versions []string
+			match    bool
+		}{{tc.matching, true}, {tc.rejected, false}} {
+			for _, v := range expected.versions {
+				version, ok := parseSemVersion(v)
+				if !ok {
+					t.Fatalf("parseSemVersion(%q) failed", v)
+				}
+				if constraint.matches(version) != expected.match {
+					t.Errorf("%q matching %s: expected %v", tc.constraint, v, expected.match)
+				}
+			}
+		}
+	}
+
+	t.Run("Invalid constraints", func(t *testing.T) {
+		for _, invalid := range []string{"", "^", "^abc", "~1.x.2", "1.2.3.4", ">=01.2", "^1 ||", "<*", strings.Repeat("^1 ", 100)} {
+			if _, err := parseSemConstraint(invalid); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+				t.Errorf("Expected ARGUS_INVALID_CONFIG for %q, got %v", invalid, err)
+			}
+		}
+	})
+
+	t.Run("Tag names", func(t *testing.T) {
+		for _, invalid := range []string{"release-1", "1.4", "v1.4.2-", "1.04.2", "latest"} {
+			if _, ok := parseSemVersion(invalid); ok {
+				t.Errorf("Expected %q not to be a semantic version", invalid)
+			}
+		}
+	})
+}
+
+// TestSelectTag tests choosing the highest matching tag and peeling annotated tags
+func TestSelectTag(t *testing.T) {
+	hash := func(c string) plumbing.Hash { return plumbing.NewHash(strings.Repeat(c, 40)) }
+	refs := []*plumbing.Reference{
+		plumbing.NewHashReference("refs/heads/main", hash("a")),
+		plumbing.NewHashReference("refs/tags/v1.4.2", hash("1")),
+		plumbing.NewHashReference("refs/tags/v1.10.0", hash("2")),
+		plumbing.NewHashReference("refs/tags/v1.10.0^{}", hash("3")),
+		plumbing.NewHashReference("refs/tags/v2.0.0-rc.1", hash("4")),
+		plumbing.NewHashReference("refs/tags/nightly", hash("5")),
+	}
+
+	constraint, _ := parseSemConstraint("^1.0.0")
+	tag, commit, ok := selectTag(refs, constraint)
+	if !ok || tag != "v1.10.0" || commit != strings.Repeat("3", 40) {
+		t.Errorf("Expected peeled v1.10.0, got %s %s %v", tag, commit, ok)
+	}
+
+	constraint, _ = parseSemConstraint("^3")
+	if _, _, ok := selectTag(refs, constraint); ok {
+		t.Error("Expected no tag to match ^3")
+	}
+}
+
+// TestGitProvider_TagConstraint tests loading from the highest tag matching a constraint
+func TestGitProvider_TagConstraint(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"app.yaml": "version: 1.3.0\n"})
+	repo.tag("v1.3.0")
+	repo.commit("release 1.4.2", map[string]string{"app.yaml": "version: 1.4.2\n"})
+	repo.annotatedTag("v1.4.2")
+	repo.commit("release 1.5.0", map[string]string{"app.yaml": "version: 1.5.0\n"})
+	release15 := repo.commit("release notes", nil)
+	repo.annotatedTag("v1.5.0")
+	repo.commit("release 2.0.0", map[string]string{"app.yaml": "version: 2.0.0\n"})
+	repo.tag("v2.0.0")
+	repo.commit("unreleased", map[string]string{"app.yaml": "version: main\n"})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	baseURL := "file://" + repo.dir + "#app.yaml"
+	testCases := []struct {
+		query   string
+		tag     string
+		version string
+	}{
+		{"?ref=^1.0.0", "v1.5.0", "1.5.0"},
+		{"?tag_constraint=~1.4", "v1.4.2", "1.4.2"},
+		{"?tag_constraint=1.x", "v1.5.0", "1.5.0"},
+		{"?tag_constraint=%3E%3D1.0%20%3C1.5", "v1.4.2", "1.4.2"},
+		{"?tag_constraint=*", "v2.0.0", "2.0.0"},
+		{"?ref=v1.4.2", "v1.4.2", "1.4.2"}, // Exact annotated tag
+		{"?ref=v1.3.0", "v1.3.0", "1.3.0"}, // Exact lightweight tag
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			result, err := provider.LoadDetailed(ctx, baseURL+tc.query)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if result.Reference != tc.tag || result.Config["version"] != tc.version {
+				t.Errorf("Expected %s at %s, got %v at %s", tc.version, tc.tag, result.Config["version"], result.Reference)
+			}
+		})
+	}
+
+	t.Run("Commit of annotated tag", func(t *testing.T) {
+		result, err := provider.LoadDetailed(ctx, baseURL+"?ref=^1")
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if result.CommitHash != release15 {
+			t.Errorf("Expected the tagged commit %s, got %s", release15, result.CommitHash)
+		}
+	})
+
+	t.Run("No matching tag", func(t *testing.T) {
+		_, err := provider.LoadDetailed(ctx, baseURL+"?ref=^3.0.0")
+		if err == nil || !strings.Contains(errors.RootCause(err).Error()+err.Error(), "constraint ^3.0.0") {
+			t.Errorf("Expected a no-match error naming the constraint, got %v", err)
+		}
+	})
+
+	t.Run("Invalid constraint", func(t *testing.T) {
+		if _, err := provider.ParseURL(baseURL + "?tag_constraint=^one"); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG, got %v", err)
+		}
+	})
+
+	t.Run("Parsed constraint", func(t *testing.T) {
+		parsed, err := provider.ParseURL(baseURL + "?ref=~1.4")
+		if err != nil || parsed.TagConstraint != "~1.4" {
+			t.Errorf("Expected the tag constraint in ParseURL, got %+v (err=%v)", parsed, err)
+		}
+	})
+}
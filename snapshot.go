@@ -0,0 +1,240 @@
+// snapshot.go: Consistent multi-file loads from a single commit
+//
+// Separate loads of related files can straddle a push and combine files from
+// different commits. LoadFiles reads every requested file from one clone at one
+// commit, so a commit that updates several files is always observed atomically.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// LoadFiles loads several configuration files of a repository from a single commit.
+//
+// The result maps each requested path to its LoadResult; all results carry the same
+// CommitHash. Results come from the cache only when every file is cached at the
+// current commit of the reference, otherwise all files are read from one fresh
+// clone. repoURL accepts the same base URL and authentication parameters as
+// configuration URLs, and a non-empty ref overrides the reference given in the URL.
+//
+// By default a file that cannot be loaded fails the whole call. With WithMergeTolerant,
+// files that fail to parse are skipped and left out of the result instead, with a
+// warning logged and the skipped_files metric incremented; WithMergeTolerantMissing
+// skips missing files too. The call still fails when no file could be loaded.
+//
+// Example:
+//
+//	files, err := provider.LoadFiles(ctx, "https://github.com/org/configs.git", "main",
+//	    []string{"services/api.yaml", "services/features.yaml"})
+//	api, features := files["services/api.yaml"], files["services/features.yaml"]
+func (g *GitProvider) LoadFiles(ctx context.Context, repoURL, ref string, filePaths []string) (results map[string]*LoadResult, err error) {
+	start := time.Now()
+	var gitURL *GitURL
+	var skipped map[string]error
+	defer func() {
+		g.auditFiles(repoURL, gitURL, filePaths, results, skipped, err, time.Since(start))
+	}()
+
+	// Check if provider is closed
+	if atomic.LoadInt64(&g.closed) == 1 {
+		err := errors.New("ARGUS_PROVIDER_CLOSED", "git provider is closed")
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	if len(filePaths) == 0 {
+		return nil, errors.New("ARGUS_INVALID_CONFIG", "no configuration files to load")
+	}
+	if len(filePaths) > maxLoadFiles {
+		return nil, errors.New("ARGUS_INVALID_CONFIG",
+			fmt.Sprintf("too many configuration files: %d (max %d)", len(filePaths), maxLoadFiles))
+	}
+	for _, filePath := range filePaths {
+		if err := validateConfigPath(filePath, g.allowedExtensions(), g.sensitivePaths()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Increment operation count
+	if !g.incrementOperationCount() {
+		err := g.operationLimitError()
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	defer g.decrementOperationCount()
+
+	gitURL, err = g.parseRequestURL(ctx, repoURL, false)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+	if ref != "" {
+		if err := gitURL.setReference(ref, false); err != nil {
+			return nil, err
+		}
+	}
+
+	results, skipped, err = g.loadFilesFromRepo(ctx, gitURL, filePaths)
+	if err != nil {
+		g.classifyAndRecordError(err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// loadFilesFromRepo loads every file at one commit, from the cache or from a single clone.
+// Files skipped by a tolerant load are returned with their errors.
+func (g *GitProvider) loadFilesFromRepo(ctx context.Context, gitURL *GitURL, filePaths []string) (map[string]*LoadResult, map[string]error, error) {
+	gitURL, err := g.resolveTagConstraint(ctx, gitURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileURL := func(filePath string) *GitURL {
+		u := *gitURL
+		u.FilePath = filePath
+		return &u
+	}
+
+	// A commit-pinned reference is its own cache key, so no remote lookup is needed;
+	// revision expressions are cached under the commit of their base
+	commitHash := strings.ToLower(revisionBase(gitURL.Reference))
+	if !isCommitHash(commitHash) {
+		var err error
+		if commitHash, err = g.getRemoteCommitHash(ctx, gitURL); err != nil {
+			commitHash = ""
+		}
+	}
+
+	// Serve from the cache only when the whole set is cached at the same commit
+	if commitHash != "" {
+		results := make(map[string]*LoadResult, len(filePaths))
+		complete := true
+		for _, filePath := range filePaths {
+			cached, found := g.configCache.getResult(fileURL(filePath), commitHash)
+			if !found {
+				complete = false
+				break
+			}
+			cached.Reference = gitURL.Reference
+			cached.cacheHit = true
+			results[filePath] = cached
+		}
+		if complete {
+			for range filePaths {
+				g.metrics.incrementCacheHits()
+			}
+			return results, nil, nil
+		}
+	}
+	for range filePaths {
+		g.metrics.incrementCacheMisses()
+	}
+
+	tempDir, err := g.createTempDirectory()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "ARGUS_IO_ERROR", "failed to create temporary directory")
+	}
+	defer g.removeTempDirectory(tempDir)
+
+	// Without a file path the clone checks out the whole tree, even with sparse checkout
+	cloneURL := fileURL("")
+	repo, err := g.cloneRepository(ctx, cloneURL, tempDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRepository(repo)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "ARGUS_GIT_ERROR", "failed to get repository worktree")
+	}
+	if reference := gitURL.Reference; reference != "" && reference != "main" && reference != "master" {
+		if err := g.checkoutReference(repo, worktree, reference, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	results := make(map[string]*LoadResult, len(filePaths))
+	var skipped map[string]error
+	var firstSkipErr error
+	for _, filePath := range filePaths {
+		result, err := g.readWorktreeConfig(repo, worktree, filePath, "")
+		if err == nil {
+			err = g.resolveResultIncludes(repo, filePath, result)
+		}
+		if err != nil {
+			if !g.options.skipsFileError(err) {
+				return nil, nil, err
+			}
+			if skipped == nil {
+				skipped = make(map[string]error)
+			}
+			skipped[filePath] = err
+			if firstSkipErr == nil {
+				firstSkipErr = err
+			}
+			g.metrics.incrementSkippedFiles()
+			g.logEvent(ctx, slog.LevelWarn, "configuration file skipped",
+				append(logURLAttrs(fileURL(filePath)), logErrorAttr(err))...)
+			continue
+		}
+		result.Reference = gitURL.Reference
+		results[filePath] = result
+	}
+	if len(results) == 0 {
+		return nil, nil, firstSkipErr // Nothing to serve
+	}
+
+	// Cache every file under the commit all of them were read from, or for ancestors
+	// under the commit of the base the lookup resolved
+	for filePath, result := range results {
+		cacheCommit := result.CommitHash
+		if isRevisionExpression(gitURL.Reference) {
+			cacheCommit = commitHash
+		}
+		if cacheCommit == "" {
+			continue
+		}
+		g.configCache.putResult(fileURL(filePath), cacheCommit, result)
+		g.persistCacheEntry(fileURL(filePath), cacheCommit, result)
+		g.metrics.incrementConfigsCached()
+	}
+
+	return results, skipped, nil
+}
+
+// auditFiles emits one audit record for each requested file of a LoadFiles call;
+// skipped files are recorded as failures with their own errors
+func (g *GitProvider) auditFiles(repoURL string, gitURL *GitURL, filePaths []string, results map[string]*LoadResult, skipped map[string]error, err error, duration time.Duration) {
+	if g.options.auditSink == nil {
+		return
+	}
+
+	for _, filePath := range filePaths {
+		var fileURL *GitURL
+		if gitURL != nil {
+			u := *gitURL
+			u.FilePath = filePath
+			fileURL = &u
+		}
+		fileErr := err
+		if skipErr, ok := skipped[filePath]; ok {
+			fileErr = skipErr
+		}
+		g.audit(AuditOperationLoad, repoURL, fileURL, results[filePath], fileErr, duration)
+	}
+}
@@ -0,0 +1,187 @@
+// snapshot_test.go
+//
+// Consistent multi-file load tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// versionedFiles returns two configuration files that a single commit updates together
+func versionedFiles(version int) map[string]string {
+	return map[string]string{
+		"services/api.json":      fmt.Sprintf(`{"version": %d}`, version),
+		"services/features.yaml": fmt.Sprintf("version: %d\n", version),
+	}
+}
+
+// TestGitProvider_LoadFiles tests that the files of one load always come from the same commit
+func TestGitProvider_LoadFiles(t *testing.T) {
+	repo := newTestRepository(t, versionedFiles(1))
+	filePaths := []string{"services/api.json", "services/features.yaml"}
+
+	provider := GetProvider().(*GitProvider)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// assertConsistent checks that both files were read at one commit and one version
+	assertConsistent := func(t *testing.T, results map[string]*LoadResult) int {
+		t.Helper()
+		api, features := results["services/api.json"], results["services/features.yaml"]
+		if api == nil || features == nil {
+			t.Fatalf("Expected both files, got %v", results)
+		}
+		apiVersion, _ := api.Config["version"].(float64)
+		featuresVersion, _ := features.Config["version"].(int)
+		if int(apiVersion) != featuresVersion || api.CommitHash != features.CommitHash {
+			t.Errorf("Half-updated load: api v%v at %s, features v%v at %s",
+				api.Config["version"], api.CommitHash, features.Config["version"], features.CommitHash)
+		}
+		return featuresVersion
+	}
+
+	t.Run("Cached file does not mix with a newer commit", func(t *testing.T) {
+		// Cache the first file at version 1, then update both files in one commit
+		if _, err := provider.loadConfigFromRepo(ctx, repo.gitURL("services/api.json", "main")); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		repo.commit("version 2", versionedFiles(2))
+
+		results, _, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), filePaths)
+		if err != nil {
+			t.Fatalf("LoadFiles failed: %v", err)
+		}
+		if version := assertConsistent(t, results); version != 2 {
+			t.Errorf("Expected version 2, got %d", version)
+		}
+
+		// The set is now cached at the current commit as a whole
+		hits := provider.Metrics().CacheHits
+		results, _, err = provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), filePaths)
+		if err != nil {
+			t.Fatalf("LoadFiles failed: %v", err)
+		}
+		assertConsistent(t, results)
+		if provider.Metrics().CacheHits != hits+2 {
+			t.Errorf("Expected both files from the cache")
+		}
+	})
+
+	t.Run("Concurrent commits", func(t *testing.T) {
+		var latest int64 = 2
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for version := 3; version <= 12; version++ {
+				repo.commit(fmt.Sprintf("version %d", version), versionedFiles(version))
+				atomic.StoreInt64(&latest, int64(version))
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			before := atomic.LoadInt64(&latest)
+			results, _, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), filePaths)
+			if err != nil {
+				t.Fatalf("LoadFiles failed: %v", err)
+			}
+			if version := assertConsistent(t, results); int64(version) < before {
+				t.Errorf("Expected at least version %d, got %d", before, version)
+			}
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		for _, paths := range [][]string{nil, {"../etc/passwd.json"}, {"config.exe"}} {
+			if _, err := provider.LoadFiles(ctx, "https://github.com/org/configs.git", "main", paths); err == nil {
+				t.Errorf("Expected error for files %v", paths)
+			}
+		}
+	})
+}
+
+// TestWithMergeTolerant tests that tolerant multi-file loads skip a malformed file
+func TestWithMergeTolerant(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		"services/api.json":      `{"service": "api"}`,
+		"services/broken.yaml":   "service: [unterminated\n",
+		"services/features.yaml": "beta: true\n",
+	})
+	filePaths := []string{"services/api.json", "services/broken.yaml", "services/features.yaml"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("Strict by default", func(t *testing.T) {
+		provider := GetProvider().(*GitProvider)
+		if _, _, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), filePaths); !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+			t.Errorf("Expected ARGUS_PARSE_ERROR, got %v", err)
+		}
+	})
+
+	t.Run("Malformed file skipped", func(t *testing.T) {
+		output := &syncBuffer{}
+		provider, err := NewProvider(WithMergeTolerant(true), WithLogger(slog.New(slog.NewTextHandler(output, nil))))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+
+		results, skipped, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), filePaths)
+		if err != nil {
+			t.Fatalf("Expected the remaining files to load, got %v", err)
+		}
+		if len(results) != 2 || results["services/api.json"] == nil || results["services/features.yaml"] == nil {
+			t.Errorf("Expected the two valid files, got %v", results)
+		}
+		if !errors.HasCode(skipped["services/broken.yaml"], "ARGUS_PARSE_ERROR") {
+			t.Errorf("Expected the malformed file to be reported as skipped, got %v", skipped)
+		}
+		if provider.Metrics().SkippedFiles != 1 {
+			t.Errorf("Expected one skipped file, got %d", provider.Metrics().SkippedFiles)
+		}
+		if !strings.Contains(output.String(), "configuration file skipped") || !strings.Contains(output.String(), "services/broken.yaml") {
+			t.Errorf("Expected a warning naming the skipped file, got %q", output.String())
+		}
+	})
+
+	t.Run("Missing files", func(t *testing.T) {
+		withMissing := append([]string{"services/optional.json"}, filePaths...)
+
+		provider, _ := NewProvider(WithMergeTolerant(true))
+		if _, _, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), withMissing); !errors.HasCode(err, "ARGUS_CONFIG_NOT_FOUND") {
+			t.Errorf("Expected a missing file to fail without WithMergeTolerantMissing, got %v", err)
+		}
+
+		provider, _ = NewProvider(WithMergeTolerant(true), WithMergeTolerantMissing(true))
+		results, skipped, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), withMissing)
+		if err != nil || len(results) != 2 || len(skipped) != 2 {
+			t.Errorf("Expected the missing and malformed files to be skipped, got %v %v (err=%v)", results, skipped, err)
+		}
+	})
+
+	t.Run("Nothing loadable", func(t *testing.T) {
+		provider, _ := NewProvider(WithMergeTolerant(true))
+		if _, _, err := provider.loadFilesFromRepo(ctx, repo.gitURL("", "main"), []string{"services/broken.yaml"}); !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+			t.Errorf("Expected ARGUS_PARSE_ERROR when every file is skipped, got %v", err)
+		}
+	})
+}
@@ -9,9 +9,20 @@
 package git
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	stderrors "errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptossh "golang.org/x/crypto/ssh"
 )
 
 // TestGitProvider_SSHAuthentication tests SSH key authentication
@@ -141,3 +152,180 @@ func TestGitProvider_AuthenticationCaching(t *testing.T) {
 
 	t.Logf("Authentication caching works correctly")
 }
+
+// writeTestSSHKey writes a freshly generated ed25519 private key, encrypted with
+// passphrase unless it is empty, and returns its path
+func writeTestSSHKey(t *testing.T, passphrase string) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate SSH key: %v", err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = cryptossh.MarshalPrivateKey(key, "test")
+	} else {
+		block, err = cryptossh.MarshalPrivateKeyWithPassphrase(key, "test", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("Failed to marshal SSH key: %v", err)
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("Failed to write SSH key: %v", err)
+	}
+	return keyPath
+}
+
+// TestGitProvider_SSHUser tests deriving the SSH username from the repository URL
+func TestGitProvider_SSHUser(t *testing.T) {
+	keyPath := writeTestSSHKey(t, "")
+
+	testCases := []struct {
+		configURL string
+		options   []Option
+		user      string
+	}{
+		{"ssh://deploy@git.example.com/org/repo.git#config.json?ssh_key=" + keyPath, nil, "deploy"},
+		{"ssh://git@github.com/org/repo.git#config.json?ssh_key=" + keyPath, []Option{WithSSHUser("gitolite")}, "git"},
+		{"ssh://git.example.com/org/repo.git#config.json?ssh_key=" + keyPath, nil, "git"},
+		{"ssh://git.example.com/org/repo.git#config.json?ssh_key=" + keyPath, []Option{WithSSHUser("gitolite")}, "gitolite"},
+	}
+
+	for _, tc := range testCases {
+		provider, err := NewProvider(tc.options...)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		gitURL, err := provider.parseGitURL(tc.configURL)
+		if err != nil {
+			t.Fatalf("parseGitURL(%q) failed: %v", tc.configURL, err)
+		}
+		auth, err := provider.getAuthentication(gitURL)
+		if err != nil {
+			t.Fatalf("getAuthentication failed: %v", err)
+		}
+		publicKeys, ok := auth.(*ssh.PublicKeys)
+		if !ok {
+			t.Fatalf("Expected SSH public key authentication, got %T", auth)
+		}
+		if publicKeys.User != tc.user {
+			t.Errorf("%s: expected SSH user %q, got %q", tc.configURL, tc.user, publicKeys.User)
+		}
+	}
+
+	t.Run("SCP-like URL", func(t *testing.T) {
+		if user := GetProvider().(*GitProvider).sshUser("gituser@git.example.com:org/repo.git"); user != "gituser" {
+			t.Errorf("Expected SSH user gituser, got %q", user)
+		}
+	})
+
+	t.Run("Invalid username", func(t *testing.T) {
+		for _, user := range []string{"", "a@b", "evil\nuser"} {
+			if _, err := NewProvider(WithSSHUser(user)); err == nil {
+				t.Errorf("Expected error for SSH user %q", user)
+			}
+		}
+	})
+}
+
+// TestGitProvider_SSHPassphrase tests passphrases of encrypted keys supplied outside the URL
+func TestGitProvider_SSHPassphrase(t *testing.T) {
+	const passphrase = "correct-horse-battery"
+	keyPath := writeTestSSHKey(t, passphrase)
+	configURL := "ssh://git@github.com/org/repo.git#config.json?ssh_key=" + keyPath
+
+	authenticate := func(t *testing.T, opts ...Option) error {
+		t.Helper()
+		provider, err := NewProvider(opts...)
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		gitURL, err := provider.parseGitURL(configURL)
+		if err != nil {
+			t.Fatalf("parseGitURL failed: %v", err)
+		}
+		_, err = provider.getAuthentication(gitURL)
+		return err
+	}
+
+	t.Run("Environment variable", func(t *testing.T) {
+		t.Setenv("ARGUS_TEST_SSH_PASSPHRASE", passphrase)
+		if err := authenticate(t, WithSSHPassphraseEnv("ARGUS_TEST_SSH_PASSPHRASE")); err != nil {
+			t.Errorf("Expected the encrypted key to load, got %v", err)
+		}
+	})
+
+	t.Run("Missing passphrase", func(t *testing.T) {
+		if err := authenticate(t); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Errorf("Expected ARGUS_AUTH_ERROR without a passphrase, got %v", err)
+		}
+		if err := authenticate(t, WithSSHPassphraseEnv("ARGUS_TEST_SSH_PASSPHRASE_UNSET")); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Errorf("Expected ARGUS_AUTH_ERROR for an unset variable, got %v", err)
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+		if err := os.WriteFile(passphraseFile, []byte(passphrase+"\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write passphrase file: %v", err)
+		}
+		if err := authenticate(t, WithSSHPassphraseFile(passphraseFile)); err != nil {
+			t.Errorf("Expected the encrypted key to load, got %v", err)
+		}
+
+		if err := os.Chmod(passphraseFile, 0o644); err != nil {
+			t.Fatalf("Failed to change permissions: %v", err)
+		}
+		if err := authenticate(t, WithSSHPassphraseFile(passphraseFile)); !errors.HasCode(err, "ARGUS_SECURITY_ERROR") {
+			t.Errorf("Expected ARGUS_SECURITY_ERROR for a readable passphrase file, got %v", err)
+		}
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		var requested string
+		err := authenticate(t, WithSSHPassphraseFunc(func(path string) (string, error) {
+			requested = path
+			return passphrase, nil
+		}))
+		if err != nil || requested != keyPath {
+			t.Errorf("Expected the callback to unlock %s, got %q and %v", keyPath, requested, err)
+		}
+	})
+
+	t.Run("Wrong passphrase is redacted", func(t *testing.T) {
+		err := authenticate(t, WithSSHPassphraseFunc(func(string) (string, error) { return "wrong-passphrase", nil }))
+		if !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Fatalf("Expected ARGUS_AUTH_ERROR, got %v", err)
+		}
+		if text := err.Error() + errors.RootCause(err).Error(); strings.Contains(text, "wrong-passphrase") {
+			t.Errorf("SECURITY: Passphrase in error: %s", text)
+		}
+	})
+
+	t.Run("Load reports passphrase errors", func(t *testing.T) {
+		provider, err := NewProvider(WithSSHPassphraseFunc(func(string) (string, error) {
+			return "", stderrors.New("vault unavailable")
+		}))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		defer func() { _ = provider.Close() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := provider.Load(ctx, configURL); !errors.HasCode(err, "ARGUS_AUTH_ERROR") {
+			t.Errorf("Expected ARGUS_AUTH_ERROR instead of an unauthenticated clone, got %v", err)
+		}
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		for _, opt := range []Option{WithSSHPassphraseEnv(""), WithSSHPassphraseFile(""), WithSSHPassphraseFunc(nil)} {
+			if _, err := NewProvider(opt); err == nil {
+				t.Error("Expected an invalid passphrase option to fail")
+			}
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// trailers.go: Commit message trailers
+//
+// Teams often record who owns a change and how it may be rolled out in trailers at
+// the end of the commit message, as git interpret-trailers writes them:
+//
+//	Raise connection pool size
+//
+//	Config-Owner: team-payments
+//	Rollback-Safe: true
+//
+// LoadDetailed returns the trailers of the commit the configuration was read from,
+// so an application can act on them without a second look at the repository:
+//
+//	result, err := provider.LoadDetailed(ctx, "https://github.com/org/configs.git#app.yaml")
+//	if err != nil {
+//	    return err
+//	}
+//	if result.Trailers.Get("rollback-safe") != "true" {
+//	    return errRequiresApproval
+//	}
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// maxCommitTrailers bounds the trailer block of a commit message; longer final
+// paragraphs are treated as ordinary message text
+const maxCommitTrailers = 64
+
+// Trailers holds the trailers of a commit message by key, with the values of repeated
+// keys in message order. Keys keep the spelling of their first occurrence; keys that
+// differ only in case are the same trailer.
+type Trailers map[string][]string
+
+// Get returns the last value of the trailer key, matched case-insensitively, or ""
+// when the commit has no such trailer
+func (t Trailers) Get(key string) string {
+	for k, values := range t {
+		if strings.EqualFold(k, key) && len(values) > 0 {
+			return values[len(values)-1]
+		}
+	}
+	return ""
+}
+
+// clone returns a copy of the trailers that shares no slices with the original
+func (t Trailers) clone() Trailers {
+	if t == nil {
+		return nil
+	}
+
+	copied := make(Trailers, len(t))
+	for k, values := range t {
+		copied[k] = slices.Clone(values)
+	}
+	return copied
+}
+
+// commitTrailers returns the trailers of the message of a commit of repo, or nil when
+// the commit cannot be read or its message has none
+func commitTrailers(repo *git.Repository, commitHash string) Trailers {
+	if commitHash == "" {
+		return nil
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil
+	}
+	return parseCommitTrailers(commit.Message)
+}
+
+// parseCommitTrailers parses the trailer block of a commit message: its last paragraph,
+// provided the message has a subject paragraph before it and every line of the paragraph
+// is a "Key: value" trailer or, indented, the continuation of the previous one.
+// Keys consist of letters, digits and hyphens.
+func parseCommitTrailers(message string) Trailers {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(message, "\r\n", "\n"), " \t\n"), "\n")
+
+	// The trailer block follows the last blank line; a message of one paragraph has none
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			start = i + 1
+			break
+		}
+	}
+	if start <= 0 || len(lines)-start > maxCommitTrailers {
+		return nil
+	}
+
+	trailers := make(Trailers)
+	var lastKey string
+	for _, line := range lines[start:] {
+		// Indented lines continue the value of the previous trailer
+		if line[0] == ' ' || line[0] == '\t' {
+			if lastKey == "" {
+				return nil
+			}
+			values := trailers[lastKey]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		key = strings.TrimRight(key, " \t")
+		if !found || !isTrailerKey(key) {
+			return nil
+		}
+
+		// Keys differing only in case share the spelling first seen
+		for existing := range trailers {
+			if strings.EqualFold(existing, key) {
+				key = existing
+				break
+			}
+		}
+		trailers[key] = append(trailers[key], strings.TrimSpace(value))
+		lastKey = key
+	}
+
+	return trailers
+}
+
+// isTrailerKey reports whether key is a valid trailer key
+func isTrailerKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// trailers_test.go
+//
+// Commit message trailer tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestParseCommitTrailers tests trailer parsing of commit messages
+func TestParseCommitTrailers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected Trailers
+	}{
+		{
+			name:     "Trailers",
+			message:  "Raise pool size\n\nConfig-Owner: team-payments\nRollback-Safe: true\n",
+			expected: Trailers{"Config-Owner": {"team-payments"}, "Rollback-Safe": {"true"}},
+		},
+		{
+			name:     "Body before trailers",
+			message:  "Raise pool size\n\nThe pool ran dry: twice.\n\nSigned-off-by: A <a@example.com>",
+			expected: Trailers{"Signed-off-by": {"A <a@example.com>"}},
+		},
+		{
+			name:     "Repeated keys in any case",
+			message:  "Subject\n\nReviewed-by: A\nreviewed-by: B\n",
+			expected: Trailers{"Reviewed-by": {"A", "B"}},
+		},
+		{
+			name:     "Continuation lines",
+			message:  "Subject\r\n\r\nNote: first line\r\n  second line\r\n",
+			expected: Trailers{"Note": {"first line second line"}},
+		},
+		{
+			name:     "Whitespace before separator",
+			message:  "Subject\n\nTicket : OPS-12\n",
+			expected: Trailers{"Ticket": {"OPS-12"}},
+		},
+		{
+			name:    "Subject only",
+			message: "Config-Owner: team-payments\n",
+		},
+		{
+			name:    "Paragraph with ordinary text",
+			message: "Subject\n\nConfig-Owner: team-payments\nnot a trailer\n",
+		},
+		{
+			name:    "Key with spaces",
+			message: "Subject\n\nConfig Owner: team-payments\n",
+		},
+		{
+			name:    "Leading continuation",
+			message: "Subject\n\n  Config-Owner: team-payments\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			trailers := parseCommitTrailers(tc.message)
+			if len(tc.expected) == 0 {
+				if len(trailers) != 0 {
+					t.Errorf("Expected no trailers, got %v", trailers)
+				}
+				return
+			}
+			if !reflect.DeepEqual(trailers, tc.expected) {
+				t.Errorf("Expected trailers %v, got %v", tc.expected, trailers)
+			}
+		})
+	}
+
+	if got := parseCommitTrailers("Subject\n\nReviewed-by: A\nreviewed-by: B\n").Get("REVIEWED-BY"); got != "B" {
+		t.Errorf("Expected the last value of the trailer, got %q", got)
+	}
+	if got := Trailers(nil).Get("Reviewed-by"); got != "" {
+		t.Errorf("Expected no value without trailers, got %q", got)
+	}
+}
+
+// TestLoadDetailedTrailers tests that load results carry the trailers of their commit
+func TestLoadDetailedTrailers(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"pool": 10}`})
+	repo.commit("Raise pool size\n\nConfig-Owner: team-payments\nRollback-Safe: true\n",
+		map[string]string{"config.json": `{"pool": 20}`})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	configURL := "file://" + repo.dir + "#config.json?ref=main"
+
+	expected := Trailers{"Config-Owner": {"team-payments"}, "Rollback-Safe": {"true"}}
+	for _, attempt := range []string{"clone", "cache hit"} {
+		result, err := provider.LoadDetailed(ctx, configURL)
+		if err != nil {
+			t.Fatalf("%s: LoadDetailed failed: %v", attempt, err)
+		}
+		if !reflect.DeepEqual(result.Trailers, expected) {
+			t.Errorf("%s: expected trailers %v, got %v", attempt, expected, result.Trailers)
+		}
+
+		// Results are copies that callers may modify
+		result.Trailers["Config-Owner"][0] = "modified"
+	}
+
+	// A commit leaving the file unchanged reuses its parse but not the trailers
+	repo.commit("Document pool size\n\nConfig-Owner: team-platform\n", map[string]string{"README.md": "pool"})
+	result, err := provider.LoadDetailed(ctx, configURL)
+	if err != nil {
+		t.Fatalf("LoadDetailed failed: %v", err)
+	}
+	if hits := provider.Metrics().BlobCacheHits; hits != 1 {
+		t.Errorf("Expected 1 blob cache hit, got %d", hits)
+	}
+	if owner := result.Trailers.Get("config-owner"); owner != "team-platform" {
+		t.Errorf("Expected the trailer of the new commit, got %q", owner)
+	}
+	if result.Trailers.Get("Rollback-Safe") != "" {
+		t.Errorf("Expected no trailer of the previous commit, got %v", result.Trailers)
+	}
+
+	// Commits without trailers have none
+	repo.commit("Lower pool size", map[string]string{"config.json": `{"pool": 15}`})
+	result, err = provider.LoadDetailed(ctx, configURL)
+	if err != nil {
+		t.Fatalf("LoadDetailed failed: %v", err)
+	}
+	if len(result.Trailers) != 0 {
+		t.Errorf("Expected no trailers, got %v", result.Trailers)
+	}
+}
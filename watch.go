@@ -0,0 +1,72 @@
+// watch.go: Watch handles
+//
+// Watch is stopped by cancelling the context it was started with, which is awkward
+// when watches come and go under central management. StartWatch returns a handle
+// owning a context derived from the caller's, so a single watch can be stopped
+// without keeping a context per watch:
+//
+//	handle, err := provider.StartWatch(ctx, "https://github.com/org/configs.git#app.yaml")
+//	if err != nil {
+//	    return err
+//	}
+//	go func() {
+//	    for config := range handle.Config() {
+//	        apply(config)
+//	    }
+//	}()
+//	...
+//	handle.Stop() // The channel is closed and the watch slot freed
+//
+// Cancelling the caller's context still stops the watch, as it does for Watch.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import "context"
+
+// WatchHandle controls a watch started with StartWatch
+type WatchHandle struct {
+	configs <-chan map[string]interface{}
+	errs    <-chan error
+	cancel  context.CancelFunc
+}
+
+// StartWatch starts watching for configuration changes like Watch, returning a handle
+// whose Stop method ends the watch independently of ctx
+func (g *GitProvider) StartWatch(ctx context.Context, configURL string) (*WatchHandle, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	errs := make(chan error, 1)
+	configs, err := g.watch(watchCtx, configURL, errs)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &WatchHandle{configs: configs, errs: errs, cancel: cancel}, nil
+}
+
+// Config returns the channel configurations are delivered on. It is closed when the
+// watch ends, whether through Stop, the context or the provider being closed.
+func (h *WatchHandle) Config() <-chan map[string]interface{} {
+	return h.configs
+}
+
+// Errors returns the channel invalid configurations are reported on under the
+// WatchDeliverError policy (see WithWatchErrorPolicy). It is closed when the watch ends.
+func (h *WatchHandle) Errors() <-chan error {
+	return h.errs
+}
+
+// Stop ends the watch and waits until it has released its watch slot and closed its
+// channel; configurations not yet received are discarded. Stop is safe to call more
+// than once and from several goroutines.
+func (h *WatchHandle) Stop() {
+	h.cancel()
+
+	// The watch goroutine frees its slot before closing the channel
+	for range h.configs {
+	}
+}
@@ -0,0 +1,265 @@
+// watch_test.go
+//
+// Watch handle tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestGitProvider_StartWatch tests stopping a watch through its handle
+func TestGitProvider_StartWatch(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"service": "api"}`})
+	configURL := "file://" + repo.dir + "#config.json?poll=1s"
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("Stop closes the channel", func(t *testing.T) {
+		handle, err := provider.StartWatch(ctx, configURL)
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		select {
+		case config := <-handle.Config():
+			if config["service"] != "api" {
+				t.Errorf("Unexpected config: %v", config)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the initial configuration")
+		}
+
+		handle.Stop()
+		if _, ok := <-handle.Config(); ok {
+			t.Error("Expected the channel to be closed after Stop")
+		}
+		if count := atomic.LoadInt64(&provider.watchCount); count != 0 {
+			t.Errorf("Expected no active watches after Stop, got %d", count)
+		}
+
+		// Stopping again is a no-op
+		handle.Stop()
+	})
+
+	t.Run("Stop frees a watch slot", func(t *testing.T) {
+		atomic.StoreInt64(&provider.watchCount, maxActiveWatches-1)
+		defer atomic.StoreInt64(&provider.watchCount, 0)
+
+		handle, err := provider.StartWatch(ctx, configURL)
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		if _, err := provider.StartWatch(ctx, configURL); !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+			t.Fatalf("Expected ARGUS_RESOURCE_LIMIT at the watch limit, got %v", err)
+		}
+
+		handle.Stop()
+		handle, err = provider.StartWatch(ctx, configURL)
+		if err != nil {
+			t.Fatalf("Expected the stopped watch to free its slot, got %v", err)
+		}
+		handle.Stop()
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		watchCtx, stop := context.WithCancel(ctx)
+		handle, err := provider.StartWatch(watchCtx, configURL)
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		stop()
+
+		for range handle.Config() {
+		}
+		handle.Stop()
+	})
+}
+
+// TestGitProvider_WatchSkipInitial tests that watch_initial=false delivers only changes
+func TestGitProvider_WatchSkipInitial(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+
+	provider, err := NewProvider(WithAllowLocalRepos(true))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("URL parameter", func(t *testing.T) {
+		baseURL := "file://" + repo.dir + "#config.json?ref=main"
+		for param, skip := range map[string]bool{"": false, "&watch_initial=true": false, "&watch_initial=false": true, "&watch_initial=0": true} {
+			gitURL, err := provider.parseGitURL(baseURL + param)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", param, err)
+			}
+			if gitURL.SkipInitial != skip {
+				t.Errorf("Expected SkipInitial %v for %q, got %v", skip, param, gitURL.SkipInitial)
+			}
+		}
+		if _, err := provider.parseGitURL(baseURL + "&watch_initial=later"); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG for an invalid watch_initial value, got %v", err)
+		}
+	})
+
+	t.Run("Only changes are delivered", func(t *testing.T) {
+		gitURL := repo.gitURL("config.json", "main")
+		gitURL.PollInterval = 10 * time.Millisecond
+		gitURL.SkipInitial = true
+
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		configChan := make(chan map[string]interface{}, 1)
+		provider.incrementWatchCount()
+		go provider.startWatching(watchCtx, gitURL, configChan, nil)
+		defer func() {
+			stopWatch()
+			for range configChan {
+			}
+		}()
+
+		// Neither the initial load nor reloads of the same content are delivered
+		time.Sleep(200 * time.Millisecond)
+		select {
+		case config := <-configChan:
+			t.Fatalf("Unexpected delivery before any change: %v", config)
+		default:
+		}
+
+		repo.commit("release 2", map[string]string{"config.json": `{"version": 2}`})
+		select {
+		case config := <-configChan:
+			if config["version"] != float64(2) {
+				t.Errorf("Expected the changed configuration, got %v", config)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the changed configuration")
+		}
+	})
+}
+
+// TestGitProvider_WatchStaleFallback tests that an outage does not redeliver the stale configuration
+func TestGitProvider_WatchStaleFallback(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+	server := repo.serveHTTP()
+
+	provider, err := NewProvider(WithStaleFallback(time.Hour))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	defer func() { _ = provider.Close() }()
+	provider.retryConfig = newNoRetryProvider().retryConfig
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	gitURL := &GitURL{
+		RepoURL:      server.URL + "/.git",
+		FilePath:     "config.json",
+		Reference:    "main",
+		AuthData:     make(map[string]string),
+		PollInterval: 10 * time.Millisecond,
+	}
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	configChan := make(chan map[string]interface{}, 1)
+	provider.incrementWatchCount()
+	go provider.startWatching(watchCtx, gitURL, configChan, nil)
+	defer func() {
+		stopWatch()
+		for range configChan {
+		}
+	}()
+
+	select {
+	case config := <-configChan:
+		if config["version"] != float64(1) {
+			t.Fatalf("Unexpected initial configuration: %v", config)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the initial configuration")
+	}
+
+	// Polls during the outage are served the last-known-good configuration
+	server.Close()
+	time.Sleep(200 * time.Millisecond)
+	if serves := provider.Metrics().StaleServes; serves == 0 {
+		t.Fatal("Expected the outage to be served stale configurations")
+	}
+	select {
+	case config := <-configChan:
+		t.Errorf("Unexpected redelivery during the outage: %v", config)
+	default:
+	}
+}
+
+// TestGitProvider_WatchFallbackOfPinnedRef tests that a watch of a commit served by a
+// fallback branch keeps following the branch
+func TestGitProvider_WatchFallbackOfPinnedRef(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+
+	provider := newNoRetryProvider()
+	defer func() { _ = provider.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	gitURL := repo.gitURL("config.json", strings.Repeat("ab", 20))
+	gitURL.FallbackRefs = []string{"main"}
+	gitURL.PollInterval = 10 * time.Millisecond
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	configChan := make(chan map[string]interface{}, 1)
+	provider.incrementWatchCount()
+	go provider.startWatching(watchCtx, gitURL, configChan, nil)
+	defer func() {
+		stopWatch()
+		for range configChan {
+		}
+	}()
+
+	select {
+	case config := <-configChan:
+		if config["version"] != float64(1) {
+			t.Fatalf("Unexpected initial configuration: %v", config)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the initial configuration")
+	}
+
+	// Reloads of the same fallback commit are not delivered again
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case config := <-configChan:
+		t.Fatalf("Unexpected redelivery of the fallback commit: %v", config)
+	default:
+	}
+
+	repo.commit("release 2", map[string]string{"config.json": `{"version": 2}`})
+	select {
+	case config := <-configChan:
+		if config["version"] != float64(2) {
+			t.Errorf("Expected the configuration of the advanced branch, got %v", config)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the advanced branch; the watch stopped polling")
+	}
+}
@@ -0,0 +1,90 @@
+// watchpolicy.go: Watch behavior on invalid configurations
+//
+// When a commit breaks the syntax of a watched file, drops a required key, exceeds a
+// size or nesting limit, or turns the file into a directory or an escaping symbolic
+// link, the watch has nothing to deliver. By default it keeps the consumer on the last good
+// configuration, logging a warning and counting the load in the
+// invalid_watch_loads metric. WithWatchErrorPolicy makes the failure visible:
+//
+//	provider, err := git.NewProvider(git.WithWatchErrorPolicy(git.WatchDeliverError))
+//	handle, err := provider.StartWatch(ctx, configURL)
+//	for {
+//	    select {
+//	    case config := <-handle.Config():
+//	        apply(config)
+//	    case err := <-handle.Errors():
+//	        alert(err) // The last good configuration stays applied
+//	    }
+//	}
+//
+// Repeated failures with the same error are reported once, until a configuration
+// loads again. Other failures, such as an unreachable repository, never reach the
+// policy: the watch keeps its last configuration and retries on the next poll.
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGILira library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/agilira/go-errors"
+)
+
+// Watch error policies of WithWatchErrorPolicy
+const (
+	WatchKeepLastGood = "keep-last-good" // Deliver nothing; the last good configuration stays current
+	WatchDeliverError = "deliver-error"  // Send the error on the Errors channel of StartWatch handles
+	WatchDeliverEmpty = "deliver-empty"  // Deliver an empty configuration in place of the invalid one
+)
+
+// invalidConfigCodes are the error codes of loads rejected for the committed content
+var invalidConfigCodes = []errors.ErrorCode{
+	"ARGUS_PARSE_ERROR",
+	"ARGUS_SCHEMA_ERROR",
+	"ARGUS_RESOURCE_LIMIT", // Oversized files, alias bombs and excessive nesting
+	"ARGUS_SECURITY_ERROR", // Symbolic links escaping the repository, sensitive paths
+	"ARGUS_INVALID_CONFIG", // Paths naming a directory, ambiguous references
+}
+
+// isInvalidConfigError reports whether a watch load failed because of the content of
+// the committed configuration rather than of the repository. Failures to reach or read
+// the repository, such as blocked redirects, are wrapped in ARGUS_GIT_ERROR and excluded.
+func isInvalidConfigError(err error) bool {
+	if errors.HasCode(err, "ARGUS_GIT_ERROR") {
+		return false
+	}
+	for _, code := range invalidConfigCodes {
+		if errors.HasCode(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportInvalidConfig applies the watch error policy to an invalid configuration,
+// reporting whether an empty configuration is to be delivered. Errors are sent
+// without blocking; one is dropped while the previous one is still unread.
+func (g *GitProvider) reportInvalidConfig(ctx context.Context, gitURL *GitURL, err error, errChan chan<- error) bool {
+	g.metrics.incrementInvalidWatchLoads()
+	policy := g.options.effectiveWatchErrorPolicy()
+	g.logEvent(ctx, slog.LevelWarn, "watched configuration invalid",
+		append(logURLAttrs(gitURL), slog.String("policy", policy), logErrorAttr(err))...)
+
+	switch policy {
+	case WatchDeliverError:
+		if errChan != nil {
+			select {
+			case errChan <- err:
+			default:
+				g.logEvent(ctx, slog.LevelWarn, "watch error dropped, previous error unread", logURLAttrs(gitURL)...)
+			}
+		}
+	case WatchDeliverEmpty:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+// watchpolicy_test.go
+//
+// Watch error policy tests for Git provider
+//
+// Copyright (c) 2025 AGILira - A. Giordano
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-errors"
+)
+
+// TestWithWatchErrorPolicy tests each policy with an invalid commit following a valid one
+func TestWithWatchErrorPolicy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// startPolicyWatch watches a repository with policy, receives its initial configuration
+	// and commits invalid content
+	startPolicyWatch := func(t *testing.T, policy, invalid string) (*testRepository, *GitProvider, chan map[string]interface{}, chan error) {
+		t.Helper()
+
+		repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+		provider, err := NewProvider(WithWatchErrorPolicy(policy))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		gitURL := repo.gitURL("config.json", "main")
+		gitURL.PollInterval = 10 * time.Millisecond
+
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		configChan := make(chan map[string]interface{}, 1)
+		errChan := make(chan error, 1)
+		provider.incrementWatchCount()
+		go provider.startWatching(watchCtx, gitURL, configChan, errChan)
+		t.Cleanup(func() {
+			stopWatch()
+			for range configChan {
+			}
+			_ = provider.Close()
+		})
+
+		select {
+		case config := <-configChan:
+			if config["version"] != float64(1) {
+				t.Fatalf("Unexpected initial configuration: %v", config)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the initial configuration")
+		}
+
+		repo.commit("break config", map[string]string{"config.json": invalid})
+		return repo, provider, configChan, errChan
+	}
+
+	// receive waits for the next configuration
+	receive := func(t *testing.T, configChan <-chan map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		select {
+		case config := <-configChan:
+			return config
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for a configuration")
+			return nil
+		}
+	}
+
+	// waitInvalid waits until the invalid commit was loaded
+	waitInvalid := func(t *testing.T, provider *GitProvider) {
+		t.Helper()
+		for provider.Metrics().InvalidWatchLoads == 0 {
+			if ctx.Err() != nil {
+				t.Fatal("Timed out waiting for the invalid configuration to be loaded")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	t.Run("Keep last good", func(t *testing.T) {
+		repo, provider, configChan, errChan := startPolicyWatch(t, WatchKeepLastGood, `{"version": `)
+		waitInvalid(t, provider)
+
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case config := <-configChan:
+			t.Errorf("Unexpected delivery for an invalid configuration: %v", config)
+		case err := <-errChan:
+			t.Errorf("Unexpected error delivery: %v", err)
+		default:
+		}
+
+		repo.commit("fix config", map[string]string{"config.json": `{"version": 2}`})
+		if config := receive(t, configChan); config["version"] != float64(2) {
+			t.Errorf("Expected the fixed configuration, got %v", config)
+		}
+	})
+
+	t.Run("Deliver error", func(t *testing.T) {
+		repo, provider, configChan, errChan := startPolicyWatch(t, WatchDeliverError, `{"version": `)
+
+		select {
+		case err := <-errChan:
+			if !errors.HasCode(err, "ARGUS_PARSE_ERROR") {
+				t.Errorf("Expected ARGUS_PARSE_ERROR, got %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the error")
+		}
+
+		// The same failure is not reported again on later polls
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case err := <-errChan:
+			t.Errorf("Unexpected repeated error: %v", err)
+		case config := <-configChan:
+			t.Errorf("Unexpected delivery for an invalid configuration: %v", config)
+		default:
+		}
+		if loads := provider.Metrics().InvalidWatchLoads; loads != 1 {
+			t.Errorf("Expected one invalid watch load, got %d", loads)
+		}
+
+		repo.commit("fix config", map[string]string{"config.json": `{"version": 2}`})
+		if config := receive(t, configChan); config["version"] != float64(2) {
+			t.Errorf("Expected the fixed configuration, got %v", config)
+		}
+	})
+
+	t.Run("Deliver empty", func(t *testing.T) {
+		repo, _, configChan, _ := startPolicyWatch(t, WatchDeliverEmpty, `{"version": `)
+
+		if config := receive(t, configChan); config == nil || len(config) != 0 {
+			t.Errorf("Expected an empty configuration, got %v", config)
+		}
+
+		// Restoring the previous content is a change from the empty configuration
+		repo.commit("restore config", map[string]string{"config.json": `{"version": 1}`})
+		if config := receive(t, configChan); config["version"] != float64(1) {
+			t.Errorf("Expected the restored configuration, got %v", config)
+		}
+	})
+
+	t.Run("Limit exceeded", func(t *testing.T) {
+		nested := strings.Repeat(`{"a": `, maxConfigDepth+1) + "1" + strings.Repeat("}", maxConfigDepth+1)
+		repo, _, configChan, errChan := startPolicyWatch(t, WatchDeliverError, nested)
+
+		select {
+		case err := <-errChan:
+			if !errors.HasCode(err, "ARGUS_RESOURCE_LIMIT") {
+				t.Errorf("Expected ARGUS_RESOURCE_LIMIT, got %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the error")
+		}
+
+		repo.commit("fix config", map[string]string{"config.json": `{"version": 2}`})
+		if config := receive(t, configChan); config["version"] != float64(2) {
+			t.Errorf("Expected the fixed configuration, got %v", config)
+		}
+	})
+
+	t.Run("Errors channel of watch handles", func(t *testing.T) {
+		repo := newTestRepository(t, map[string]string{"config.json": `{"version": 1}`})
+		provider, err := NewProvider(WithAllowLocalRepos(true), WithWatchErrorPolicy(WatchDeliverError))
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		defer func() { _ = provider.Close() }()
+
+		handle, err := provider.StartWatch(ctx, "file://"+repo.dir+"#config.json?ref=main")
+		if err != nil {
+			t.Fatalf("StartWatch failed: %v", err)
+		}
+		receive(t, handle.Config())
+		handle.Stop()
+		if _, ok := <-handle.Errors(); ok {
+			t.Error("Expected the errors channel to be closed after Stop")
+		}
+	})
+
+	t.Run("Repository failures are retried", func(t *testing.T) {
+		blocked := errors.Wrap(errors.New("ARGUS_SECURITY_ERROR", "Git HTTP redirect blocked"),
+			"ARGUS_GIT_ERROR", "failed to clone repository")
+		if isInvalidConfigError(blocked) {
+			t.Errorf("Expected a repository failure not to be an invalid configuration: %v", blocked)
+		}
+		if !isInvalidConfigError(directoryPathError("configs")) {
+			t.Error("Expected a directory path to be an invalid configuration")
+		}
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		if _, err := NewProvider(WithWatchErrorPolicy("deliver-nothing")); !errors.HasCode(err, "ARGUS_INVALID_CONFIG") {
+			t.Errorf("Expected ARGUS_INVALID_CONFIG for an unknown policy, got %v", err)
+		}
+	})
+}